@@ -1,4 +1,6 @@
-import type { Child, SleepLog, FeedingLog, DiaperLog, GrowthLog, DaySummary, DayStats } from './types/models';
+import type { User, Child, SleepLog, FeedingLog, DiaperLog, GrowthLog, DaySummary, DayStats } from './types/models';
+
+import { navigate } from './router';
 
 const BASE = '/api/v1';
 
@@ -9,12 +11,21 @@ async function req<T>(method: string, path: string, body?: unknown): Promise<T>
     body: body ? JSON.stringify(body) : undefined,
   });
   if (res.status === 204) return undefined as T;
+  if (res.status === 401 && !path.startsWith('/auth/')) {
+    navigate('/login');
+    throw new Error('Please log in');
+  }
   const data = await res.json();
   if (!res.ok) throw new Error(data.error ?? `HTTP ${res.status}`);
   return data as T;
 }
 
 export const api = {
+  // Auth
+  login: (username: string, password: string) => req<User>('POST', '/auth/login', { username, password }),
+  logout: () => req<void>('POST', '/auth/logout'),
+  me: () => req<User>('GET', '/auth/me'),
+
   // Child
   getChild: () => req<Child | null>('GET', '/child'),
   createChild: (body: Partial<Child>) => req<Child>('POST', '/child', body),
@@ -0,0 +1,76 @@
+import { h } from '../utils/dom';
+import { api } from '../api';
+import { state } from '../state';
+import { navigate } from '../router';
+import { showToast } from './toast';
+
+export function renderLogin(): HTMLElement {
+  const screen = h('div', { class: 'screen onboarding' });
+
+  const header = h('div', { class: 'onboarding-header' },
+    h('div', { class: 'onboarding-emoji' }, '🍼'),
+    h('h1', { class: 'onboarding-title' }, 'Welcome back'),
+    h('p', { class: 'onboarding-subtitle' }, 'Sign in to your family\'s tracker.'),
+  );
+
+  const usernameInput = h('input', {
+    class: 'form-input',
+    type: 'text',
+    autocomplete: 'username',
+    autocapitalize: 'none',
+    id: 'login-username',
+  }) as HTMLInputElement;
+
+  const passwordInput = h('input', {
+    class: 'form-input',
+    type: 'password',
+    autocomplete: 'current-password',
+    id: 'login-password',
+  }) as HTMLInputElement;
+
+  const submitBtn = h('button', {
+    class: 'btn btn-primary btn-full',
+    type: 'button',
+    onClick: async () => {
+      const username = usernameInput.value.trim();
+      const password = passwordInput.value;
+      if (!username || !password) { showToast('Please enter your username and password', 'error'); return; }
+
+      submitBtn.disabled = true;
+      submitBtn.innerHTML = '<div class="spinner"></div>';
+
+      try {
+        await api.login(username, password);
+        const child = await api.getChild().catch(() => null);
+        if (child && 'id' in child) {
+          state.child.set(child);
+          navigate('/dashboard');
+        } else {
+          navigate('/onboarding');
+        }
+      } catch (e: any) {
+        showToast(e.message ?? 'Something went wrong', 'error');
+        submitBtn.disabled = false;
+        submitBtn.textContent = 'Sign In';
+      }
+    },
+  }, 'Sign In') as HTMLButtonElement;
+
+  const form = h('div', { class: 'onboarding-form' },
+    h('div', { class: 'form-group' },
+      h('label', { class: 'form-label', for: 'login-username' }, 'Username'),
+      usernameInput,
+    ),
+    h('div', { class: 'form-group' },
+      h('label', { class: 'form-label', for: 'login-password' }, 'Password'),
+      passwordInput,
+    ),
+    h('div', { class: 'onboarding-footer' },
+      submitBtn,
+    ),
+  );
+
+  screen.appendChild(header);
+  screen.appendChild(form);
+  return screen;
+}
@@ -106,16 +106,9 @@ export function renderSleepModal(onSave: () => void): HTMLElement {
           const endISO = endInput.value ? localInputToISO(endInput.value) : '';
 
           if (endISO) {
-            // Log a completed sleep: create then immediately stop it
-            const res = await api.createSleep({ start_time: startISO });
-            await api.updateSleep(res.id, { end_time: endISO });
-            state.activeFeeding.set(null);
-            if (res.stopped_feeding) {
-              const side = res.stopped_feeding.feed_type === 'breast_left' ? 'Left' : 'Right';
-              showToast(`${side} feed stopped — sleep logged`);
-            } else {
-              showToast('Sleep logged');
-            }
+            // Log a completed sleep in one request so it never overlaps later entries
+            await api.createSleep({ start_time: startISO, end_time: endISO });
+            showToast('Sleep logged');
           } else {
             // Start a live timer
             const res = await api.createSleep({ start_time: startISO });
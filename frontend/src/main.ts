@@ -10,6 +10,7 @@ import '../css/analytics.css';
 import { addRoute, initRouter, navigate } from './router';
 import { api } from './api';
 import { state } from './state';
+import { renderLogin } from './components/login';
 import { renderOnboarding } from './components/onboarding';
 import { renderDashboard } from './components/dashboard';
 import { renderHistory } from './components/history';
@@ -18,6 +19,7 @@ import { renderGuideScreen } from './components/guide';
 import { renderAnalyticsScreen } from './components/analytics';
 
 // Register routes
+addRoute('/login', renderLogin);
 addRoute('/onboarding', renderOnboarding);
 addRoute('/dashboard', renderDashboard);
 addRoute('/history', renderHistory);
@@ -29,6 +31,14 @@ async function bootstrap() {
   const app = document.getElementById('app');
   if (!app) return;
 
+  try {
+    await api.me();
+  } catch {
+    navigate('/login');
+    initRouter(app);
+    return;
+  }
+
   try {
     const child = await api.getChild();
     if (child && 'id' in child) {
@@ -9,6 +9,13 @@ export interface Child {
   updated_at: string;
 }
 
+export interface User {
+  id: string;
+  username: string;
+  role: 'parent' | 'nanny' | 'grandparent';
+  created_at: string;
+}
+
 export interface SleepLog {
   id: string;
   child_id: string;
@@ -17,6 +24,14 @@ export interface SleepLog {
   duration_minutes: number | null;
   notes?: string;
   created_at: string;
+  created_by?: string;
+  pauses?: TimerPause[];
+  paused_at?: string; // set while the timer is paused
+}
+
+export interface TimerPause {
+  paused_at: string;
+  resumed_at: string | null;
 }
 
 export interface FeedingLog {
@@ -27,8 +42,244 @@ export interface FeedingLog {
   end_time: string | null;
   duration_minutes: number | null;
   quantity_ml: number | null;
+  milk_type?: 'breast_milk' | 'formula';
+  milk_used?: MilkUse[]; // breast milk bottles only
+  notes?: string;
+  created_at: string;
+  created_by?: string;
+  pauses?: TimerPause[];
+  paused_at?: string;
+  segments?: FeedingSegment[]; // breast feeds only
+  left_minutes?: number;
+  right_minutes?: number;
+}
+
+export interface MilkUse {
+  bag_id: string;
+  ml: number;
+}
+
+export interface FeedingSegment {
+  side: 'left' | 'right';
+  start_time: string;
+  end_time: string | null;
+  duration_minutes: number | null;
+}
+
+export interface NextSide {
+  side: 'left' | 'right';
+  last_feeding_id: string;
+  last_side: 'left' | 'right';
+  last_end_time: string;
+}
+
+export interface PumpingLog {
+  id: string;
+  child_id: string;
+  start_time: string;
+  end_time: string | null;
+  duration_minutes: number | null;
+  left_ml: number | null;
+  right_ml: number | null;
   notes?: string;
   created_at: string;
+  created_by?: string;
+}
+
+export interface MilkBag {
+  id: string;
+  child_id: string;
+  pumping_id: string | null;
+  volume_ml: number;
+  remaining_ml: number;
+  location: 'room' | 'fridge' | 'freezer';
+  stored_at: string;
+  thawed_at?: string;
+  expires_at: string;
+  notes?: string;
+  created_at: string;
+  created_by?: string;
+}
+
+export interface MilkStash {
+  total_ml: number;
+  room_ml: number;
+  fridge_ml: number;
+  freezer_ml: number;
+  expired_ml: number;
+  bags: MilkBag[];
+  expiring_soon: MilkBag[];
+  expired: MilkBag[];
+}
+
+export interface Medication {
+  id: string;
+  child_id: string;
+  name: string;
+  dose_unit: string;
+  default_dose: number | null;
+  min_interval_minutes: number | null;
+  max_daily_doses: number | null;
+  notes?: string;
+  created_at: string;
+  created_by?: string;
+}
+
+export interface MedicationLog {
+  id: string;
+  child_id: string;
+  medication_id: string;
+  given_at: string;
+  dose: number | null;
+  notes?: string;
+  created_at: string;
+  created_by?: string;
+}
+
+export type GivenDose = MedicationLog & { medication_name: string; dose_unit: string };
+
+export interface TemperatureLog {
+  id: string;
+  child_id: string;
+  taken_at: string;
+  celsius: number;
+  method: 'axillary' | 'rectal' | 'ear';
+  fever: boolean;
+  notes?: string;
+  created_at: string;
+  created_by?: string;
+}
+
+export interface SymptomLog {
+  id: string;
+  child_id: string;
+  observed_at: string;
+  symptoms: string[];
+  severity: 'mild' | 'moderate' | 'severe';
+  notes?: string;
+  created_at: string;
+  created_by?: string;
+}
+
+export interface SickEpisode {
+  start_date: string;
+  end_date: string;
+  days: number;
+  ongoing: boolean;
+  fever_days: number;
+  max_celsius?: number;
+  symptoms: string[];
+  temperatures: TemperatureLog[];
+  symptom_logs: SymptomLog[];
+  doses: GivenDose[];
+}
+
+export interface VaccinationLog {
+  id: string;
+  child_id: string;
+  vaccine: string;
+  dose_number: number;
+  given_on: string;
+  clinic?: string;
+  lot_number?: string;
+  notes?: string;
+  created_at: string;
+  created_by?: string;
+}
+
+export interface DueDose {
+  vaccine: string;
+  name: string;
+  dose_number: number;
+  target_age: string;
+  target_date: string;
+  due_by: string;
+  status: 'overdue' | 'due' | 'upcoming';
+}
+
+export interface VaccinationsDue {
+  schedule_id: string;
+  schedule_name: string;
+  overdue: DueDose[];
+  due: DueDose[];
+  upcoming: DueDose[];
+}
+
+export type MilestoneCategory = 'motor' | 'social' | 'language' | 'cognitive' | 'physical';
+
+export interface Milestone {
+  id: string;
+  child_id: string;
+  code?: string;
+  title: string;
+  category: MilestoneCategory;
+  achieved_on: string;
+  photo_url?: string;
+  notes?: string;
+  created_at: string;
+  created_by?: string;
+}
+
+export interface CatalogAge {
+  months?: number;
+  weeks?: number;
+  days?: number;
+}
+
+export interface CatalogMilestone {
+  id: string;
+  name: string;
+  category: MilestoneCategory;
+  from: CatalogAge;
+  to: CatalogAge;
+}
+
+export interface ExpectedMilestone extends CatalogMilestone {
+  typical_from: string;
+  typical_to: string;
+  expected_from: string;
+  expected_by: string;
+  late: boolean;
+}
+
+export type Allergen = 'milk' | 'egg' | 'peanut' | 'tree_nut' | 'soy' | 'wheat' | 'fish' | 'shellfish' | 'sesame';
+export type Reaction = 'none' | 'mild' | 'moderate' | 'severe';
+
+export interface Food {
+  id: string;
+  name: string;
+  category: 'grain' | 'vegetable' | 'fruit' | 'protein' | 'dairy' | 'other';
+  allergens: Allergen[];
+  custom: boolean;
+}
+
+export interface SolidFeedingLog {
+  id: string;
+  child_id: string;
+  food_id: string;
+  food_name: string;
+  fed_at: string;
+  amount_grams?: number;
+  reaction: Reaction;
+  reaction_notes?: string;
+  notes?: string;
+  created_at: string;
+  created_by?: string;
+}
+
+export interface TriedFood extends Food {
+  first_fed_at: string;
+  last_fed_at: string;
+  times: number;
+  worst_reaction: Reaction;
+}
+
+export interface FoodIntroductions {
+  tried: TriedFood[];
+  allergens_introduced: { allergen: Allergen; first_fed_at: string; food_id: string; reacted: boolean }[];
+  allergens_pending: Allergen[];
+  waiting: { food_id: string; food_name: string; allergens: Allergen[]; introduced_at: string; wait_until: string }[];
+  next_allergen_at: string | null;
 }
 
 export interface DiaperLog {
@@ -38,6 +289,7 @@ export interface DiaperLog {
   changed_at: string;
   notes?: string;
   created_at: string;
+  created_by?: string;
 }
 
 export interface GrowthLog {
@@ -49,29 +301,162 @@ export interface GrowthLog {
   head_circumference_mm: number | null;
   notes?: string;
   created_at: string;
+  created_by?: string;
+  // Filled in by GET /growth
+  age_days?: number;
+  weight_for_age?: GrowthScore;
+  length_for_age?: GrowthScore;
+  head_circumference_for_age?: GrowthScore;
+  weight_for_length?: GrowthScore;
+  weight_velocity?: GrowthVelocity;
+  length_velocity?: GrowthVelocity;
+  warnings?: GrowthWarning[];
+}
+
+export interface GrowthVelocity {
+  since: string;
+  days: number;
+  rate: number;
+  unit: 'g/day' | 'mm/month';
+  p5?: number;
+  p50?: number;
+  p95?: number;
+  status?: 'low' | 'normal' | 'high';
+}
+
+export interface GrowthWarning {
+  code: 'percentile_drop' | 'percentile_rise' | 'low_velocity' | 'high_velocity';
+  indicator: string;
+  since: string;
+  message: string;
+}
+
+export interface GrowthScore {
+  z: number;
+  percentile: number;
+}
+
+export type GrowthIndicator = 'weight_for_age' | 'length_for_age' | 'head_circumference_for_age' | 'weight_for_length';
+
+export interface GrowthCurvePoint {
+  x: number;
+  p3: number;
+  p15: number;
+  p50: number;
+  p85: number;
+  p97: number;
+}
+
+export interface GrowthCurve {
+  indicator: GrowthIndicator;
+  name: string;
+  x: 'age_months' | 'length_cm';
+  unit: 'kg' | 'cm';
+  sex: 'male' | 'female';
+  points: GrowthCurvePoint[];
+}
+
+export interface Attachment {
+  id: string;
+  child_id: string;
+  log_kind: string; // a log kind, or 'child'
+  log_id: string;
+  filename: string;
+  content_type: string;
+  size_bytes: number;
+  sha256: string;
+  has_thumbnail: boolean;
+  created_at: string;
+  created_by?: string;
 }
 
 export interface DayStats {
   date: string;
   sleep_minutes: number;
   sleep_count: number;
+  day_sleep_minutes: number;
+  night_sleep_minutes: number;
+  night?: NightStats;
+  nap_count: number;
+  longest_wake_minutes: number;
   feeding_count: number;
   breast_feed_count: number;
   bottle_feed_count: number;
   bottle_ml_total: number;
+  solid_feed_count: number;
+  pumping_count: number;
+  pumped_ml_total: number;
   diaper_count: number;
   wet_count: number;
   dirty_count: number;
 }
 
+export interface NightStats {
+  bedtime: string;
+  wake_up: string;
+  longest_stretch_minutes: number;
+  wakings: number;
+}
+
 export interface DaySummary {
   date: string;
   total_sleep_minutes: number;
   sleep_count: number;
   feeding_count: number;
+  solid_feed_count: number;
+  pumping_count: number;
+  pumped_ml_total: number;
   diaper_count: number;
   last_weight_grams?: number;
   last_sleep_end_time?: string;
   active_sleep?: SleepLog;
   active_feeding?: FeedingLog;
+  active_pumping?: PumpingLog;
+  last_dose?: GivenDose;
+  next_breast_side?: 'left' | 'right';
+  predictions?: Predictions;
+  wake_window_minutes?: [number, number];
+  over_wake_window: boolean;
+}
+
+export interface SleepNeed {
+  from_weeks: number;
+  to_weeks: number;
+  wake_minutes: [number, number];
+  naps: [number, number];
+  sleep_minutes: [number, number];
+}
+
+export interface SleepCheck {
+  date: string;
+  age_weeks: number;
+  sleep_minutes: number;
+  nap_count: number;
+  longest_wake_minutes: number;
+  recommended?: SleepNeed;
+  sleep_deviation_minutes?: number;
+  nap_deviation?: number;
+  wake_deviation_minutes?: number;
+}
+
+export interface Predictions {
+  next_feed?: FeedPrediction;
+  next_nap?: NapPrediction;
+}
+
+export interface FeedPrediction {
+  at: string;
+  last_feed_at: string;
+  interval_minutes: number;
+  samples: number;
+  overdue: boolean;
+}
+
+export interface NapPrediction {
+  from: string;
+  to: string;
+  awake_since: string;
+  wake_minutes?: number;
+  recommended_wake_minutes?: [number, number];
+  samples: number;
 }
@@ -0,0 +1,86 @@
+// Package auth holds the credential primitives shared by the store, the HTTP
+// middleware and the CLI: password hashing, opaque tokens and the request
+// context carrying the authenticated user.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"baby-care/internal/model"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SessionCookie is the name of the HTTP-only cookie carrying the session token.
+const SessionCookie = "baby_care_session"
+
+// SessionTTL is how long a login stays valid.
+const SessionTTL = 30 * 24 * time.Hour
+
+// MinPasswordLength is the shortest password accepted for new accounts.
+const MinPasswordLength = 8
+
+var ErrPasswordTooShort = errors.New("password must be at least 8 characters")
+
+// HashPassword returns a bcrypt hash of password.
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", ErrPasswordTooShort
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// dummyHash is checked against in place of a missing hash, so that logging in
+// as an unknown user takes as long as with a wrong password.
+const dummyHash = "$2a$10$zT1g7/omvTf3BqywlpQlt.Kk5i7QukAo3jVwrc3Az5w4ZPz8UCsNC"
+
+// CheckPassword reports whether password matches the bcrypt hash. An empty
+// hash, as for an unknown user, never matches but takes as long to check.
+func CheckPassword(hash, password string) bool {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword([]byte(dummyHash), []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NewToken returns a random opaque token and the hash under which it is
+// stored. Only the hash is persisted, so a leaked database cannot be used to
+// impersonate anyone.
+func NewToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the stored form of an opaque token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type contextKey struct{}
+
+// WithUser returns a copy of ctx carrying the authenticated user.
+func WithUser(ctx context.Context, u *model.User) context.Context {
+	return context.WithValue(ctx, contextKey{}, u)
+}
+
+// UserFrom returns the authenticated user stored in ctx, or nil.
+func UserFrom(ctx context.Context) *model.User {
+	u, _ := ctx.Value(contextKey{}).(*model.User)
+	return u
+}
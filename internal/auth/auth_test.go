@@ -0,0 +1,81 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"baby-care/internal/auth"
+	"baby-care/internal/model"
+)
+
+func TestHashAndCheckPassword(t *testing.T) {
+	hash, err := auth.HashPassword("correct horse battery")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	if !auth.CheckPassword(hash, "correct horse battery") {
+		t.Error("expected password to match its hash")
+	}
+	if auth.CheckPassword(hash, "wrong horse battery") {
+		t.Error("expected wrong password not to match")
+	}
+	if auth.CheckPassword("", "") {
+		t.Error("expected an empty hash never to match")
+	}
+}
+
+func TestHashPassword_TooShort(t *testing.T) {
+	if _, err := auth.HashPassword("short"); !errors.Is(err, auth.ErrPasswordTooShort) {
+		t.Errorf("error = %v, want ErrPasswordTooShort", err)
+	}
+}
+
+func TestNewToken(t *testing.T) {
+	token, hash, err := auth.NewToken()
+	if err != nil {
+		t.Fatalf("NewToken: %v", err)
+	}
+	if token == "" || hash == token {
+		t.Fatalf("token = %q, hash = %q", token, hash)
+	}
+	if auth.HashToken(token) != hash {
+		t.Error("HashToken does not reproduce the stored hash")
+	}
+	other, _, _ := auth.NewToken()
+	if other == token {
+		t.Error("expected distinct tokens")
+	}
+}
+
+func TestUserContext(t *testing.T) {
+	if auth.UserFrom(context.Background()) != nil {
+		t.Error("expected no user in empty context")
+	}
+	u := &model.User{ID: "u1"}
+	if got := auth.UserFrom(auth.WithUser(context.Background(), u)); got != u {
+		t.Errorf("UserFrom = %v, want %v", got, u)
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	for _, tc := range []struct {
+		role, perm string
+		want       bool
+	}{
+		{auth.RoleParent, auth.PermHouseholdWrite, true},
+		{auth.RoleParent, auth.PermGrowthWrite, true},
+		{auth.RoleNanny, auth.PermSleepWrite, true},
+		{auth.RoleNanny, auth.PermGrowthRead, true},
+		{auth.RoleNanny, auth.PermGrowthWrite, false},
+		{auth.RoleNanny, auth.PermChildWrite, false},
+		{auth.RoleGrandparent, auth.PermSummaryRead, true},
+		{auth.RoleGrandparent, auth.PermSleepRead, false},
+		{auth.RoleGrandparent, auth.PermDiaperWrite, false},
+		{"stranger", auth.PermSummaryRead, false},
+	} {
+		if got := auth.Allowed(tc.role, tc.perm); got != tc.want {
+			t.Errorf("Allowed(%q, %q) = %v, want %v", tc.role, tc.perm, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"slices"
+
+	"baby-care/internal/model"
+)
+
+// Roles a household member can hold.
+const (
+	// RoleParent has full access, including the child profile, growth
+	// measurements and inviting other caregivers.
+	RoleParent = "parent"
+	// RoleNanny can log and edit daily care (sleep, feeding, pumping,
+	// diapers, health) and read everything else.
+	RoleNanny = "nanny"
+	// RoleGrandparent can only read the summary and analytics.
+	RoleGrandparent = "grandparent"
+)
+
+// Roles lists every valid role.
+var Roles = []string{RoleParent, RoleNanny, RoleGrandparent}
+
+// Permissions are "<resource>:<read|write>" pairs checked per route.
+const (
+	PermChildRead      = "child:read"
+	PermChildWrite     = "child:write"
+	PermSleepRead      = "sleep:read"
+	PermSleepWrite     = "sleep:write"
+	PermFeedingRead    = "feeding:read"
+	PermFeedingWrite   = "feeding:write"
+	PermPumpingRead    = "pumping:read"
+	PermPumpingWrite   = "pumping:write"
+	PermDiaperRead     = "diaper:read"
+	PermDiaperWrite    = "diaper:write"
+	PermGrowthRead     = "growth:read"
+	PermGrowthWrite    = "growth:write"
+	PermHealthRead     = "health:read"
+	PermHealthWrite    = "health:write"
+	PermSummaryRead    = "summary:read"
+	PermAnalyticsRead  = "analytics:read"
+	PermSettingsRead   = "settings:read"
+	PermSettingsWrite  = "settings:write"
+	PermHouseholdWrite = "household:write"
+	PermAuditRead      = "audit:read"
+)
+
+// Permissions lists every permission, in the order shown to users. They double
+// as the scopes an API token can be granted.
+var Permissions = []string{
+	PermChildRead, PermChildWrite,
+	PermSleepRead, PermSleepWrite,
+	PermFeedingRead, PermFeedingWrite,
+	PermPumpingRead, PermPumpingWrite,
+	PermDiaperRead, PermDiaperWrite,
+	PermGrowthRead, PermGrowthWrite,
+	PermHealthRead, PermHealthWrite,
+	PermSummaryRead, PermAnalyticsRead,
+	PermSettingsRead, PermSettingsWrite,
+	PermHouseholdWrite, PermAuditRead,
+}
+
+// rolePermissions lists what each non-parent role may do. Parents may do
+// everything.
+var rolePermissions = map[string][]string{
+	RoleNanny: {
+		PermChildRead,
+		PermSleepRead, PermSleepWrite,
+		PermFeedingRead, PermFeedingWrite,
+		PermPumpingRead, PermPumpingWrite,
+		PermDiaperRead, PermDiaperWrite,
+		PermGrowthRead,
+		PermHealthRead, PermHealthWrite,
+		PermSummaryRead, PermAnalyticsRead,
+		PermSettingsRead,
+	},
+	RoleGrandparent: {
+		PermChildRead,
+		PermSummaryRead, PermAnalyticsRead,
+		PermSettingsRead,
+	},
+}
+
+// ValidRole reports whether role is one of Roles.
+func ValidRole(role string) bool {
+	return slices.Contains(Roles, role)
+}
+
+// Allowed reports whether a member with the given role holds perm.
+func Allowed(role, perm string) bool {
+	if role == RoleParent {
+		return true
+	}
+	return slices.Contains(rolePermissions[role], perm)
+}
+
+// Can reports whether u may use perm: their role must allow it and, when
+// they authenticated with an API token, the token must be scoped for it.
+func Can(u *model.User, perm string) bool {
+	if u == nil || !Allowed(u.Role, perm) {
+		return false
+	}
+	return u.Scopes == nil || slices.Contains(u.Scopes, perm)
+}
@@ -0,0 +1,143 @@
+// Package blob stores uploaded files addressed by the SHA-256 of their
+// content, so identical uploads share one copy.
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrNotFound is returned when no blob has the requested hash.
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps immutable blobs. Hashes are lowercase hex SHA-256 digests.
+type Store interface {
+	// Put stores the content of r and returns its hash and size.
+	Put(r io.Reader) (hash string, size int64, err error)
+	// Open returns the blob with the given hash.
+	Open(hash string) (io.ReadSeekCloser, error)
+	// Delete removes a blob; deleting a missing blob is not an error.
+	Delete(hash string) error
+}
+
+// Dir is a Store keeping each blob in a file under root, fanned out by the
+// first two hex digits of its hash: root/ab/abcdef….
+type Dir struct {
+	root string
+}
+
+// NewDir returns a Store rooted at root. The directory is created on first
+// use.
+func NewDir(root string) *Dir {
+	return &Dir{root: root}
+}
+
+func (d *Dir) path(hash string) (string, error) {
+	if len(hash) != sha256.Size*2 {
+		return "", ErrNotFound
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return "", ErrNotFound
+	}
+	return filepath.Join(d.root, hash[:2], hash), nil
+}
+
+func (d *Dir) Put(r io.Reader) (string, int64, error) {
+	if err := os.MkdirAll(d.root, 0755); err != nil {
+		return "", 0, fmt.Errorf("create blob dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(d.root, ".upload-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("write blob: %w", err)
+	}
+
+	hash := hex.EncodeToString(h.Sum(nil))
+	dst, _ := d.path(hash)
+	if _, err := os.Stat(dst); err == nil {
+		// Touch the existing copy so Sweep treats it as a fresh upload.
+		now := time.Now()
+		os.Chtimes(dst, now, now)
+		return hash, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", 0, fmt.Errorf("create blob dir: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return "", 0, fmt.Errorf("store blob: %w", err)
+	}
+	return hash, size, nil
+}
+
+func (d *Dir) Open(hash string) (io.ReadSeekCloser, error) {
+	p, err := d.path(hash)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (d *Dir) Delete(hash string) error {
+	p, err := d.path(hash)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete blob: %w", err)
+	}
+	return nil
+}
+
+// Sweep deletes blobs older than minAge for which keep returns false, and
+// returns how many it deleted. The age check spares uploads whose database
+// row has not been written yet.
+func (d *Dir) Sweep(keep func(hash string) bool, minAge time.Duration) (int, error) {
+	cutoff := time.Now().Add(-minAge)
+	deleted := 0
+	err := filepath.WalkDir(d.root, func(p string, e fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if e.IsDir() {
+			return nil
+		}
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			return err
+		}
+		// Anything else that is old enough goes, including temp files left
+		// by interrupted uploads.
+		if _, err := d.path(e.Name()); err == nil && keep(e.Name()) {
+			return nil
+		}
+		if err := os.Remove(p); err != nil {
+			return err
+		}
+		deleted++
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,67 @@
+package blob
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDir(t *testing.T) {
+	d := NewDir(t.TempDir())
+	hash, size, err := d.Put(strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if hash != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" || size != 5 {
+		t.Errorf("Put = %s, %d", hash, size)
+	}
+	if again, _, err := d.Put(strings.NewReader("hello")); err != nil || again != hash {
+		t.Errorf("second Put = %s, %v; want the same hash", again, err)
+	}
+
+	f, err := d.Open(hash)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	b, _ := io.ReadAll(f)
+	f.Close()
+	if string(b) != "hello" {
+		t.Errorf("content = %q", b)
+	}
+
+	if _, err := d.Open("../../etc/passwd"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open of a bad hash error = %v, want ErrNotFound", err)
+	}
+	if err := d.Delete(hash); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := d.Open(hash); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open after Delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestDirSweep(t *testing.T) {
+	d := NewDir(t.TempDir())
+	keep, _, _ := d.Put(strings.NewReader("keep"))
+	drop, _, _ := d.Put(strings.NewReader("drop"))
+	fresh, _, _ := d.Put(strings.NewReader("fresh"))
+	old := time.Now().Add(-2 * time.Hour)
+	for _, h := range []string{keep, drop} {
+		p, _ := d.path(h)
+		os.Chtimes(p, old, old)
+	}
+
+	n, err := d.Sweep(func(h string) bool { return h == keep }, time.Hour)
+	if err != nil || n != 1 {
+		t.Fatalf("Sweep = %d, %v; want 1", n, err)
+	}
+	for h, want := range map[string]bool{keep: true, drop: false, fresh: true} {
+		_, err := d.Open(h)
+		if got := err == nil; got != want {
+			t.Errorf("blob %s present = %v, want %v", h[:8], got, want)
+		}
+	}
+}
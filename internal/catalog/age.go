@@ -0,0 +1,45 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Age is an age as the schedules state it, e.g. "2 months" or "6 weeks".
+// The parts add up.
+type Age struct {
+	Months int `json:"months,omitempty"`
+	Weeks  int `json:"weeks,omitempty"`
+	Days   int `json:"days,omitempty"`
+}
+
+// From returns the date a child born on dob reaches the age. Months that are
+// too short for the birth day end on their last day, so one month after
+// 31 January is 29 February, not 2 March.
+func (a Age) From(dob time.Time) time.Time {
+	y, m, d := dob.Date()
+	first := time.Date(y, m+time.Month(a.Months), 1, 0, 0, 0, 0, dob.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(d, last)-1+7*a.Weeks+a.Days)
+}
+
+// String renders the age for display: "birth", "6 weeks", "12 months 2 weeks".
+func (a Age) String() string {
+	var parts []string
+	for _, p := range []struct {
+		n    int
+		unit string
+	}{{a.Months, "month"}, {a.Weeks, "week"}, {a.Days, "day"}} {
+		switch {
+		case p.n == 1:
+			parts = append(parts, "1 "+p.unit)
+		case p.n > 1:
+			parts = append(parts, fmt.Sprintf("%d %ss", p.n, p.unit))
+		}
+	}
+	if len(parts) == 0 {
+		return "birth"
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,53 @@
+// Package catalog holds the reference data shipped inside the binary, such as
+// vaccination schedules, developmental milestones, first foods and the WHO
+// growth standards. The data lives in JSON files under data/ and is parsed
+// once at startup.
+package catalog
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+//go:embed data
+var dataFS embed.FS
+
+// loadDir decodes every JSON file in dir, in file name order.
+func loadDir[T any](dir string) ([]T, error) {
+	entries, err := fs.ReadDir(dataFS, dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []T
+	for _, e := range entries {
+		if path.Ext(e.Name()) != ".json" {
+			continue
+		}
+		v, err := loadFile[T](path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// loadFile decodes one JSON file, rejecting unknown fields so typos in the
+// data fail loudly.
+func loadFile[T any](name string) (T, error) {
+	var v T
+	f, err := dataFS.Open(name)
+	if err != nil {
+		return v, err
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&v); err != nil {
+		return v, fmt.Errorf("parse %s: %w", name, err)
+	}
+	return v, nil
+}
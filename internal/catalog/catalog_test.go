@@ -0,0 +1,161 @@
+package catalog
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestVaccineSchedules_Load(t *testing.T) {
+	for _, id := range []string{"vn-epi", "who"} {
+		s, ok := VaccineScheduleByID(id)
+		if !ok || len(s.Doses) == 0 {
+			t.Errorf("schedule %q missing or empty", id)
+		}
+	}
+	if _, ok := VaccineScheduleByID(DefaultVaccineSchedule); !ok {
+		t.Errorf("default schedule %q is not shipped", DefaultVaccineSchedule)
+	}
+}
+
+func TestAge(t *testing.T) {
+	dob, _ := time.Parse("2006-01-02", "2024-01-31")
+	cases := []struct {
+		age  Age
+		text string
+		date string
+	}{
+		{Age{}, "birth", "2024-01-31"},
+		{Age{Weeks: 6}, "6 weeks", "2024-03-13"},
+		{Age{Months: 1}, "1 month", "2024-02-29"},
+		{Age{Months: 12, Weeks: 2}, "12 months 2 weeks", "2025-02-14"},
+	}
+	for _, c := range cases {
+		if got := c.age.String(); got != c.text {
+			t.Errorf("%+v.String() = %q, want %q", c.age, got, c.text)
+		}
+		if got := c.age.From(dob).Format("2006-01-02"); got != c.date {
+			t.Errorf("%+v.From = %s, want %s", c.age, got, c.date)
+		}
+	}
+}
+
+func TestMilestones_Load(t *testing.T) {
+	dob, _ := time.Parse("2006-01-02", "2024-01-01")
+	for _, m := range Milestones() {
+		if m.To.From(dob).Before(m.From.From(dob)) {
+			t.Errorf("milestone %q ends before it starts", m.ID)
+		}
+	}
+	if _, ok := MilestoneByID("walks-alone"); !ok {
+		t.Error("walks-alone is missing from the catalogue")
+	}
+}
+
+func TestFoods_Load(t *testing.T) {
+	covered := map[string]bool{}
+	for _, f := range Foods() {
+		for _, a := range f.Allergens {
+			covered[a] = true
+		}
+	}
+	for _, a := range Allergens {
+		if !covered[a] {
+			t.Errorf("no catalogue food introduces %s", a)
+		}
+	}
+}
+
+func TestGrowthStandards(t *testing.T) {
+	for _, ind := range GrowthIndicators {
+		if _, ok := GrowthStandardFor(ind); !ok {
+			t.Errorf("growth standard %q is not shipped", ind)
+		}
+	}
+	wfa, _ := GrowthStandardFor(WeightForAge)
+
+	// The median boy at birth weighs 3.3464 kg.
+	if z, ok := wfa.ZScore("male", 0, 3.3464); !ok || math.Abs(z) > 1e-9 {
+		t.Errorf("z of the birth median = %v, %v; want 0", z, ok)
+	}
+	// Halfway through month 1 the parameters are interpolated.
+	p, _ := wfa.LMS("female", 0.5)
+	if math.Abs(p.M-(3.2322+4.1873)/2) > 1e-9 {
+		t.Errorf("M at 0.5 months = %v", p.M)
+	}
+	// Values read off the curves score back to their percentiles, within the
+	// rounding of the curve.
+	for _, pt := range wfa.Curve("female")[6:7] {
+		for pc, v := range map[float64]float64{3: pt.P3, 50: pt.P50, 97: pt.P97} {
+			z, _ := wfa.ZScore("female", pt.X, v)
+			if got := Percentile(z); math.Abs(got-pc) > 0.5 {
+				t.Errorf("percentile of the P%v line = %v", pc, got)
+			}
+		}
+	}
+	// Weights beyond 3 SD are scored by the 2–3 SD distance.
+	p, _ = wfa.LMS("male", 12)
+	sd2, sd3 := p.Value(2), p.Value(3)
+	if z, _ := wfa.ZScore("male", 12, sd3+(sd3-sd2)); math.Abs(z-4) > 1e-9 {
+		t.Errorf("z one 2–3 SD step above +3 = %v, want 4", z)
+	}
+	if _, ok := wfa.ZScore("male", 30, 12); ok {
+		t.Error("z beyond the table reported ok")
+	}
+	if _, ok := wfa.ZScore("other", 6, 8); ok {
+		t.Error("z without a sex standard reported ok")
+	}
+}
+
+func TestIncrementStandards(t *testing.T) {
+	wv, ok := IncrementStandardFor(WeightVelocity)
+	if !ok || wv.Unit != "g" || wv.IntervalMonths != 1 {
+		t.Fatalf("weight velocity standard = %+v", wv)
+	}
+	lv, ok := IncrementStandardFor(LengthVelocity)
+	if !ok || lv.Unit != "mm" || lv.IntervalMonths != 2 {
+		t.Fatalf("length velocity standard = %+v", lv)
+	}
+	for _, s := range []*IncrementStandard{wv, lv} {
+		for _, rows := range [][][4]float64{s.Male, s.Female} {
+			for _, r := range rows {
+				if !(r[1] < r[2] && r[2] < r[3]) {
+					t.Errorf("%s at %v months: percentiles out of order", s.Indicator, r[0])
+				}
+			}
+		}
+	}
+	// An age picks the interval that contains it.
+	if inc, ok := lv.At("female", 3.5); !ok || inc.P50 != 50 {
+		t.Errorf("length increment at 3.5 months = %+v, %v", inc, ok)
+	}
+	if _, ok := wv.At("male", 12); ok {
+		t.Error("weight increment past the end of the table")
+	}
+	if _, ok := wv.At("other", 2); ok {
+		t.Error("weight increment for a sex without a standard")
+	}
+}
+
+func TestSleepNeeds(t *testing.T) {
+	needs := SleepNeeds()
+	if len(needs) == 0 || needs[0].FromWeeks != 0 {
+		t.Fatalf("sleep needs do not start at birth: %+v", needs)
+	}
+	for _, tc := range []struct {
+		weeks int
+		wake  [2]int
+		ok    bool
+	}{
+		{0, [2]int{35, 60}, true},
+		{4, [2]int{60, 90}, true},
+		{11, [2]int{60, 90}, true},
+		{60, [2]int{240, 330}, true},
+		{156, [2]int{}, false},
+	} {
+		n, ok := SleepNeedAt(tc.weeks)
+		if ok != tc.ok || ok && n.WakeMinutes != tc.wake {
+			t.Errorf("SleepNeedAt(%d) = %+v, %v; want wake window %v", tc.weeks, n, ok, tc.wake)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package catalog
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Allergens are the major food allergens tracked during solid food
+// introduction.
+var Allergens = []string{"milk", "egg", "peanut", "tree_nut", "soy", "wheat", "fish", "shellfish", "sesame"}
+
+// FoodCategories are the groups foods are filed under.
+var FoodCategories = []string{"grain", "vegetable", "fruit", "protein", "dairy", "other"}
+
+// Food is a common first food and the allergens it contains.
+type Food struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Category  string   `json:"category"`
+	Allergens []string `json:"allergens,omitempty"`
+}
+
+var foods = mustLoadFoods()
+
+func mustLoadFoods() []*Food {
+	list, err := loadFile[[]*Food]("data/foods.json")
+	if err != nil {
+		panic(fmt.Sprintf("catalog: %v", err))
+	}
+	seen := map[string]bool{}
+	for _, f := range list {
+		if f.ID == "" || f.Name == "" || !slices.Contains(FoodCategories, f.Category) || seen[f.ID] {
+			panic(fmt.Sprintf("catalog: invalid or repeated food %+v", f))
+		}
+		for _, a := range f.Allergens {
+			if !slices.Contains(Allergens, a) {
+				panic(fmt.Sprintf("catalog: food %q has unknown allergen %q", f.ID, a))
+			}
+		}
+		seen[f.ID] = true
+	}
+	return list
+}
+
+// Foods returns the food catalogue.
+func Foods() []*Food {
+	return foods
+}
@@ -0,0 +1,181 @@
+package catalog
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// The indicators of the WHO Child Growth Standards shipped in data/growth.
+const (
+	WeightForAge            = "weight_for_age"
+	LengthForAge            = "length_for_age"
+	HeadCircumferenceForAge = "head_circumference_for_age"
+	WeightForLength         = "weight_for_length"
+)
+
+// GrowthIndicators lists the growth standard indicators.
+var GrowthIndicators = []string{WeightForAge, LengthForAge, HeadCircumferenceForAge, WeightForLength}
+
+// DaysPerMonth converts an age in days to the months of the age-based
+// tables, as the WHO does.
+const DaysPerMonth = 30.4375
+
+// LMS holds the Box-Cox power (L), median (M) and coefficient of variation
+// (S) describing the distribution of a measurement at one age or length.
+type LMS struct {
+	L, M, S float64
+}
+
+// Z returns the z-score of the measurement y.
+func (p LMS) Z(y float64) float64 {
+	if p.L == 0 {
+		return math.Log(y/p.M) / p.S
+	}
+	return (math.Pow(y/p.M, p.L) - 1) / (p.L * p.S)
+}
+
+// Value returns the measurement at z-score z.
+func (p LMS) Value(z float64) float64 {
+	if p.L == 0 {
+		return p.M * math.Exp(p.S*z)
+	}
+	return p.M * math.Pow(1+p.L*p.S*z, 1/p.L)
+}
+
+// GrowthStandard is one WHO indicator for boys and girls. Each row holds x
+// (age in months, or length in cm) followed by L, M and S; x is spaced
+// evenly and values in between are interpolated linearly. The shipped tables
+// cover birth to 24 months and lengths of 45 to 95 cm.
+type GrowthStandard struct {
+	Indicator string       `json:"indicator"`
+	Name      string       `json:"name"`
+	X         string       `json:"x"`    // age_months or length_cm
+	Unit      string       `json:"unit"` // of the measurement: kg or cm
+	Male      [][4]float64 `json:"male"`
+	Female    [][4]float64 `json:"female"`
+}
+
+var growthStandards = mustLoadGrowthStandards()
+
+func mustLoadGrowthStandards() []*GrowthStandard {
+	list, err := loadDir[*GrowthStandard]("data/growth")
+	if err != nil {
+		panic(fmt.Sprintf("catalog: %v", err))
+	}
+	for _, g := range list {
+		if !slices.Contains(GrowthIndicators, g.Indicator) || len(g.Male) < 2 || len(g.Male) != len(g.Female) {
+			panic(fmt.Sprintf("catalog: invalid growth standard %q", g.Indicator))
+		}
+		for i := range g.Male {
+			if g.Male[i][0] != g.Female[i][0] || i > 0 && g.Male[i][0] <= g.Male[i-1][0] {
+				panic(fmt.Sprintf("catalog: growth standard %q rows out of order at %v", g.Indicator, g.Male[i][0]))
+			}
+		}
+	}
+	return list
+}
+
+// GrowthStandardFor returns the standard of a growth indicator.
+func GrowthStandardFor(indicator string) (*GrowthStandard, bool) {
+	for _, g := range growthStandards {
+		if g.Indicator == indicator {
+			return g, true
+		}
+	}
+	return nil, false
+}
+
+func (g *GrowthStandard) rows(sex string) [][4]float64 {
+	switch sex {
+	case "male":
+		return g.Male
+	case "female":
+		return g.Female
+	}
+	return nil
+}
+
+// LMS returns the parameters at x for sex ("male" or "female"). It reports
+// false outside the table and for other sexes.
+func (g *GrowthStandard) LMS(sex string, x float64) (LMS, bool) {
+	rows := g.rows(sex)
+	if len(rows) == 0 || x < rows[0][0] || x > rows[len(rows)-1][0] {
+		return LMS{}, false
+	}
+	i, _ := slices.BinarySearchFunc(rows, x, func(r [4]float64, x float64) int {
+		switch {
+		case r[0] < x:
+			return -1
+		case r[0] > x:
+			return 1
+		}
+		return 0
+	})
+	if rows[i][0] == x {
+		return LMS{rows[i][1], rows[i][2], rows[i][3]}, true
+	}
+	lo, hi := rows[i-1], rows[i]
+	t := (x - lo[0]) / (hi[0] - lo[0])
+	lerp := func(k int) float64 { return lo[k] + t*(hi[k]-lo[k]) }
+	return LMS{lerp(1), lerp(2), lerp(3)}, true
+}
+
+// ZScore returns the z-score of measurement y at x. Beyond ±3 weights are
+// scored by the distance between the 2 and 3 SD lines, as the WHO does to
+// keep the skewed tail from compressing extreme values.
+func (g *GrowthStandard) ZScore(sex string, x, y float64) (float64, bool) {
+	p, ok := g.LMS(sex, x)
+	if !ok || y <= 0 {
+		return 0, false
+	}
+	z := p.Z(y)
+	if g.Unit == "kg" {
+		switch {
+		case z > 3:
+			sd2, sd3 := p.Value(2), p.Value(3)
+			z = 3 + (y-sd3)/(sd3-sd2)
+		case z < -3:
+			sd2, sd3 := p.Value(-2), p.Value(-3)
+			z = -3 + (y-sd3)/(sd2-sd3)
+		}
+	}
+	return z, true
+}
+
+// PercentileLines are the major percentiles drawn on WHO growth charts.
+var PercentileLines = []float64{3, 15, 50, 85, 97}
+
+// CurvePoint holds the reference lines drawn on WHO growth charts at one x:
+// the 3rd, 15th, 50th, 85th and 97th percentiles.
+type CurvePoint struct {
+	X   float64 `json:"x"`
+	P3  float64 `json:"p3"`
+	P15 float64 `json:"p15"`
+	P50 float64 `json:"p50"`
+	P85 float64 `json:"p85"`
+	P97 float64 `json:"p97"`
+}
+
+// Curve returns the reference lines for sex at every row of the table,
+// rounded to hundredths, or nil for sexes without a standard.
+func (g *GrowthStandard) Curve(sex string) []CurvePoint {
+	var points []CurvePoint
+	for _, r := range g.rows(sex) {
+		p := LMS{r[1], r[2], r[3]}
+		at := func(pc float64) float64 { return math.Round(p.Value(PercentileZ(pc))*100) / 100 }
+		points = append(points, CurvePoint{X: r[0], P3: at(3), P15: at(15), P50: math.Round(r[2]*100) / 100, P85: at(85), P97: at(97)})
+	}
+	return points
+}
+
+// Percentile converts a z-score to the percentile of the standard normal
+// distribution, from 0 to 100.
+func Percentile(z float64) float64 {
+	return 50 * (1 + math.Erf(z/math.Sqrt2))
+}
+
+// PercentileZ is the inverse of Percentile.
+func PercentileZ(pc float64) float64 {
+	return math.Sqrt2 * math.Erfinv(pc/50-1)
+}
@@ -0,0 +1,79 @@
+package catalog
+
+import (
+	"fmt"
+	"slices"
+)
+
+// The WHO growth velocity standards shipped in data/increments.
+const (
+	WeightVelocity = "weight_velocity"
+	LengthVelocity = "length_velocity"
+)
+
+// IncrementStandard is a WHO growth velocity standard: how much weight or
+// length children gain over a fixed interval. Each row holds the age in
+// months at which the interval starts followed by the 5th, 50th and 95th
+// percentile increments, in Unit. The shipped tables cover 1-month weight
+// increments to 12 months and 2-month length increments to 24 months.
+type IncrementStandard struct {
+	Indicator      string       `json:"indicator"`
+	Name           string       `json:"name"`
+	IntervalMonths float64      `json:"interval_months"`
+	Unit           string       `json:"unit"` // g or mm
+	Male           [][4]float64 `json:"male"`
+	Female         [][4]float64 `json:"female"`
+}
+
+// Increment is the P5, P50 and P95 gain over one interval.
+type Increment struct {
+	P5, P50, P95 float64
+}
+
+var incrementStandards = mustLoadIncrementStandards()
+
+func mustLoadIncrementStandards() []*IncrementStandard {
+	list, err := loadDir[*IncrementStandard]("data/increments")
+	if err != nil {
+		panic(fmt.Sprintf("catalog: %v", err))
+	}
+	for _, s := range list {
+		if !slices.Contains([]string{WeightVelocity, LengthVelocity}, s.Indicator) || s.IntervalMonths <= 0 || len(s.Male) == 0 || len(s.Male) != len(s.Female) {
+			panic(fmt.Sprintf("catalog: invalid increment standard %q", s.Indicator))
+		}
+		for i := range s.Male {
+			if s.Male[i][0] != s.Female[i][0] || i > 0 && s.Male[i][0] != s.Male[i-1][0]+s.IntervalMonths {
+				panic(fmt.Sprintf("catalog: increment standard %q rows out of order at %v", s.Indicator, s.Male[i][0]))
+			}
+		}
+	}
+	return list
+}
+
+// IncrementStandardFor returns the velocity standard of an indicator.
+func IncrementStandardFor(indicator string) (*IncrementStandard, bool) {
+	for _, s := range incrementStandards {
+		if s.Indicator == indicator {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// At returns the increments over the interval that includes age months for
+// sex. It reports false outside the table and for other sexes.
+func (s *IncrementStandard) At(sex string, months float64) (Increment, bool) {
+	var rows [][4]float64
+	switch sex {
+	case "male":
+		rows = s.Male
+	case "female":
+		rows = s.Female
+	}
+	for _, r := range rows {
+		if months >= r[0] && months < r[0]+s.IntervalMonths {
+			return Increment{r[1], r[2], r[3]}, true
+		}
+	}
+	return Increment{}, false
+}
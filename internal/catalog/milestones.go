@@ -0,0 +1,52 @@
+package catalog
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MilestoneCategories are the areas of development milestones belong to.
+var MilestoneCategories = []string{"motor", "social", "language", "cognitive", "physical"}
+
+// Milestone is a standard developmental milestone with the age window most
+// children reach it in. The motor windows follow the WHO Multicentre Growth
+// Reference Study; the others follow common paediatric guidance.
+type Milestone struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	From     Age    `json:"from"` // earliest typical age
+	To       Age    `json:"to"`   // latest typical age
+}
+
+var milestones = mustLoadMilestones()
+
+func mustLoadMilestones() []*Milestone {
+	list, err := loadFile[[]*Milestone]("data/milestones.json")
+	if err != nil {
+		panic(fmt.Sprintf("catalog: %v", err))
+	}
+	seen := map[string]bool{}
+	for _, m := range list {
+		if m.ID == "" || m.Name == "" || !slices.Contains(MilestoneCategories, m.Category) || seen[m.ID] {
+			panic(fmt.Sprintf("catalog: invalid or repeated milestone %+v", m))
+		}
+		seen[m.ID] = true
+	}
+	return list
+}
+
+// Milestones returns the milestone catalogue, by typical age.
+func Milestones() []*Milestone {
+	return milestones
+}
+
+// MilestoneByID returns the catalogued milestone with the given ID.
+func MilestoneByID(id string) (*Milestone, bool) {
+	for _, m := range milestones {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return nil, false
+}
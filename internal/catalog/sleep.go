@@ -0,0 +1,49 @@
+package catalog
+
+import "fmt"
+
+// SleepNeed is the sleep recommended for children in an age band, following
+// common paediatric guidance. Each range holds the least and the most.
+type SleepNeed struct {
+	FromWeeks    int    `json:"from_weeks"`
+	ToWeeks      int    `json:"to_weeks"`      // exclusive
+	WakeMinutes  [2]int `json:"wake_minutes"`  // time awake between sleeps
+	Naps         [2]int `json:"naps"`          // daytime sleeps a day
+	SleepMinutes [2]int `json:"sleep_minutes"` // total sleep in 24 hours
+}
+
+var sleepNeeds = mustLoadSleepNeeds()
+
+func mustLoadSleepNeeds() []*SleepNeed {
+	list, err := loadFile[[]*SleepNeed]("data/sleep-needs.json")
+	if err != nil {
+		panic(fmt.Sprintf("catalog: %v", err))
+	}
+	for i, n := range list {
+		ranges := [][2]int{n.WakeMinutes, n.Naps, n.SleepMinutes}
+		valid := n.ToWeeks > n.FromWeeks && n.WakeMinutes[0] > 0 && n.SleepMinutes[0] > 0 && (i == 0 || n.FromWeeks == list[i-1].ToWeeks)
+		for _, r := range ranges {
+			valid = valid && r[0] >= 0 && r[1] >= r[0]
+		}
+		if !valid {
+			panic(fmt.Sprintf("catalog: invalid sleep need %+v", n))
+		}
+	}
+	return list
+}
+
+// SleepNeeds returns the sleep recommendations, youngest first.
+func SleepNeeds() []*SleepNeed {
+	return sleepNeeds
+}
+
+// SleepNeedAt returns the recommendations for a child aged weeks. It reports
+// false beyond the end of the table.
+func SleepNeedAt(weeks int) (*SleepNeed, bool) {
+	for _, n := range sleepNeeds {
+		if weeks >= n.FromWeeks && weeks < n.ToWeeks {
+			return n, true
+		}
+	}
+	return nil, false
+}
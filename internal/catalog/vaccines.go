@@ -0,0 +1,100 @@
+package catalog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultVaccineSchedule is the schedule used until the household picks
+// another: Vietnam's Expanded Programme on Immunization.
+const DefaultVaccineSchedule = "vn-epi"
+
+// DefaultGraceDays is how long after its target age a dose counts as due
+// rather than overdue, unless the dose sets its own grace period.
+const DefaultGraceDays = 30
+
+// VaccineSchedule is a national or recommended immunization schedule.
+type VaccineSchedule struct {
+	ID     string        `json:"id"`
+	Name   string        `json:"name"`
+	Source string        `json:"source,omitempty"`
+	Doses  []VaccineDose `json:"doses"`
+}
+
+// VaccineDose is one dose of a schedule. Vaccine is a short code
+// ("bcg", "dtp-hepb-hib") that vaccination logs refer to.
+type VaccineDose struct {
+	Vaccine   string `json:"vaccine"`
+	Name      string `json:"name"`
+	Dose      int    `json:"dose"`
+	Age       Age    `json:"age"`
+	GraceDays int    `json:"grace_days,omitempty"`
+}
+
+// Grace returns the dose's grace period in days.
+func (d VaccineDose) Grace() int {
+	if d.GraceDays > 0 {
+		return d.GraceDays
+	}
+	return DefaultGraceDays
+}
+
+var vaccineSchedules = mustLoadVaccineSchedules()
+
+func mustLoadVaccineSchedules() []*VaccineSchedule {
+	schedules, err := loadDir[*VaccineSchedule]("data/vaccines")
+	if err != nil {
+		panic(fmt.Sprintf("catalog: %v", err))
+	}
+	for _, s := range schedules {
+		if err := s.check(); err != nil {
+			panic(fmt.Sprintf("catalog: vaccine schedule %q: %v", s.ID, err))
+		}
+	}
+	slices.SortFunc(schedules, func(a, b *VaccineSchedule) int { return strings.Compare(a.ID, b.ID) })
+	return schedules
+}
+
+// check rejects schedules with missing codes or repeated doses.
+func (s *VaccineSchedule) check() error {
+	if s.ID == "" || s.Name == "" {
+		return fmt.Errorf("id and name are required")
+	}
+	seen := map[string]bool{}
+	for _, d := range s.Doses {
+		if d.Vaccine == "" || d.Name == "" || d.Dose < 1 {
+			return fmt.Errorf("dose %+v needs a vaccine, a name and a dose number", d)
+		}
+		key := fmt.Sprintf("%s#%d", d.Vaccine, d.Dose)
+		if seen[key] {
+			return fmt.Errorf("%s dose %d is listed twice", d.Vaccine, d.Dose)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// VaccineSchedules returns the shipped schedules, by ID.
+func VaccineSchedules() []*VaccineSchedule {
+	return vaccineSchedules
+}
+
+// VaccineScheduleByID returns the schedule with the given ID.
+func VaccineScheduleByID(id string) (*VaccineSchedule, bool) {
+	for _, s := range vaccineSchedules {
+		if s.ID == id {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// VaccineScheduleIDs lists the IDs of the shipped schedules.
+func VaccineScheduleIDs() []string {
+	ids := make([]string, len(vaccineSchedules))
+	for i, s := range vaccineSchedules {
+		ids[i] = s.ID
+	}
+	return ids
+}
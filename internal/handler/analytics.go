@@ -8,21 +8,13 @@ import (
 )
 
 func (h *Handler) GetAnalytics(w http.ResponseWriter, r *http.Request) {
-	childID, ok := h.requireChild(w)
+	childID, ok := h.resolveChild(w, r)
 	if !ok {
 		return
 	}
-
-	to := r.URL.Query().Get("to")
-	from := r.URL.Query().Get("from")
-
-	// Default: last 7 days in GMT+7
-	now := time.Now().In(hcmcTZ)
-	if to == "" {
-		to = now.Format("2006-01-02")
-	}
-	if from == "" {
-		from = now.AddDate(0, 0, -6).Format("2006-01-02")
+	from, to, ok := h.analyticsRange(w, r)
+	if !ok {
+		return
 	}
 
 	days, err := h.Store.GetAnalytics(childID, from, to)
@@ -35,3 +27,46 @@ func (h *Handler) GetAnalytics(w http.ResponseWriter, r *http.Request) {
 	}
 	h.JSON(w, http.StatusOK, days)
 }
+
+// GetSleepChecks compares each day's sleep with the recommendations for the
+// child's age.
+func (h *Handler) GetSleepChecks(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	from, to, ok := h.analyticsRange(w, r)
+	if !ok {
+		return
+	}
+
+	checks, err := h.Store.GetSleepChecks(childID, from, to)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, checks)
+}
+
+// analyticsRange reads the ?from= and ?to= dates, defaulting to the last 7
+// days in the household timezone.
+func (h *Handler) analyticsRange(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	v := h.validator()
+	to := r.URL.Query().Get("to")
+	from := r.URL.Query().Get("from")
+	v.Date("from", from)
+	v.Date("to", to)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return "", "", false
+	}
+
+	now := time.Now().In(h.Store.Location())
+	if to == "" {
+		to = now.Format("2006-01-02")
+	}
+	if from == "" {
+		from = now.AddDate(0, 0, -6).Format("2006-01-02")
+	}
+	return from, to, true
+}
@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"baby-care/internal/auth"
+	"baby-care/internal/blob"
+	"baby-care/internal/model"
+	"baby-care/internal/store"
+	"baby-care/internal/thumbnail"
+	"baby-care/internal/validate"
+)
+
+const (
+	// maxAttachmentBytes caps the size of one uploaded file.
+	maxAttachmentBytes = 10 << 20
+	// thumbnailSize is the longest side of generated thumbnails, in pixels.
+	thumbnailSize = 256
+)
+
+// attachmentTypes lists the content types accepted for upload, as sniffed
+// from the file itself rather than trusted from the client.
+var attachmentTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf"}
+
+// attachmentPerm returns the permission needed to read, or with write to
+// change, attachments of kind.
+func attachmentPerm(kind string, write bool) string {
+	switch {
+	case kind == store.AttachChild && write:
+		return auth.PermChildWrite
+	case kind == store.AttachChild:
+		return auth.PermChildRead
+	case write:
+		return logWritePerms[kind]
+	default:
+		return logReadPerms[kind]
+	}
+}
+
+// resolveAttachTarget returns the child and the ID of the entry that the
+// request attaches to: the {logId} entry of kind, or the child itself.
+func (h *Handler) resolveAttachTarget(w http.ResponseWriter, r *http.Request, kind string) (childID, logID string, ok bool) {
+	childID, ok = h.resolveChild(w, r)
+	if !ok {
+		return "", "", false
+	}
+	if kind == store.AttachChild {
+		return childID, childID, true
+	}
+	logID, ok = h.resolveLog(w, r, kind)
+	return childID, logID, ok
+}
+
+// ListAttachments serves GET /{kind}/{logId}/attachments, or GET /attachments
+// for files about the child.
+func (h *Handler) ListAttachments(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, logID, ok := h.resolveAttachTarget(w, r, kind)
+		if !ok {
+			return
+		}
+		list, err := h.Store.ListAttachments(kind, logID)
+		if err != nil {
+			h.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if list == nil {
+			list = []*model.Attachment{}
+		}
+		h.JSON(w, http.StatusOK, list)
+	}
+}
+
+// UploadAttachment serves the multipart POST of a single "file" part to
+// /{kind}/{logId}/attachments, or to /attachments for the child. JPEG and PNG
+// images get a thumbnail.
+func (h *Handler) UploadAttachment(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		childID, logID, ok := h.resolveAttachTarget(w, r, kind)
+		if !ok {
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentBytes+1<<20)
+		mr, err := r.MultipartReader()
+		if err != nil {
+			h.Error(w, http.StatusBadRequest, "expected a multipart/form-data upload")
+			return
+		}
+		var part io.Reader
+		var filename string
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				h.uploadError(w, err)
+				return
+			}
+			if p.FormName() == "file" {
+				part, filename = p, p.FileName()
+				break
+			}
+		}
+		v := h.validator()
+		if part == nil {
+			v.Add("file", validate.CodeRequired, "is required")
+			h.Invalid(w, v.Err())
+			return
+		}
+
+		// Sniff the type from the first bytes, then store them with the rest.
+		head := make([]byte, 512)
+		n, err := io.ReadFull(part, head)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			h.uploadError(w, err)
+			return
+		}
+		head = head[:n]
+		contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+		v.OneOf("file", contentType, attachmentTypes...)
+		if err := v.Err(); err != nil {
+			h.Invalid(w, err)
+			return
+		}
+
+		hash, size, err := h.Blobs.Put(io.LimitReader(io.MultiReader(bytes.NewReader(head), part), maxAttachmentBytes+1))
+		if err != nil {
+			h.uploadError(w, err)
+			return
+		}
+		if size > maxAttachmentBytes {
+			h.deleteBlob(hash)
+			h.Error(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("file is larger than %d MB", maxAttachmentBytes>>20))
+			return
+		}
+		thumbHash := h.thumbnail(hash, contentType)
+
+		a, err := h.storeFor(r).CreateAttachment(childID, kind, logID, cleanFilename(filename, contentType), contentType, hash, thumbHash, size)
+		if err != nil {
+			h.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		h.JSON(w, http.StatusCreated, a)
+	}
+}
+
+func (h *Handler) uploadError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		h.Error(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("file is larger than %d MB", maxAttachmentBytes>>20))
+		return
+	}
+	h.Error(w, http.StatusBadRequest, "invalid upload: "+err.Error())
+}
+
+// thumbnail stores a thumbnail of the image blob hash and returns its hash,
+// or "" for files that get none. A picture that fails to decode is still
+// kept; it just has no preview.
+func (h *Handler) thumbnail(hash, contentType string) string {
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return ""
+	}
+	f, err := h.Blobs.Open(hash)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	thumb, err := thumbnail.Generate(f, thumbnailSize)
+	if err != nil {
+		return ""
+	}
+	thumbHash, _, err := h.Blobs.Put(bytes.NewReader(thumb))
+	if err != nil {
+		return ""
+	}
+	return thumbHash
+}
+
+// cleanFilename strips any client path from name, falling back to a name
+// derived from the content type.
+func cleanFilename(name, contentType string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" || name == "" {
+		name = "attachment"
+		if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+			name += exts[0]
+		}
+	}
+	return name
+}
+
+// resolveAttachment returns the {attId} attachment of the child addressed by
+// the request, after checking the user may read it or, with write, change it.
+func (h *Handler) resolveAttachment(w http.ResponseWriter, r *http.Request, write bool) (*model.Attachment, bool) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return nil, false
+	}
+	a, err := h.Store.GetAttachment(r.PathValue("attId"))
+	if err != nil && !h.IsNotFound(err) {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if err != nil || a.ChildID != childID {
+		h.Error(w, http.StatusNotFound, "attachment not found")
+		return nil, false
+	}
+	if perm := attachmentPerm(a.LogKind, write); !auth.Can(auth.UserFrom(r.Context()), perm) {
+		h.Error(w, http.StatusForbidden, "forbidden: requires "+perm)
+		return nil, false
+	}
+	return a, true
+}
+
+// GetAttachmentFile serves the content of an attachment.
+func (h *Handler) GetAttachmentFile(w http.ResponseWriter, r *http.Request) {
+	a, ok := h.resolveAttachment(w, r, false)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": a.Filename}))
+	h.serveBlob(w, r, a.SHA256, a.ContentType)
+}
+
+// GetAttachmentThumbnail serves the JPEG preview of an image attachment.
+func (h *Handler) GetAttachmentThumbnail(w http.ResponseWriter, r *http.Request) {
+	a, ok := h.resolveAttachment(w, r, false)
+	if !ok {
+		return
+	}
+	if a.ThumbnailSHA256 == "" {
+		h.Error(w, http.StatusNotFound, "attachment has no thumbnail")
+		return
+	}
+	h.serveBlob(w, r, a.ThumbnailSHA256, "image/jpeg")
+}
+
+// serveBlob writes a blob with range and conditional request support. Blobs
+// never change, so the hash doubles as a strong ETag.
+func (h *Handler) serveBlob(w http.ResponseWriter, r *http.Request, hash, contentType string) {
+	f, err := h.Blobs.Open(hash)
+	if errors.Is(err, blob.ErrNotFound) {
+		h.Error(w, http.StatusNotFound, "attachment file missing")
+		return
+	}
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	w.Header().Set("ETag", `"`+hash+`"`)
+	http.ServeContent(w, r, "", time.Time{}, f)
+}
+
+// DeleteAttachment removes an attachment, and its files once no other
+// attachment shares them.
+func (h *Handler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	a, ok := h.resolveAttachment(w, r, true)
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeleteAttachment(a.ID); err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.deleteBlob(a.SHA256)
+	if a.ThumbnailSHA256 != "" {
+		h.deleteBlob(a.ThumbnailSHA256)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteBlob removes a blob unless an attachment still refers to it. Failures
+// only leave garbage behind for the periodic sweep.
+func (h *Handler) deleteBlob(hash string) {
+	if inUse, err := h.Store.BlobInUse(hash); err == nil && !inUse {
+		h.Blobs.Delete(hash)
+	}
+}
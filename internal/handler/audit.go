@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"baby-care/internal/model"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 200
+)
+
+type auditPage struct {
+	Entries []*model.AuditEntry `json:"entries"`
+	// NextCursor is passed as ?cursor= to fetch the following page. It is
+	// omitted on the last page.
+	NextCursor int64 `json:"next_cursor,omitempty"`
+}
+
+// LogHistory serves the change history of one log entry of the given kind,
+// oldest first.
+func (h *Handler) LogHistory(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := h.resolveLog(w, r, kind)
+		if !ok {
+			return
+		}
+		entries, err := h.Store.GetLogHistory(kind, id)
+		if err != nil {
+			h.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if entries == nil {
+			entries = []*model.AuditEntry{}
+		}
+		h.JSON(w, http.StatusOK, entries)
+	}
+}
+
+// ListAudit serves the household-wide audit feed, newest first.
+// Query params: ?entity=sleep, ?limit=50 (max 200), ?cursor=<next_cursor>.
+func (h *Handler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := defaultAuditLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			h.Error(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = min(n, maxAuditLimit)
+	}
+	var cursor int64
+	if v := q.Get("cursor"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 {
+			h.Error(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		cursor = n
+	}
+
+	entries, err := h.Store.ListAudit(q.Get("entity"), cursor, limit)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	page := auditPage{Entries: entries}
+	if page.Entries == nil {
+		page.Entries = []*model.AuditEntry{}
+	}
+	if len(entries) == limit {
+		page.NextCursor = entries[len(entries)-1].ID
+	}
+	h.JSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"baby-care/internal/auth"
+	"baby-care/internal/model"
+)
+
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Authenticate resolves the API token or session cookie of a request to its
+// user. It implements middleware.Authenticator.
+func (h *Handler) Authenticate(r *http.Request) (*model.User, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			return nil, nil
+		}
+		user, err := h.Store.GetAPITokenUser(auth.HashToken(token))
+		if h.IsNotFound(err) {
+			return nil, nil
+		}
+		return user, err
+	}
+
+	cookie, err := r.Cookie(auth.SessionCookie)
+	if err != nil || cookie.Value == "" {
+		return nil, nil
+	}
+	user, err := h.Store.GetSessionUser(auth.HashToken(cookie.Value))
+	if h.IsNotFound(err) {
+		return nil, nil
+	}
+	return user, err
+}
+
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	user, hash, err := h.Store.GetUserCredentials(req.Username)
+	if err != nil && !h.IsNotFound(err) {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// Check the password even for an unknown user so the response time does
+	// not tell which usernames exist.
+	if ok := auth.CheckPassword(hash, req.Password); err != nil || !ok {
+		h.Error(w, http.StatusUnauthorized, "invalid username or password")
+		return
+	}
+	if err := h.startSession(w, r, user); err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, user)
+}
+
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(auth.SessionCookie); err == nil {
+		if err := h.Store.DeleteSession(auth.HashToken(cookie.Value)); err != nil {
+			h.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	http.SetCookie(w, sessionCookie(r, "", -1))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) Me(w http.ResponseWriter, r *http.Request) {
+	h.JSON(w, http.StatusOK, auth.UserFrom(r.Context()))
+}
+
+func (h *Handler) startSession(w http.ResponseWriter, r *http.Request, user *model.User) error {
+	token, hash, err := auth.NewToken()
+	if err != nil {
+		return err
+	}
+	if err := h.Store.CreateSession(hash, user.ID, auth.SessionTTL); err != nil {
+		return err
+	}
+	http.SetCookie(w, sessionCookie(r, token, int(auth.SessionTTL/time.Second)))
+	return nil
+}
+
+// sessionCookie builds the session cookie. It is marked Secure whenever the
+// request reached us over HTTPS, including behind a TLS-terminating proxy.
+func sessionCookie(r *http.Request, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     auth.SessionCookie,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
@@ -2,6 +2,10 @@ package handler
 
 import (
 	"net/http"
+	"strings"
+
+	"baby-care/internal/model"
+	"baby-care/internal/validate"
 )
 
 type childRequest struct {
@@ -12,8 +16,92 @@ type childRequest struct {
 	Notes       string `json:"notes"`
 }
 
+func (req childRequest) validate(v *validate.Validator) {
+	v.Required("name", req.Name)
+	if v.Required("date_of_birth", req.DateOfBirth) {
+		v.PastDate("date_of_birth", req.DateOfBirth)
+	}
+	if v.Required("gender", req.Gender) {
+		v.OneOf("gender", req.Gender, validate.Genders...)
+	}
+}
+
+// lookupChild returns the child addressed by the {childId} path segment, or
+// the first child when the request came through a legacy /api/v1 alias.
+func (h *Handler) lookupChild(r *http.Request) (*model.Child, error) {
+	if id := r.PathValue("childId"); id != "" {
+		return h.Store.GetChildByID(id)
+	}
+	return h.Store.GetChild()
+}
+
+// resolveChild writes the error response and returns false when the request
+// does not address an existing child.
+func (h *Handler) resolveChild(w http.ResponseWriter, r *http.Request) (string, bool) {
+	child, err := h.lookupChild(r)
+	if err != nil {
+		switch {
+		case !h.IsNotFound(err):
+			h.Error(w, http.StatusInternalServerError, err.Error())
+		case r.PathValue("childId") != "":
+			h.Error(w, http.StatusNotFound, "child not found")
+		default:
+			h.Error(w, http.StatusBadRequest, "no child profile found")
+		}
+		return "", false
+	}
+	return child.ID, true
+}
+
+// resolveLog checks that the {logId} entry of the given kind belongs to the
+// child addressed by the request and returns its ID.
+func (h *Handler) resolveLog(w http.ResponseWriter, r *http.Request, kind string) (string, bool) {
+	return h.resolveOwnedLog(w, r, kind, h.Store.LogChildID)
+}
+
+// resolveTrashedLog is resolveLog for entries in the trash.
+func (h *Handler) resolveTrashedLog(w http.ResponseWriter, r *http.Request, kind string) (string, bool) {
+	return h.resolveOwnedLog(w, r, kind, h.Store.TrashedLogChildID)
+}
+
+// logNotFound is the 404 message for a missing entry of kind, e.g.
+// "medication log not found" for kind medication_log.
+func logNotFound(kind string) string {
+	return strings.TrimSuffix(kind, "_log") + " log not found"
+}
+
+func (h *Handler) resolveOwnedLog(w http.ResponseWriter, r *http.Request, kind string, owner func(kind, id string) (string, error)) (string, bool) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return "", false
+	}
+	id := r.PathValue("logId")
+	ownerID, err := owner(kind, id)
+	if err != nil && !h.IsNotFound(err) {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return "", false
+	}
+	if err != nil || ownerID != childID {
+		h.Error(w, http.StatusNotFound, logNotFound(kind))
+		return "", false
+	}
+	return id, true
+}
+
+func (h *Handler) ListChildren(w http.ResponseWriter, r *http.Request) {
+	children, err := h.Store.ListChildren()
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if children == nil {
+		children = []*model.Child{}
+	}
+	h.JSON(w, http.StatusOK, children)
+}
+
 func (h *Handler) GetChild(w http.ResponseWriter, r *http.Request) {
-	child, err := h.Store.GetChild()
+	child, err := h.lookupChild(r)
 	if err != nil {
 		if h.IsNotFound(err) {
 			h.JSON(w, http.StatusNotFound, map[string]string{"message": "no child yet"})
@@ -31,11 +119,13 @@ func (h *Handler) CreateChild(w http.ResponseWriter, r *http.Request) {
 		h.Error(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	if req.Name == "" || req.DateOfBirth == "" || req.Gender == "" {
-		h.Error(w, http.StatusBadRequest, "name, date_of_birth, and gender are required")
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
 		return
 	}
-	child, err := h.Store.CreateChild(req.Name, req.DateOfBirth, req.Gender, req.PhotoURL, req.Notes)
+	child, err := h.storeFor(r).CreateChild(req.Name, req.DateOfBirth, req.Gender, req.PhotoURL, req.Notes)
 	if err != nil {
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -44,7 +134,7 @@ func (h *Handler) CreateChild(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) UpdateChild(w http.ResponseWriter, r *http.Request) {
-	existing, err := h.Store.GetChild()
+	existing, err := h.lookupChild(r)
 	if err != nil {
 		if h.IsNotFound(err) {
 			h.Error(w, http.StatusNotFound, "no child profile found")
@@ -58,7 +148,13 @@ func (h *Handler) UpdateChild(w http.ResponseWriter, r *http.Request) {
 		h.Error(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	child, err := h.Store.UpdateChild(existing.ID, req.Name, req.DateOfBirth, req.Gender, req.PhotoURL, req.Notes)
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	child, err := h.storeFor(r).UpdateChild(existing.ID, req.Name, req.DateOfBirth, req.Gender, req.PhotoURL, req.Notes)
 	if err != nil {
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"baby-care/internal/model"
+	"baby-care/internal/validate"
 )
 
 type diaperRequest struct {
@@ -12,12 +13,20 @@ type diaperRequest struct {
 	Notes      string `json:"notes"`
 }
 
+func (req diaperRequest) validate(v *validate.Validator) {
+	v.OneOf("diaper_type", req.DiaperType, validate.DiaperTypes...)
+	v.Timestamp("changed_at", req.ChangedAt)
+}
+
 func (h *Handler) ListDiaper(w http.ResponseWriter, r *http.Request) {
-	childID, ok := h.requireChild(w)
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	date, ok := h.queryDate(w, r, "date")
 	if !ok {
 		return
 	}
-	date := r.URL.Query().Get("date")
 	logs, err := h.Store.GetDiaperLogs(childID, date)
 	if err != nil {
 		h.Error(w, http.StatusInternalServerError, err.Error())
@@ -30,7 +39,7 @@ func (h *Handler) ListDiaper(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) CreateDiaper(w http.ResponseWriter, r *http.Request) {
-	childID, ok := h.requireChild(w)
+	childID, ok := h.resolveChild(w, r)
 	if !ok {
 		return
 	}
@@ -39,11 +48,14 @@ func (h *Handler) CreateDiaper(w http.ResponseWriter, r *http.Request) {
 		h.Error(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	if req.DiaperType == "" {
-		h.Error(w, http.StatusBadRequest, "diaper_type is required")
+	v := h.validator()
+	v.Required("diaper_type", req.DiaperType)
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
 		return
 	}
-	log, err := h.Store.CreateDiaper(childID, req.DiaperType, req.ChangedAt, req.Notes)
+	log, err := h.storeFor(r).CreateDiaper(childID, req.DiaperType, req.ChangedAt, req.Notes)
 	if err != nil {
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -52,13 +64,22 @@ func (h *Handler) CreateDiaper(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) UpdateDiaper(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("logId")
+	id, ok := h.resolveLog(w, r, "diaper")
+	if !ok {
+		return
+	}
 	var req diaperRequest
 	if err := h.Decode(r, &req); err != nil {
 		h.Error(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	log, err := h.Store.UpdateDiaper(id, req.DiaperType, req.ChangedAt, req.Notes)
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	log, err := h.storeFor(r).UpdateDiaper(id, req.DiaperType, req.ChangedAt, req.Notes)
 	if err != nil {
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -67,8 +88,11 @@ func (h *Handler) UpdateDiaper(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) DeleteDiaper(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("logId")
-	if err := h.Store.DeleteDiaper(id); err != nil {
+	id, ok := h.resolveLog(w, r, "diaper")
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeleteDiaper(id); err != nil {
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"baby-care/internal/model"
 	"baby-care/internal/store"
+	"baby-care/internal/validate"
 )
 
 type feedingRequest struct {
@@ -12,15 +14,28 @@ type feedingRequest struct {
 	StartTime  string `json:"start_time"`
 	EndTime    string `json:"end_time"`
 	QuantityML *int   `json:"quantity_ml"`
+	MilkType   string `json:"milk_type"`
 	Notes      string `json:"notes"`
 }
 
+func (req feedingRequest) validate(v *validate.Validator) {
+	v.OneOf("feed_type", req.FeedType, validate.FeedTypes...)
+	start := v.Timestamp("start_time", req.StartTime)
+	end := v.Timestamp("end_time", req.EndTime)
+	v.EndAfterStart("end_time", start, end)
+	v.Range("quantity_ml", req.QuantityML, validate.MinQuantityML, validate.MaxQuantityML)
+	v.OneOf("milk_type", req.MilkType, validate.MilkTypes...)
+}
+
 func (h *Handler) ListFeeding(w http.ResponseWriter, r *http.Request) {
-	childID, ok := h.requireChild(w)
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	date, ok := h.queryDate(w, r, "date")
 	if !ok {
 		return
 	}
-	date := r.URL.Query().Get("date")
 	logs, err := h.Store.GetFeedingLogs(childID, date)
 	if err != nil {
 		h.Error(w, http.StatusInternalServerError, err.Error())
@@ -33,7 +48,7 @@ func (h *Handler) ListFeeding(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) CreateFeeding(w http.ResponseWriter, r *http.Request) {
-	childID, ok := h.requireChild(w)
+	childID, ok := h.resolveChild(w, r)
 	if !ok {
 		return
 	}
@@ -42,12 +57,27 @@ func (h *Handler) CreateFeeding(w http.ResponseWriter, r *http.Request) {
 		h.Error(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	if req.FeedType == "" {
-		h.Error(w, http.StatusBadRequest, "feed_type is required")
+	v := h.validator()
+	v.Required("feed_type", req.FeedType)
+	v.Check(req.MilkType == "" || req.FeedType == "bottle", "milk_type", validate.CodeInvalidChoice, "only applies to bottle feeds")
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	st, ok := h.timedStore(w, r)
+	if !ok {
 		return
 	}
-	log, stopped, err := h.Store.CreateFeeding(childID, req.FeedType, req.StartTime, req.Notes, req.QuantityML)
+	log, stopped, err := st.CreateFeedingSpan(childID, req.FeedType, req.MilkType, req.StartTime, req.EndTime, req.Notes, req.QuantityML)
 	if err != nil {
+		if h.Conflict(w, err) {
+			return
+		}
+		if errors.Is(err, store.ErrEndBeforeStart) {
+			h.Invalid(w, validate.Field("end_time", validate.CodeBeforeStart, "must not be before the start time"))
+			return
+		}
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -59,7 +89,7 @@ func (h *Handler) CreateFeeding(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetActiveFeeding(w http.ResponseWriter, r *http.Request) {
-	childID, ok := h.requireChild(w)
+	childID, ok := h.resolveChild(w, r)
 	if !ok {
 		return
 	}
@@ -76,23 +106,106 @@ func (h *Handler) GetActiveFeeding(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) UpdateFeeding(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("logId")
+	id, ok := h.resolveLog(w, r, "feeding")
+	if !ok {
+		return
+	}
 	var req feedingRequest
 	if err := h.Decode(r, &req); err != nil {
 		h.Error(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	log, err := h.Store.UpdateFeeding(id, req.FeedType, req.StartTime, req.EndTime, req.Notes, req.QuantityML)
+	v := h.validator()
+	// An omitted feed_type keeps the stored one, which the store checks.
+	v.Check(req.MilkType == "" || req.FeedType == "" || req.FeedType == "bottle", "milk_type", validate.CodeInvalidChoice, "only applies to bottle feeds")
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	st, ok := h.timedStore(w, r)
+	if !ok {
+		return
+	}
+	log, err := st.UpdateFeeding(id, req.FeedType, req.MilkType, req.StartTime, req.EndTime, req.Notes, req.QuantityML)
 	if err != nil {
+		if h.Conflict(w, err) {
+			return
+		}
+		if errors.Is(err, store.ErrEndBeforeStart) {
+			h.Invalid(w, validate.Field("end_time", validate.CodeBeforeStart, "must not be before the start time"))
+			return
+		}
+		if errors.Is(err, store.ErrMilkTypeNotBottle) {
+			h.Invalid(w, validate.Field("milk_type", validate.CodeInvalidChoice, "only applies to bottle feeds"))
+			return
+		}
+		if errors.Is(err, store.ErrCutsSegments) {
+			field := "start_time"
+			if req.StartTime == "" {
+				field = "end_time"
+			}
+			h.Invalid(w, validate.Field(field, validate.CodeOutOfRange, "must not cut across a side switch"))
+			return
+		}
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 	h.JSON(w, http.StatusOK, log)
 }
 
+// SwitchFeedingSide serves POST /feeding/{logId}/switch, moving an ongoing
+// breast feed to the other side at "at" (now when the body is empty). A
+// paused feed resumes on the new side.
+func (h *Handler) SwitchFeedingSide(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "feeding")
+	if !ok {
+		return
+	}
+	req, ok := h.decodeTimerRequest(w, r)
+	if !ok {
+		return
+	}
+	log, err := h.storeFor(r).SwitchFeedingSide(id, req.At)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrFeedingNotActive):
+			h.Error(w, http.StatusConflict, err.Error())
+		case errors.Is(err, store.ErrEndBeforeStart):
+			h.Invalid(w, validate.Field("at", validate.CodeBeforeStart, "must not be before the current side started"))
+		default:
+			h.Error(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	h.JSON(w, http.StatusOK, log)
+}
+
+// GetNextBreastSide recommends the side to start the next breast feed on, or
+// answers null before the first one.
+func (h *Handler) GetNextBreastSide(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	next, err := h.Store.GetNextBreastSide(childID)
+	if err != nil {
+		if h.IsNotFound(err) {
+			h.JSON(w, http.StatusOK, nil)
+			return
+		}
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, next)
+}
+
 func (h *Handler) DeleteFeeding(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("logId")
-	if err := h.Store.DeleteFeeding(id); err != nil {
+	id, ok := h.resolveLog(w, r, "feeding")
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeleteFeeding(id); err != nil {
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
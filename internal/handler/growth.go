@@ -3,7 +3,8 @@ package handler
 import (
 	"net/http"
 
-	"baby-care/internal/model"
+	"baby-care/internal/catalog"
+	"baby-care/internal/validate"
 )
 
 type growthRequest struct {
@@ -14,24 +15,80 @@ type growthRequest struct {
 	Notes               string `json:"notes"`
 }
 
+func (req growthRequest) validate(v *validate.Validator) {
+	v.PastDate("measured_on", req.MeasuredOn)
+	v.Check(req.WeightGrams != nil || req.LengthMM != nil || req.HeadCircumferenceMM != nil,
+		"weight_grams", validate.CodeRequired, "at least one of weight_grams, length_mm, head_circumference_mm is required")
+	v.Range("weight_grams", req.WeightGrams, validate.MinWeightGrams, validate.MaxWeightGrams)
+	v.Range("length_mm", req.LengthMM, validate.MinLengthMM, validate.MaxLengthMM)
+	v.Range("head_circumference_mm", req.HeadCircumferenceMM, validate.MinHeadCircMM, validate.MaxHeadCircMM)
+}
+
+// ListGrowth returns the growth logs with their WHO z-scores and
+// percentiles.
 func (h *Handler) ListGrowth(w http.ResponseWriter, r *http.Request) {
-	childID, ok := h.requireChild(w)
+	childID, ok := h.resolveChild(w, r)
 	if !ok {
 		return
 	}
-	logs, err := h.Store.GetGrowthLogs(childID)
+	logs, err := h.Store.GetAssessedGrowth(childID)
 	if err != nil {
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	if logs == nil {
-		logs = []*model.GrowthLog{}
-	}
 	h.JSON(w, http.StatusOK, logs)
 }
 
+type growthCurve struct {
+	Indicator string               `json:"indicator"`
+	Name      string               `json:"name"`
+	X         string               `json:"x"`
+	Unit      string               `json:"unit"`
+	Sex       string               `json:"sex"`
+	Points    []catalog.CurvePoint `json:"points"`
+}
+
+// GetGrowthCurves returns the P3–P97 reference lines of a WHO growth standard
+// (?indicator=, weight_for_age by default) for charting. The sex defaults to
+// the child's gender and can be chosen with ?sex= when that has no standard.
+func (h *Handler) GetGrowthCurves(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	child, err := h.Store.GetChildByID(childID)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	indicator := r.URL.Query().Get("indicator")
+	if indicator == "" {
+		indicator = catalog.WeightForAge
+	}
+	sex := r.URL.Query().Get("sex")
+	if sex == "" {
+		sex = child.Gender
+	}
+	v := h.validator()
+	v.OneOf("indicator", indicator, catalog.GrowthIndicators...)
+	v.OneOf("sex", sex, "male", "female")
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	g, _ := catalog.GrowthStandardFor(indicator)
+	h.JSON(w, http.StatusOK, growthCurve{
+		Indicator: g.Indicator,
+		Name:      g.Name,
+		X:         g.X,
+		Unit:      g.Unit,
+		Sex:       sex,
+		Points:    g.Curve(sex),
+	})
+}
+
 func (h *Handler) CreateGrowth(w http.ResponseWriter, r *http.Request) {
-	childID, ok := h.requireChild(w)
+	childID, ok := h.resolveChild(w, r)
 	if !ok {
 		return
 	}
@@ -40,7 +97,13 @@ func (h *Handler) CreateGrowth(w http.ResponseWriter, r *http.Request) {
 		h.Error(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	log, err := h.Store.CreateGrowth(childID, req.MeasuredOn, req.WeightGrams, req.LengthMM, req.HeadCircumferenceMM, req.Notes)
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	log, err := h.storeFor(r).CreateGrowth(childID, req.MeasuredOn, req.WeightGrams, req.LengthMM, req.HeadCircumferenceMM, req.Notes)
 	if err != nil {
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -49,13 +112,23 @@ func (h *Handler) CreateGrowth(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) UpdateGrowth(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("logId")
+	id, ok := h.resolveLog(w, r, "growth")
+	if !ok {
+		return
+	}
 	var req growthRequest
 	if err := h.Decode(r, &req); err != nil {
 		h.Error(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	log, err := h.Store.UpdateGrowth(id, req.MeasuredOn, req.WeightGrams, req.LengthMM, req.HeadCircumferenceMM, req.Notes)
+	v := h.validator()
+	v.Required("measured_on", req.MeasuredOn)
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	log, err := h.storeFor(r).UpdateGrowth(id, req.MeasuredOn, req.WeightGrams, req.LengthMM, req.HeadCircumferenceMM, req.Notes)
 	if err != nil {
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
@@ -64,8 +137,11 @@ func (h *Handler) UpdateGrowth(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) DeleteGrowth(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("logId")
-	if err := h.Store.DeleteGrowth(id); err != nil {
+	id, ok := h.resolveLog(w, r, "growth")
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeleteGrowth(id); err != nil {
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -4,12 +4,27 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"time"
 
+	"baby-care/internal/auth"
+	"baby-care/internal/blob"
 	"baby-care/internal/store"
+	"baby-care/internal/validate"
 )
 
 type Handler struct {
 	Store *store.Store
+	// Blobs holds the content of uploaded attachments.
+	Blobs blob.Store
+}
+
+// storeFor returns the store acting on behalf of the request's user, so the
+// rows it creates record who logged them.
+func (h *Handler) storeFor(r *http.Request) *store.Store {
+	if u := auth.UserFrom(r.Context()); u != nil {
+		return h.Store.WithActor(u.ID)
+	}
+	return h.Store
 }
 
 func (h *Handler) JSON(w http.ResponseWriter, status int, v any) {
@@ -26,6 +41,67 @@ func (h *Handler) Decode(r *http.Request, v any) error {
 	return json.NewDecoder(r.Body).Decode(v)
 }
 
+// validator returns a request validator judging "future" by the household clock.
+func (h *Handler) validator() *validate.Validator {
+	return validate.New(time.Now().In(h.Store.Location()))
+}
+
+type validationResponse struct {
+	Error  string          `json:"error"`
+	Fields validate.Errors `json:"fields"`
+}
+
+// Invalid writes a 422 response listing the field errors in err, which must
+// wrap validate.Errors.
+func (h *Handler) Invalid(w http.ResponseWriter, err error) {
+	var fields validate.Errors
+	errors.As(err, &fields)
+	h.JSON(w, http.StatusUnprocessableEntity, validationResponse{Error: err.Error(), Fields: fields})
+}
+
+// queryDate returns the optional YYYY-MM-DD query parameter name. It writes a
+// 422 and returns false when the parameter is malformed.
+func (h *Handler) queryDate(w http.ResponseWriter, r *http.Request, name string) (string, bool) {
+	date := r.URL.Query().Get(name)
+	v := h.validator()
+	v.Date(name, date)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return "", false
+	}
+	return date, true
+}
+
+// timedStore returns the request's store view resolving overlaps as asked by
+// the optional ?resolve= parameter. It writes a 422 and returns false when the
+// parameter is unknown.
+func (h *Handler) timedStore(w http.ResponseWriter, r *http.Request) (*store.Store, bool) {
+	mode := r.URL.Query().Get("resolve")
+	v := h.validator()
+	v.OneOf("resolve", mode, store.ResolveTruncate, store.ResolveMerge)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return nil, false
+	}
+	return h.storeFor(r).WithResolve(mode), true
+}
+
+type conflictResponse struct {
+	Error     string   `json:"error"`
+	Conflicts []string `json:"conflicts"`
+}
+
+// Conflict writes a 409 listing the overlapping log IDs and returns true
+// when err is a *store.ConflictError.
+func (h *Handler) Conflict(w http.ResponseWriter, err error) bool {
+	var conflict *store.ConflictError
+	if !errors.As(err, &conflict) {
+		return false
+	}
+	h.JSON(w, http.StatusConflict, conflictResponse{Error: conflict.Error(), Conflicts: conflict.IDs})
+	return true
+}
+
 func (h *Handler) IsNotFound(err error) bool {
 	return errors.Is(err, store.ErrNotFound)
 }
@@ -3,21 +3,45 @@ package handler_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"net/url"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"testing/fstest"
+	"time"
 
+	"baby-care/internal/auth"
+	"baby-care/internal/catalog"
 	"baby-care/internal/model"
 	"baby-care/internal/server"
 	"baby-care/internal/store"
+	"baby-care/internal/validate"
 )
 
 // ── helpers ──────────────────────────────────────────────────────────────────
 
-func newTestServer(t *testing.T) (*httptest.Server, *store.Store) {
+var testPasswordHash = sync.OnceValue(func() string {
+	hash, err := auth.HashPassword(testPassword)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+const testPassword = "correct horse battery"
+
+// newAnonTestServer starts a server whose client carries no credentials.
+func newAnonTestServer(t *testing.T) (*httptest.Server, *store.Store) {
 	t.Helper()
 	st, err := store.Open(filepath.Join(t.TempDir(), "test.db"))
 	if err != nil {
@@ -28,11 +52,42 @@ func newTestServer(t *testing.T) (*httptest.Server, *store.Store) {
 	staticFS := fstest.MapFS{
 		"index.html": {Data: []byte("<html></html>")},
 	}
-	srv := httptest.NewServer(server.New(st, staticFS))
+	srv := httptest.NewServer(server.New(st, staticFS, server.Options{}))
 	t.Cleanup(srv.Close)
 	return srv, st
 }
 
+// newTestServer starts a server whose client is logged in as a parent.
+func newTestServer(t *testing.T) (*httptest.Server, *store.Store) {
+	t.Helper()
+	srv, st := newAnonTestServer(t)
+	mustLogin(t, srv, st, "parent", auth.RoleParent)
+	return srv, st
+}
+
+// mustLogin creates a user with the given role and installs a session cookie
+// for them on the server's client, skipping the bcrypt round-trip of the
+// login endpoint.
+func mustLogin(t *testing.T, srv *httptest.Server, st *store.Store, username, role string) *model.User {
+	t.Helper()
+	user, err := st.CreateUser(username, testPasswordHash(), role)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	token, hash, err := auth.NewToken()
+	if err != nil {
+		t.Fatalf("new token: %v", err)
+	}
+	if err := st.CreateSession(hash, user.ID, time.Hour); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	jar, _ := cookiejar.New(nil)
+	u, _ := url.Parse(srv.URL)
+	jar.SetCookies(u, []*http.Cookie{{Name: auth.SessionCookie, Value: token, Path: "/"}})
+	srv.Client().Jar = jar
+	return user
+}
+
 func do(t *testing.T, srv *httptest.Server, method, path string, body any) *http.Response {
 	t.Helper()
 	var buf *bytes.Buffer
@@ -49,7 +104,7 @@ func do(t *testing.T, srv *httptest.Server, method, path string, body any) *http
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := srv.Client().Do(req)
 	if err != nil {
 		t.Fatalf("do request: %v", err)
 	}
@@ -102,8 +157,15 @@ func TestGetChild_NotFound(t *testing.T) {
 func TestCreateChild_MissingFields(t *testing.T) {
 	srv, _ := newTestServer(t)
 	resp := do(t, srv, "POST", "/api/v1/child", map[string]string{"name": "Only Name"})
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("status = %d, want 400", resp.StatusCode)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", resp.StatusCode)
+	}
+	var body struct {
+		Fields []validate.FieldError `json:"fields"`
+	}
+	decodeJSON(t, resp, &body)
+	if len(body.Fields) != 2 || body.Fields[0].Field != "date_of_birth" || body.Fields[1].Field != "gender" {
+		t.Errorf("fields = %+v, want date_of_birth and gender", body.Fields)
 	}
 }
 
@@ -111,7 +173,7 @@ func TestCreateChild_InvalidJSON(t *testing.T) {
 	srv, _ := newTestServer(t)
 	req, _ := http.NewRequest("POST", srv.URL+"/api/v1/child", strings.NewReader("not json"))
 	req.Header.Set("Content-Type", "application/json")
-	resp, _ := http.DefaultClient.Do(req)
+	resp, _ := srv.Client().Do(req)
 	if resp.StatusCode != http.StatusBadRequest {
 		t.Errorf("status = %d, want 400", resp.StatusCode)
 	}
@@ -166,6 +228,105 @@ func TestUpdateChild_NoChild(t *testing.T) {
 	}
 }
 
+func TestChildren_ListAndCreate(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	resp := do(t, srv, "GET", "/api/v1/children", nil)
+	var empty []any
+	decodeJSON(t, resp, &empty)
+	if len(empty) != 0 {
+		t.Fatalf("expected no children, got %d", len(empty))
+	}
+
+	for _, name := range []string{"Twin A", "Twin B"} {
+		resp := do(t, srv, "POST", "/api/v1/children", map[string]string{
+			"name": name, "date_of_birth": "2024-01-01", "gender": "female",
+		})
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("create %s: status %d", name, resp.StatusCode)
+		}
+		resp.Body.Close()
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/children", nil)
+	var children []model.Child
+	decodeJSON(t, resp, &children)
+	if len(children) != 2 {
+		t.Fatalf("got %d children, want 2", len(children))
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/children/"+children[1].ID, nil)
+	var got model.Child
+	decodeJSON(t, resp, &got)
+	if got.Name != "Twin B" {
+		t.Errorf("Name = %q, want Twin B", got.Name)
+	}
+}
+
+func TestChildScopedRoutes_UnknownChild(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	for _, tc := range []struct{ method, path string }{
+		{"GET", "/api/v1/children/missing"},
+		{"GET", "/api/v1/children/missing/sleep"},
+		{"POST", "/api/v1/children/missing/diaper"},
+		{"GET", "/api/v1/children/missing/summary"},
+		{"GET", "/api/v1/children/missing/analytics"},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			resp := do(t, srv, tc.method, tc.path, map[string]string{"diaper_type": "wet"})
+			if resp.StatusCode != http.StatusNotFound {
+				t.Errorf("status = %d, want 404", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestChildScopedRoutes_Isolation(t *testing.T) {
+	srv, _ := newTestServer(t)
+	first := mustCreateChildViaAPI(t, srv)
+	resp := do(t, srv, "POST", "/api/v1/children", map[string]string{
+		"name": "Second", "date_of_birth": "2024-01-01", "gender": "male",
+	})
+	var second model.Child
+	decodeJSON(t, resp, &second)
+
+	resp = do(t, srv, "POST", "/api/v1/children/"+second.ID+"/diaper", map[string]string{
+		"diaper_type": "wet", "changed_at": "2024-01-15T06:00:00+07:00",
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create diaper status = %d, want 201", resp.StatusCode)
+	}
+	var created map[string]any
+	decodeJSON(t, resp, &created)
+	id := created["id"].(string)
+
+	// The legacy alias resolves to the first child, which has no diapers.
+	resp = do(t, srv, "GET", "/api/v1/diaper", nil)
+	var logs []any
+	decodeJSON(t, resp, &logs)
+	if len(logs) != 0 {
+		t.Errorf("legacy route returned %d logs, want 0", len(logs))
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/children/"+second.ID+"/diaper", nil)
+	decodeJSON(t, resp, &logs)
+	if len(logs) != 1 {
+		t.Errorf("scoped route returned %d logs, want 1", len(logs))
+	}
+
+	// A log cannot be modified through another child's routes.
+	resp = do(t, srv, "DELETE", "/api/v1/children/"+first.ID+"/diaper/"+id, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("cross-child delete status = %d, want 404", resp.StatusCode)
+	}
+	resp = do(t, srv, "DELETE", "/api/v1/children/"+second.ID+"/diaper/"+id, nil)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("delete status = %d, want 204", resp.StatusCode)
+	}
+}
+
 // ── sleep ─────────────────────────────────────────────────────────────────────
 
 func TestSleep_RequiresChild(t *testing.T) {
@@ -304,6 +465,44 @@ func TestCreateSleep_AutoStopsFeeding(t *testing.T) {
 	}
 }
 
+func TestPauseResumeSleep(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+	resp := do(t, srv, "POST", "/api/v1/sleep", map[string]string{"start_time": "2024-01-15T13:00:00+07:00"})
+	var sl model.SleepLog
+	decodeJSON(t, resp, &sl)
+
+	resp = do(t, srv, "POST", "/api/v1/sleep/"+sl.ID+"/pause", map[string]string{"at": "2024-01-15T13:20:00+07:00"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("pause status = %d, want 200", resp.StatusCode)
+	}
+	resp.Body.Close()
+	resp = do(t, srv, "POST", "/api/v1/sleep/"+sl.ID+"/pause", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("second pause status = %d, want 409", resp.StatusCode)
+	}
+
+	var summary store.DaySummary
+	decodeJSON(t, do(t, srv, "GET", "/api/v1/summary?date=2024-01-15", nil), &summary)
+	if summary.ActiveSleep == nil || summary.ActiveSleep.PausedAt == nil {
+		t.Errorf("summary active sleep = %+v, want it paused", summary.ActiveSleep)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/sleep/"+sl.ID+"/resume", map[string]string{"at": "2024-01-15T13:10:00+07:00"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("resume before the pause status = %d, want 422", resp.StatusCode)
+	}
+	resp = do(t, srv, "POST", "/api/v1/sleep/"+sl.ID+"/resume", map[string]string{"at": "2024-01-15T13:30:00+07:00"})
+	resp.Body.Close()
+	resp = do(t, srv, "PUT", "/api/v1/sleep/"+sl.ID, map[string]string{"end_time": "2024-01-15T14:00:00+07:00"})
+	decodeJSON(t, resp, &sl)
+	if sl.DurationMinutes == nil || *sl.DurationMinutes != 50 {
+		t.Errorf("duration = %v, want 50 minutes excluding the pause", sl.DurationMinutes)
+	}
+}
+
 // ── feeding ───────────────────────────────────────────────────────────────────
 
 func TestCreateFeeding_MissingFeedType(t *testing.T) {
@@ -313,8 +512,8 @@ func TestCreateFeeding_MissingFeedType(t *testing.T) {
 	resp := do(t, srv, "POST", "/api/v1/feeding", map[string]string{
 		"start_time": "2024-01-15T10:00:00+07:00",
 	})
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("status = %d, want 400", resp.StatusCode)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", resp.StatusCode)
 	}
 }
 
@@ -419,6 +618,41 @@ func TestCreateFeeding_BreastAutoStopsSleep(t *testing.T) {
 	}
 }
 
+func TestSwitchFeedingSide(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "POST", "/api/v1/feeding", map[string]string{"feed_type": "breast_left", "start_time": "2024-01-15T10:00:00+07:00"})
+	var feed model.FeedingLog
+	decodeJSON(t, resp, &feed)
+
+	resp = do(t, srv, "POST", "/api/v1/feeding/"+feed.ID+"/switch", map[string]string{"at": "2024-01-15T10:10:00+07:00"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("switch status = %d, want 200", resp.StatusCode)
+	}
+	decodeJSON(t, resp, &feed)
+	if len(feed.Segments) != 2 || feed.Segments[1].Side != "right" {
+		t.Errorf("segments = %+v, want left then right", feed.Segments)
+	}
+
+	resp = do(t, srv, "PUT", "/api/v1/feeding/"+feed.ID, map[string]string{"end_time": "2024-01-15T10:25:00+07:00"})
+	decodeJSON(t, resp, &feed)
+	if feed.LeftMinutes == nil || *feed.LeftMinutes != 10 || *feed.RightMinutes != 15 {
+		t.Errorf("side minutes = %v/%v, want 10/15", feed.LeftMinutes, feed.RightMinutes)
+	}
+	resp = do(t, srv, "POST", "/api/v1/feeding/"+feed.ID+"/switch", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("switch of a finished feed status = %d, want 409", resp.StatusCode)
+	}
+
+	var next store.NextSide
+	decodeJSON(t, do(t, srv, "GET", "/api/v1/feeding/next-side", nil), &next)
+	if next.Side != "right" || next.LastFeedingID != feed.ID {
+		t.Errorf("next side = %+v, want right", next)
+	}
+}
+
 // ── diaper ────────────────────────────────────────────────────────────────────
 
 func TestCreateDiaper_MissingType(t *testing.T) {
@@ -428,8 +662,8 @@ func TestCreateDiaper_MissingType(t *testing.T) {
 	resp := do(t, srv, "POST", "/api/v1/diaper", map[string]string{
 		"changed_at": "2024-01-15T06:00:00+07:00",
 	})
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Errorf("status = %d, want 400", resp.StatusCode)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", resp.StatusCode)
 	}
 }
 
@@ -542,6 +776,50 @@ func TestDeleteGrowth(t *testing.T) {
 	}
 }
 
+func TestGrowthPercentiles(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	do(t, srv, "POST", "/api/v1/growth", map[string]any{
+		"measured_on": "2024-07-01", "weight_grams": 7300, "length_mm": 658,
+	})
+	resp := do(t, srv, "GET", "/api/v1/growth", nil)
+	var logs []store.AssessedGrowth
+	decodeJSON(t, resp, &logs)
+	if len(logs) != 1 {
+		t.Fatalf("got %d logs, want 1", len(logs))
+	}
+	if logs[0].AgeDays != 182 || logs[0].WeightForAge == nil || logs[0].LengthForAge == nil || logs[0].WeightForLength == nil {
+		t.Errorf("assessed growth = %+v, want age 182 days with three scores", logs[0])
+	}
+	if logs[0].HeadCircumferenceForAge != nil {
+		t.Error("head circumference scored without a measurement")
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/growth/curves?indicator=length_for_age", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("curves status = %d, want 200", resp.StatusCode)
+	}
+	var curve struct {
+		Indicator string               `json:"indicator"`
+		Sex       string               `json:"sex"`
+		Unit      string               `json:"unit"`
+		Points    []catalog.CurvePoint `json:"points"`
+	}
+	decodeJSON(t, resp, &curve)
+	if curve.Indicator != "length_for_age" || curve.Sex != "female" || curve.Unit != "cm" || len(curve.Points) != 25 {
+		t.Errorf("curve = %s %s %s with %d points", curve.Indicator, curve.Sex, curve.Unit, len(curve.Points))
+	}
+	if p := curve.Points[0]; !(p.P3 < p.P15 && p.P15 < p.P50 && p.P50 < p.P85 && p.P85 < p.P97) {
+		t.Errorf("reference lines out of order: %+v", p)
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/growth/curves?indicator=bmi_for_age", nil)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("unknown indicator status = %d, want 422", resp.StatusCode)
+	}
+}
+
 // ── summary ───────────────────────────────────────────────────────────────────
 
 func TestGetSummary(t *testing.T) {
@@ -590,12 +868,1028 @@ func TestGetSummary_DefaultsToToday(t *testing.T) {
 	}
 }
 
-// ── CORS ──────────────────────────────────────────────────────────────────────
+func TestGetPredictions(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "GET", "/api/v1/predictions", nil)
+	var p store.Predictions
+	decodeJSON(t, resp, &p)
+	if p.NextFeed != nil {
+		t.Errorf("next_feed = %+v before any feed", p.NextFeed)
+	}
+
+	now := time.Now().Truncate(time.Minute)
+	for _, ago := range []time.Duration{7 * time.Hour, 4 * time.Hour, time.Hour} {
+		do(t, srv, "POST", "/api/v1/feeding", map[string]any{
+			"feed_type": "bottle", "start_time": now.Add(-ago).Format(time.RFC3339), "quantity_ml": 90,
+		})
+	}
+	resp = do(t, srv, "GET", "/api/v1/predictions", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("predictions status = %d, want 200", resp.StatusCode)
+	}
+	decodeJSON(t, resp, &p)
+	if p.NextFeed == nil || p.NextFeed.IntervalMinutes != 180 {
+		t.Fatalf("next_feed = %+v, want a 180-minute interval", p.NextFeed)
+	}
+	if at, _ := time.Parse(time.RFC3339, p.NextFeed.At); !at.Equal(now.Add(2 * time.Hour)) {
+		t.Errorf("next feed at %s, want two hours from now", p.NextFeed.At)
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/summary", nil)
+	var summary store.DaySummary
+	decodeJSON(t, resp, &summary)
+	if summary.Predictions == nil || summary.Predictions.NextFeed == nil {
+		t.Errorf("summary predictions = %+v, want the next feed", summary.Predictions)
+	}
+}
 
-func TestCORSHeaders(t *testing.T) {
+func TestGetSleepChecks(t *testing.T) {
 	srv, _ := newTestServer(t)
-	resp := do(t, srv, "GET", "/health", nil)
-	if resp.Header.Get("Access-Control-Allow-Origin") != "*" {
-		t.Error("expected CORS header on response")
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "POST", "/api/v1/sleep", map[string]string{
+		"start_time": "2024-01-15T08:00:00+07:00",
+	})
+	var sl map[string]any
+	decodeJSON(t, resp, &sl)
+	do(t, srv, "PUT", "/api/v1/sleep/"+sl["id"].(string), map[string]string{
+		"end_time": "2024-01-15T09:00:00+07:00",
+	})
+
+	resp = do(t, srv, "GET", "/api/v1/analytics/sleep?from=2024-01-15&to=2024-01-16", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("sleep checks status = %d, want 200", resp.StatusCode)
+	}
+	var checks []store.SleepCheck
+	decodeJSON(t, resp, &checks)
+	if len(checks) != 2 || checks[0].AgeWeeks != 2 || checks[0].NapCount != 1 || checks[0].Recommended == nil {
+		t.Fatalf("checks = %+v, want two days starting two weeks old with one nap", checks)
+	}
+	if d := checks[0].SleepDeviationMinutes; d == nil || *d != 60-840 {
+		t.Errorf("sleep_deviation_minutes = %v, want %d", d, 60-840)
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/analytics/sleep?from=yesterday", nil)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("bad date status = %d, want 422", resp.StatusCode)
+	}
+}
+
+// ── settings ──────────────────────────────────────────────────────────────────
+
+func TestSettings_UpdateTimezone(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	resp := do(t, srv, "PUT", "/api/v1/settings", map[string]string{"timezone": "Europe/Paris"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var settings map[string]any
+	decodeJSON(t, resp, &settings)
+	if settings["timezone"] != "Europe/Paris" || settings["effective_timezone"] != "Europe/Paris" {
+		t.Errorf("settings = %v, want Europe/Paris", settings)
+	}
+
+	resp = do(t, srv, "PUT", "/api/v1/settings", map[string]string{"timezone": "Not/AZone"})
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("invalid timezone status = %d, want 422", resp.StatusCode)
+	}
+}
+
+func TestSettings_UpdateNightWindow(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	resp := do(t, srv, "PUT", "/api/v1/settings", map[string]string{"night_start": "20:00", "night_end": "06:30"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var settings store.Settings
+	decodeJSON(t, resp, &settings)
+	if settings.NightStart != "20:00" || settings.NightEnd != "06:30" {
+		t.Errorf("night = %s–%s, want 20:00–06:30", settings.NightStart, settings.NightEnd)
+	}
+
+	for _, body := range []map[string]string{{"night_start": "8pm"}, {"night_end": "20:00"}} {
+		resp = do(t, srv, "PUT", "/api/v1/settings", body)
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("%v status = %d, want 422", body, resp.StatusCode)
+		}
+	}
+}
+
+// ── auth ──────────────────────────────────────────────────────────────────────
+
+func TestAPI_RequiresSession(t *testing.T) {
+	srv, _ := newAnonTestServer(t)
+	for _, tc := range []struct{ method, path string }{
+		{"GET", "/api/v1/child"},
+		{"GET", "/api/v1/children"},
+		{"POST", "/api/v1/sleep"},
+		{"GET", "/api/v1/summary"},
+		{"GET", "/api/v1/auth/me"},
+	} {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			resp := do(t, srv, tc.method, tc.path, nil)
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("status = %d, want 401", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestPublicRoutes_NoSession(t *testing.T) {
+	srv, _ := newAnonTestServer(t)
+	for _, path := range []string{"/health", "/", "/index.html", "/dashboard"} {
+		resp := do(t, srv, "GET", path, nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s status = %d, want 200", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestLoginLogout(t *testing.T) {
+	srv, st := newAnonTestServer(t)
+	if _, err := st.CreateUser("Mom", testPasswordHash(), auth.RoleParent); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	jar, _ := cookiejar.New(nil)
+	srv.Client().Jar = jar
+
+	resp := do(t, srv, "POST", "/api/v1/auth/login", map[string]string{"username": "mom", "password": "wrong password"})
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("bad password status = %d, want 401", resp.StatusCode)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/auth/login", map[string]string{"username": "mom", "password": testPassword})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("login status = %d, want 200", resp.StatusCode)
+	}
+	var cookie *http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == auth.SessionCookie {
+			cookie = c
+		}
+	}
+	if cookie == nil || !cookie.HttpOnly {
+		t.Fatalf("expected HTTP-only session cookie, got %+v", resp.Cookies())
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/auth/me", nil)
+	var me model.User
+	decodeJSON(t, resp, &me)
+	if me.Username != "mom" {
+		t.Errorf("me = %q, want mom", me.Username)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/auth/logout", nil)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("logout status = %d, want 204", resp.StatusCode)
+	}
+
+	// The old token must be dead server-side, not just cleared client-side.
+	req, _ := http.NewRequest("GET", srv.URL+"/api/v1/auth/me", nil)
+	req.AddCookie(cookie)
+	resp, _ = http.DefaultClient.Do(req)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("me after logout status = %d, want 401", resp.StatusCode)
+	}
+}
+
+// ── roles & invites ──────────────────────────────────────────────────────────
+
+func TestRoles_Nanny(t *testing.T) {
+	srv, st := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+	nanny := mustLogin(t, srv, st, "lan", auth.RoleNanny)
+
+	resp := do(t, srv, "POST", "/api/v1/sleep", map[string]string{"start_time": "2024-01-15T13:00:00+07:00"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("nanny create sleep status = %d, want 201", resp.StatusCode)
+	}
+	var created model.SleepLog
+	decodeJSON(t, resp, &created)
+	if created.CreatedBy != nanny.ID {
+		t.Errorf("created_by = %q, want %q", created.CreatedBy, nanny.ID)
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		body         any
+		want         int
+	}{
+		{"GET", "/api/v1/growth", nil, http.StatusOK},
+		{"POST", "/api/v1/growth", map[string]any{"measured_on": "2024-01-15", "weight_grams": 4000}, http.StatusForbidden},
+		{"PUT", "/api/v1/child", map[string]string{"name": "X", "date_of_birth": "2024-01-01", "gender": "male"}, http.StatusForbidden},
+		{"POST", "/api/v1/invites", map[string]string{"role": "parent"}, http.StatusForbidden},
+		{"PUT", "/api/v1/settings", map[string]string{"timezone": "UTC"}, http.StatusForbidden},
+	} {
+		resp := do(t, srv, tc.method, tc.path, tc.body)
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s %s status = %d, want %d", tc.method, tc.path, resp.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestRoles_Grandparent(t *testing.T) {
+	srv, st := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+	mustLogin(t, srv, st, "ba", auth.RoleGrandparent)
+
+	for _, tc := range []struct {
+		method, path string
+		body         any
+		want         int
+	}{
+		{"GET", "/api/v1/summary?date=2024-01-15", nil, http.StatusOK},
+		{"GET", "/api/v1/analytics", nil, http.StatusOK},
+		{"GET", "/api/v1/analytics/sleep", nil, http.StatusOK},
+		{"GET", "/api/v1/predictions", nil, http.StatusOK},
+		{"GET", "/api/v1/sleep", nil, http.StatusForbidden},
+		{"POST", "/api/v1/diaper", map[string]string{"diaper_type": "wet"}, http.StatusForbidden},
+	} {
+		resp := do(t, srv, tc.method, tc.path, tc.body)
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s %s status = %d, want %d", tc.method, tc.path, resp.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestInvites_AcceptFlow(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	resp := do(t, srv, "POST", "/api/v1/invites", map[string]string{"role": "owner"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("invalid role status = %d, want 422", resp.StatusCode)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/invites", map[string]string{"role": "nanny"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create invite status = %d, want 201", resp.StatusCode)
+	}
+	var invite struct {
+		ID, Role, Token, URL string
+	}
+	decodeJSON(t, resp, &invite)
+	if invite.Token == "" || !strings.Contains(invite.URL, invite.Token) {
+		t.Fatalf("invite = %+v, want token and link", invite)
+	}
+
+	// The invitee is not logged in.
+	srv.Client().Jar, _ = cookiejar.New(nil)
+	accept := map[string]string{"token": invite.Token, "username": "Lan", "password": testPassword}
+	resp = do(t, srv, "POST", "/api/v1/auth/accept-invite", accept)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("accept invite status = %d, want 201", resp.StatusCode)
+	}
+	resp.Body.Close()
+
+	resp = do(t, srv, "GET", "/api/v1/auth/me", nil)
+	var me model.User
+	decodeJSON(t, resp, &me)
+	if me.Username != "lan" || me.Role != auth.RoleNanny {
+		t.Errorf("me = %+v, want lan/nanny", me)
+	}
+
+	accept["username"] = "someone-else"
+	resp = do(t, srv, "POST", "/api/v1/auth/accept-invite", accept)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusGone {
+		t.Errorf("reused invite status = %d, want 410", resp.StatusCode)
+	}
+}
+
+// ── API tokens ───────────────────────────────────────────────────────────────
+
+func TestAPITokens_BearerAuth(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "POST", "/api/v1/tokens", map[string]any{"name": "shortcut", "scopes": []string{"diaper:fly"}})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("unknown scope status = %d, want 422", resp.StatusCode)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/tokens", map[string]any{"name": "shortcut", "scopes": []string{"diaper:write"}})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create token status = %d, want 201", resp.StatusCode)
+	}
+	var created struct {
+		ID, Token string
+	}
+	decodeJSON(t, resp, &created)
+
+	bearer := func(method, path, body string) int {
+		req, _ := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+created.Token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("do request: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if got := bearer("POST", "/api/v1/diaper", `{"diaper_type":"wet"}`); got != http.StatusCreated {
+		t.Errorf("token diaper POST status = %d, want 201", got)
+	}
+	if got := bearer("GET", "/api/v1/summary", ""); got != http.StatusForbidden {
+		t.Errorf("out-of-scope GET status = %d, want 403", got)
+	}
+	if got := bearer("POST", "/api/v1/tokens", `{"name":"x","scopes":["diaper:write"]}`); got != http.StatusForbidden {
+		t.Errorf("token minting token status = %d, want 403", got)
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/tokens", nil)
+	var tokens []model.APIToken
+	decodeJSON(t, resp, &tokens)
+	if len(tokens) != 1 || tokens[0].LastUsedAt == nil {
+		t.Fatalf("tokens = %+v, want one used token", tokens)
+	}
+
+	resp = do(t, srv, "DELETE", "/api/v1/tokens/"+created.ID, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete token status = %d, want 204", resp.StatusCode)
+	}
+	if got := bearer("POST", "/api/v1/diaper", `{"diaper_type":"wet"}`); got != http.StatusUnauthorized {
+		t.Errorf("revoked token status = %d, want 401", got)
+	}
+}
+
+func TestAPITokens_ScopesLimitedByRole(t *testing.T) {
+	srv, st := newTestServer(t)
+	mustLogin(t, srv, st, "lan", auth.RoleNanny)
+
+	resp := do(t, srv, "POST", "/api/v1/tokens", map[string]any{"name": "x", "scopes": []string{"growth:write"}})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("nanny growth:write token status = %d, want 403", resp.StatusCode)
+	}
+}
+
+// ── validation ───────────────────────────────────────────────────────────────
+
+func TestValidation_FieldErrors(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	for _, tc := range []struct {
+		name, method, path string
+		body               any
+		field, code        string
+	}{
+		{"feed type enum", "POST", "/api/v1/feeding", map[string]string{"feed_type": "spoon"}, "feed_type", validate.CodeInvalidChoice},
+		{"diaper type enum", "POST", "/api/v1/diaper", map[string]string{"diaper_type": "soggy"}, "diaper_type", validate.CodeInvalidChoice},
+		{"gender enum", "POST", "/api/v1/children", map[string]string{"name": "B", "date_of_birth": "2024-01-01", "gender": "x"}, "gender", validate.CodeInvalidChoice},
+		{"bad timestamp", "POST", "/api/v1/sleep", map[string]string{"start_time": "yesterday"}, "start_time", validate.CodeInvalidFormat},
+		{"future timestamp", "POST", "/api/v1/diaper", map[string]string{"diaper_type": "wet", "changed_at": future}, "changed_at", validate.CodeInFuture},
+		{"bottle too large", "POST", "/api/v1/feeding", map[string]any{"feed_type": "bottle", "quantity_ml": 5000}, "quantity_ml", validate.CodeOutOfRange},
+		{"implausible weight", "POST", "/api/v1/growth", map[string]any{"measured_on": "2024-02-01", "weight_grams": 50}, "weight_grams", validate.CodeOutOfRange},
+		{"no measurement", "POST", "/api/v1/growth", map[string]any{"measured_on": "2024-02-01"}, "weight_grams", validate.CodeRequired},
+		{"bad date query", "GET", "/api/v1/diaper?date=15-01-2024", nil, "date", validate.CodeInvalidFormat},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := do(t, srv, tc.method, tc.path, tc.body)
+			if resp.StatusCode != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want 422", resp.StatusCode)
+			}
+			var body struct {
+				Fields []validate.FieldError `json:"fields"`
+			}
+			decodeJSON(t, resp, &body)
+			if len(body.Fields) != 1 || body.Fields[0].Field != tc.field || body.Fields[0].Code != tc.code {
+				t.Errorf("fields = %+v, want %s/%s", body.Fields, tc.field, tc.code)
+			}
+		})
+	}
+}
+
+func TestValidation_EndBeforeStart(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "POST", "/api/v1/sleep", map[string]string{"start_time": "2024-01-15T13:00:00+07:00"})
+	var created model.SleepLog
+	decodeJSON(t, resp, &created)
+
+	// Only end_time is sent, so the check needs the stored start time.
+	resp = do(t, srv, "PUT", "/api/v1/sleep/"+created.ID, map[string]string{"end_time": "2024-01-15T12:00:00+07:00"})
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422", resp.StatusCode)
+	}
+	var body struct {
+		Fields []validate.FieldError `json:"fields"`
+	}
+	decodeJSON(t, resp, &body)
+	if len(body.Fields) != 1 || body.Fields[0].Code != validate.CodeBeforeStart {
+		t.Errorf("fields = %+v, want end_time before_start", body.Fields)
+	}
+}
+
+// ── pumping ──────────────────────────────────────────────────────────────────
+
+func TestPumping_CRUD(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "POST", "/api/v1/pumping", map[string]string{"start_time": "2024-01-15T07:00:00+07:00"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d, want 201", resp.StatusCode)
+	}
+	var created model.PumpingLog
+	decodeJSON(t, resp, &created)
+
+	resp = do(t, srv, "GET", "/api/v1/pumping/active", nil)
+	var active *model.PumpingLog
+	decodeJSON(t, resp, &active)
+	if active == nil || active.ID != created.ID {
+		t.Fatalf("active = %+v, want the new session", active)
+	}
+
+	resp = do(t, srv, "PUT", "/api/v1/pumping/"+created.ID, map[string]any{
+		"end_time": "2024-01-15T07:20:00+07:00", "left_ml": 90, "right_ml": 70,
+	})
+	var stopped model.PumpingLog
+	decodeJSON(t, resp, &stopped)
+	if stopped.DurationMinutes == nil || *stopped.DurationMinutes != 20 {
+		t.Errorf("DurationMinutes = %v, want 20", stopped.DurationMinutes)
+	}
+
+	resp = do(t, srv, "PUT", "/api/v1/pumping/"+created.ID, map[string]any{"left_ml": 900})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("implausible volume status = %d, want 422", resp.StatusCode)
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/summary?date=2024-01-15", nil)
+	var summary store.DaySummary
+	decodeJSON(t, resp, &summary)
+	if summary.PumpingCount != 1 || summary.PumpedMLTotal != 160 {
+		t.Errorf("summary pumping = %d, %d ml; want 1, 160", summary.PumpingCount, summary.PumpedMLTotal)
+	}
+
+	resp = do(t, srv, "DELETE", "/api/v1/pumping/"+created.ID, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("delete status = %d, want 204", resp.StatusCode)
+	}
+}
+
+// ── milk stash ───────────────────────────────────────────────────────────────
+
+func TestMilkStash_BottleConsumes(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "POST", "/api/v1/milk-stash", map[string]any{"location": "fridge", "volume_ml": 150})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create bag status = %d, want 201", resp.StatusCode)
+	}
+	var bag model.MilkBag
+	decodeJSON(t, resp, &bag)
+
+	resp = do(t, srv, "POST", "/api/v1/feeding", map[string]any{
+		"feed_type": "breast_left", "milk_type": "breast_milk",
+	})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("milk_type on breast feed status = %d, want 422", resp.StatusCode)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/feeding", map[string]any{
+		"feed_type": "bottle", "milk_type": "breast_milk", "quantity_ml": 90,
+	})
+	var feed struct {
+		model.FeedingLog
+		MilkUsed []model.MilkUse `json:"milk_used"`
+	}
+	decodeJSON(t, resp, &feed)
+	if feed.MilkType != "breast_milk" || len(feed.MilkUsed) != 1 || feed.MilkUsed[0].BagID != bag.ID || feed.MilkUsed[0].ML != 90 {
+		t.Errorf("feed = %+v, want 90 ml drawn from the bag", feed)
+	}
+
+	resp = do(t, srv, "PUT", "/api/v1/feeding/"+feed.ID, map[string]any{
+		"feed_type": "breast_left", "milk_type": "formula",
+	})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("milk_type on breast feed update status = %d, want 422", resp.StatusCode)
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/milk-stash", nil)
+	var stash store.MilkStash
+	decodeJSON(t, resp, &stash)
+	if stash.TotalML != 60 {
+		t.Errorf("stash total = %d, want 60", stash.TotalML)
+	}
+
+	resp = do(t, srv, "DELETE", "/api/v1/milk-stash/"+bag.ID, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("discard status = %d, want 204", resp.StatusCode)
+	}
+}
+
+// ── medications ──────────────────────────────────────────────────────────────
+
+func TestMedications_CanGive(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "POST", "/api/v1/medications", map[string]any{
+		"name": "Paracetamol", "dose_unit": "ml", "default_dose": 2.5, "min_interval_minutes": 240, "max_daily_doses": 4,
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d, want 201", resp.StatusCode)
+	}
+	var med model.Medication
+	decodeJSON(t, resp, &med)
+
+	resp = do(t, srv, "GET", "/api/v1/medications/"+med.ID+"/can-give", nil)
+	var check store.DoseCheck
+	decodeJSON(t, resp, &check)
+	if !check.CanGive {
+		t.Errorf("before any dose: %+v, want can_give", check)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/medication-logs", map[string]any{"medication_id": med.ID})
+	var dose model.MedicationLog
+	decodeJSON(t, resp, &dose)
+
+	resp = do(t, srv, "GET", "/api/v1/medications/"+med.ID+"/can-give", nil)
+	decodeJSON(t, resp, &check)
+	if check.CanGive || check.NextAllowedAt == nil || check.LastGivenAt == nil || *check.LastGivenAt != dose.GivenAt {
+		t.Errorf("after a dose: %+v, want blocked until the interval passes", check)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/medication-logs", map[string]any{"medication_id": "nope"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("unknown medication status = %d, want 422", resp.StatusCode)
+	}
+
+	resp = do(t, srv, "DELETE", "/api/v1/medication-logs/"+dose.ID, nil)
+	resp.Body.Close()
+	resp = do(t, srv, "GET", "/api/v1/trash", nil)
+	var trash []model.TrashItem
+	decodeJSON(t, resp, &trash)
+	if len(trash) != 1 || trash[0].Kind != "medication_log" {
+		t.Errorf("trash = %+v, want the deleted dose", trash)
+	}
+}
+
+// ── temperature & symptoms ───────────────────────────────────────────────────
+
+func TestHealth_SickEpisodes(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "POST", "/api/v1/temperature", map[string]any{"celsius": 38.6, "method": "ear"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create temperature status = %d, want 201", resp.StatusCode)
+	}
+	var temp model.TemperatureLog
+	decodeJSON(t, resp, &temp)
+	if !temp.Fever {
+		t.Errorf("38.6 ear reading: %+v, want a fever", temp)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/temperature", map[string]any{"celsius": 45, "method": "mouth"})
+	var body struct {
+		Fields []validate.FieldError `json:"fields"`
+	}
+	decodeJSON(t, resp, &body)
+	if resp.StatusCode != http.StatusUnprocessableEntity || len(body.Fields) != 2 {
+		t.Errorf("bad reading: status %d, fields %+v, want 422 on celsius and method", resp.StatusCode, body.Fields)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/symptoms", map[string]any{"symptoms": []string{"Cough", " runny  nose ", "cough"}})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create symptom status = %d, want 201", resp.StatusCode)
+	}
+	var sym model.SymptomLog
+	decodeJSON(t, resp, &sym)
+	if len(sym.Symptoms) != 2 || sym.Symptoms[0] != "cough" || sym.Symptoms[1] != "runny-nose" || sym.Severity != "mild" {
+		t.Errorf("symptom = %+v, want normalized tags and mild severity", sym)
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/sick-episodes", nil)
+	var episodes []store.SickEpisode
+	decodeJSON(t, resp, &episodes)
+	if len(episodes) != 1 || !episodes[0].Ongoing || episodes[0].FeverDays != 1 || len(episodes[0].Symptoms) != 2 {
+		t.Errorf("episodes = %+v, want one ongoing episode with a fever and two symptoms", episodes)
+	}
+}
+
+// ── vaccinations ─────────────────────────────────────────────────────────────
+
+func TestVaccinations_Due(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "POST", "/api/v1/vaccinations", map[string]any{
+		"vaccine": "BCG", "dose_number": 1, "given_on": "2024-01-02", "clinic": "District 1", "lot_number": "B123",
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d, want 201", resp.StatusCode)
+	}
+	var vacc model.VaccinationLog
+	decodeJSON(t, resp, &vacc)
+	if vacc.Vaccine != "bcg" || vacc.LotNumber != "B123" {
+		t.Errorf("vaccination = %+v, want the bcg code and lot number", vacc)
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/vaccinations/due?within_days=0", nil)
+	var due store.VaccinationsDue
+	decodeJSON(t, resp, &due)
+	if due.ScheduleID != "vn-epi" || len(due.Overdue) == 0 || len(due.Upcoming) != 0 {
+		t.Fatalf("due = %+v, want overdue vn-epi doses and nothing upcoming", due)
+	}
+	for _, d := range due.Overdue {
+		if d.Vaccine == "bcg" {
+			t.Errorf("BCG was given but is listed as overdue")
+		}
+	}
+
+	resp = do(t, srv, "PUT", "/api/v1/settings", map[string]string{"vaccination_schedule": "nope"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("unknown schedule status = %d, want 422", resp.StatusCode)
+	}
+	resp = do(t, srv, "PUT", "/api/v1/settings", map[string]string{"vaccination_schedule": "who"})
+	resp.Body.Close()
+	resp = do(t, srv, "GET", "/api/v1/vaccinations/schedule", nil)
+	var schedule catalog.VaccineSchedule
+	decodeJSON(t, resp, &schedule)
+	if schedule.ID != "who" {
+		t.Errorf("schedule = %q, want who", schedule.ID)
+	}
+}
+
+// ── milestones ───────────────────────────────────────────────────────────────
+
+func TestMilestones(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "POST", "/api/v1/milestones", map[string]any{"code": "social-smile", "achieved_on": "2024-02-10"})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d, want 201", resp.StatusCode)
+	}
+	var m model.Milestone
+	decodeJSON(t, resp, &m)
+	if m.Title != "First social smile" {
+		t.Errorf("title = %q, want the catalogue name", m.Title)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/milestones", map[string]any{"code": "social-smile"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("duplicate status = %d, want 409", resp.StatusCode)
+	}
+	resp = do(t, srv, "POST", "/api/v1/milestones", map[string]any{"title": "First swim"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("custom without category status = %d, want 422", resp.StatusCode)
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/milestones/expected", nil)
+	var expected []store.ExpectedMilestone
+	decodeJSON(t, resp, &expected)
+	if len(expected) == 0 {
+		t.Fatal("no expected milestones for a child born in 2024")
+	}
+	for _, e := range expected {
+		if e.ID == "social-smile" {
+			t.Error("the recorded social-smile is still expected")
+		}
+	}
+}
+
+// ── solid foods ──────────────────────────────────────────────────────────────
+
+func TestSolidFeedings(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "POST", "/api/v1/foods", map[string]any{"name": "Bánh flan", "category": "dairy", "allergens": []string{"Milk", "egg"}})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create food status = %d, want 201", resp.StatusCode)
+	}
+	var food model.Food
+	decodeJSON(t, resp, &food)
+	if !food.Custom || len(food.Allergens) != 2 || food.Allergens[0] != "milk" {
+		t.Errorf("food = %+v, want a custom food with milk and egg", food)
+	}
+	resp = do(t, srv, "POST", "/api/v1/foods", map[string]any{"name": "Mystery", "category": "dairy", "allergens": []string{"gluten"}})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("unknown allergen status = %d, want 422", resp.StatusCode)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/solid-feedings", map[string]any{"food_id": food.ID, "amount_grams": 30})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create solid feeding status = %d, want 201", resp.StatusCode)
+	}
+	var feed model.SolidFeedingLog
+	decodeJSON(t, resp, &feed)
+	if feed.FoodName != "Bánh flan" || feed.Reaction != "none" {
+		t.Errorf("feed = %+v, want the food name and no reaction", feed)
+	}
+	resp = do(t, srv, "POST", "/api/v1/solid-feedings", map[string]any{"food_id": "nope"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("unknown food status = %d, want 422", resp.StatusCode)
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/solid-feedings/introductions", nil)
+	var intro store.FoodIntroductions
+	decodeJSON(t, resp, &intro)
+	if len(intro.Waiting) != 1 || intro.NextAllergenAt == nil || len(intro.AllergensIntroduced) != 2 {
+		t.Errorf("introductions = %+v, want the flan in its wait window", intro)
+	}
+}
+
+// ── attachments ──────────────────────────────────────────────────────────────
+
+// upload posts content as the "file" part of a multipart form.
+func upload(t *testing.T, srv *httptest.Server, path, filename string, content []byte) *http.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	part, _ := mw.CreateFormFile("file", filename)
+	part.Write(content)
+	mw.Close()
+	req, err := http.NewRequest("POST", srv.URL+path, &buf)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do request: %v", err)
+	}
+	return resp
+}
+
+func TestAttachments(t *testing.T) {
+	srv, st := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+	resp := do(t, srv, "POST", "/api/v1/diaper", map[string]string{"diaper_type": "dirty"})
+	var diaper model.DiaperLog
+	decodeJSON(t, resp, &diaper)
+
+	var img bytes.Buffer
+	png.Encode(&img, image.NewGray(image.Rect(0, 0, 600, 300)))
+	resp = upload(t, srv, "/api/v1/diaper/"+diaper.ID+"/attachments", `C:\photos\rash.png`, img.Bytes())
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("upload status = %d, want 201", resp.StatusCode)
+	}
+	var att model.Attachment
+	decodeJSON(t, resp, &att)
+	if att.Filename != "rash.png" || att.ContentType != "image/png" || !att.HasThumbnail || att.SizeBytes != int64(img.Len()) {
+		t.Errorf("attachment = %+v", att)
+	}
+
+	resp = upload(t, srv, "/api/v1/diaper/"+diaper.ID+"/attachments", "notes.txt", []byte("hello"))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("text upload status = %d, want 422", resp.StatusCode)
+	}
+	resp = upload(t, srv, "/api/v1/diaper/nope/attachments", "rash.png", img.Bytes())
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("upload to a missing log status = %d, want 404", resp.StatusCode)
+	}
+
+	var list []model.Attachment
+	decodeJSON(t, do(t, srv, "GET", "/api/v1/diaper/"+diaper.ID+"/attachments", nil), &list)
+	if len(list) != 1 || list[0].ID != att.ID {
+		t.Errorf("list = %+v, want the upload", list)
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/attachments/"+att.ID+"/file", nil)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(body, img.Bytes()) || resp.Header.Get("Content-Type") != "image/png" {
+		t.Errorf("file = %d %s, %d bytes", resp.StatusCode, resp.Header.Get("Content-Type"), len(body))
+	}
+	resp = do(t, srv, "GET", "/api/v1/attachments/"+att.ID+"/thumbnail", nil)
+	thumb, err := jpeg.DecodeConfig(resp.Body)
+	resp.Body.Close()
+	if err != nil || thumb.Width != 256 || thumb.Height != 128 {
+		t.Errorf("thumbnail = %+v, %v; want a 256x128 JPEG", thumb, err)
+	}
+
+	// A grandparent may see the child's files but not the diaper log's.
+	resp = upload(t, srv, "/api/v1/attachments", "scan.png", img.Bytes())
+	var scan model.Attachment
+	decodeJSON(t, resp, &scan)
+	mustLogin(t, srv, st, "ba", auth.RoleGrandparent)
+	for path, want := range map[string]int{
+		"/api/v1/attachments/" + scan.ID + "/file": http.StatusOK,
+		"/api/v1/attachments/" + att.ID + "/file":  http.StatusForbidden,
+	} {
+		resp := do(t, srv, "GET", path, nil)
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("grandparent GET %s status = %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+	mustLogin(t, srv, st, "mom", auth.RoleParent)
+
+	resp = do(t, srv, "DELETE", "/api/v1/attachments/"+att.ID, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete status = %d, want 204", resp.StatusCode)
+	}
+	// The scan shares its content with the deleted upload, so it survives.
+	resp = do(t, srv, "GET", "/api/v1/attachments/"+scan.ID+"/file", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("shared file after delete status = %d, want 200", resp.StatusCode)
+	}
+	resp = do(t, srv, "GET", "/api/v1/attachments/"+att.ID+"/file", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("deleted file status = %d, want 404", resp.StatusCode)
+	}
+}
+
+// ── overlaps ─────────────────────────────────────────────────────────────────
+
+func TestOverlap_ConflictAndResolve(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "POST", "/api/v1/sleep", map[string]string{
+		"start_time": "2024-01-15T13:00:00+07:00", "end_time": "2024-01-15T14:00:00+07:00",
+	})
+	var nap model.SleepLog
+	decodeJSON(t, resp, &nap)
+	if nap.EndTime == nil || nap.DurationMinutes == nil || *nap.DurationMinutes != 60 {
+		t.Fatalf("created = %+v, want a finished 60 minute sleep", nap)
+	}
+
+	overlapping := map[string]string{
+		"start_time": "2024-01-15T13:30:00+07:00", "end_time": "2024-01-15T14:30:00+07:00",
+	}
+	resp = do(t, srv, "POST", "/api/v1/sleep", overlapping)
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("status = %d, want 409", resp.StatusCode)
+	}
+	var conflict struct {
+		Conflicts []string `json:"conflicts"`
+	}
+	decodeJSON(t, resp, &conflict)
+	if len(conflict.Conflicts) != 1 || conflict.Conflicts[0] != nap.ID {
+		t.Errorf("conflicts = %v, want [%s]", conflict.Conflicts, nap.ID)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/sleep?resolve=squash", overlapping)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("unknown resolve status = %d, want 422", resp.StatusCode)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/sleep?resolve=truncate", overlapping)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("truncate status = %d, want 201", resp.StatusCode)
+	}
+	var later model.SleepLog
+	decodeJSON(t, resp, &later)
+	resp = do(t, srv, "GET", "/api/v1/sleep", nil)
+	var logs []model.SleepLog
+	decodeJSON(t, resp, &logs)
+	for _, l := range logs {
+		if l.ID == nap.ID && (l.EndTime == nil || *l.EndTime != "2024-01-15T13:30:00+07:00") {
+			t.Errorf("truncated EndTime = %v, want 13:30", l.EndTime)
+		}
+	}
+
+	// Restoring an entry a merge absorbed would bring the overlap back.
+	resp = do(t, srv, "POST", "/api/v1/sleep?resolve=merge", overlapping)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("merge status = %d, want 201", resp.StatusCode)
+	}
+	resp = do(t, srv, "POST", "/api/v1/sleep/"+later.ID+"/restore", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("restore absorbed status = %d, want 409", resp.StatusCode)
+	}
+}
+
+// ── trash ────────────────────────────────────────────────────────────────────
+
+func TestDeleteRestoreViaTrash(t *testing.T) {
+	srv, _ := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "POST", "/api/v1/diaper", map[string]string{"diaper_type": "wet"})
+	var created model.DiaperLog
+	decodeJSON(t, resp, &created)
+
+	resp = do(t, srv, "DELETE", "/api/v1/diaper/"+created.ID, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete status = %d, want 204", resp.StatusCode)
+	}
+	resp = do(t, srv, "PUT", "/api/v1/diaper/"+created.ID, map[string]string{"diaper_type": "dirty"})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("update deleted status = %d, want 404", resp.StatusCode)
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/trash", nil)
+	var trash []model.TrashItem
+	decodeJSON(t, resp, &trash)
+	if len(trash) != 1 || trash[0].Kind != "diaper" || trash[0].ID != created.ID {
+		t.Fatalf("trash = %+v, want the deleted diaper", trash)
+	}
+
+	resp = do(t, srv, "POST", "/api/v1/diaper/"+created.ID+"/restore", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("restore status = %d, want 200", resp.StatusCode)
+	}
+	resp = do(t, srv, "POST", "/api/v1/diaper/"+created.ID+"/restore", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("restore live entry status = %d, want 404", resp.StatusCode)
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/diaper", nil)
+	var logs []model.DiaperLog
+	decodeJSON(t, resp, &logs)
+	if len(logs) != 1 || logs[0].DeletedAt != nil {
+		t.Errorf("diapers after restore = %+v, want the restored entry", logs)
+	}
+}
+
+// ── audit ────────────────────────────────────────────────────────────────────
+
+func TestLogHistoryAndAuditFeed(t *testing.T) {
+	srv, st := newTestServer(t)
+	mustCreateChildViaAPI(t, srv)
+
+	resp := do(t, srv, "POST", "/api/v1/sleep", map[string]string{"start_time": "2024-01-15T13:00:00+07:00"})
+	var created model.SleepLog
+	decodeJSON(t, resp, &created)
+	resp = do(t, srv, "PUT", "/api/v1/sleep/"+created.ID, map[string]string{"end_time": "2024-01-15T14:00:00+07:00"})
+	resp.Body.Close()
+
+	resp = do(t, srv, "GET", "/api/v1/sleep/"+created.ID+"/history", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("history status = %d, want 200", resp.StatusCode)
+	}
+	var history []model.AuditEntry
+	decodeJSON(t, resp, &history)
+	if len(history) != 2 || history[1].Action != "update" || history[1].ActorName != "parent" {
+		t.Fatalf("history = %+v, want create then update by parent", history)
+	}
+
+	resp = do(t, srv, "GET", "/api/v1/audit?limit=2", nil)
+	var page struct {
+		Entries    []model.AuditEntry `json:"entries"`
+		NextCursor int64              `json:"next_cursor"`
+	}
+	decodeJSON(t, resp, &page)
+	if len(page.Entries) != 2 || page.NextCursor == 0 {
+		t.Fatalf("audit page = %+v, want 2 entries and a cursor", page)
+	}
+	cursor := page.NextCursor
+	resp = do(t, srv, "GET", fmt.Sprintf("/api/v1/audit?limit=2&cursor=%d", cursor), nil)
+	decodeJSON(t, resp, &page)
+	if len(page.Entries) == 0 || page.Entries[0].ID >= cursor {
+		t.Errorf("second page = %+v, want entries older than %d", page.Entries, cursor)
+	}
+
+	mustLogin(t, srv, st, "lan", auth.RoleNanny)
+	resp = do(t, srv, "GET", "/api/v1/audit", nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("nanny audit feed status = %d, want 403", resp.StatusCode)
+	}
+}
+
+// ── CORS ──────────────────────────────────────────────────────────────────────
+
+func TestCORSHeaders_NoWildcard(t *testing.T) {
+	srv, _ := newTestServer(t)
+	req, _ := http.NewRequest("GET", srv.URL+"/health", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("do request: %v", err)
+	}
+	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want none for unlisted origin", got)
 	}
 }
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"baby-care/internal/model"
+	"baby-care/internal/store"
+	"baby-care/internal/validate"
+)
+
+type temperatureRequest struct {
+	TakenAt string   `json:"taken_at"`
+	Celsius *float64 `json:"celsius"`
+	Method  string   `json:"method"`
+	Notes   string   `json:"notes"`
+}
+
+func (req temperatureRequest) validate(v *validate.Validator) {
+	v.Timestamp("taken_at", req.TakenAt)
+	v.Check(req.Celsius == nil || (*req.Celsius >= validate.MinCelsius && *req.Celsius <= validate.MaxCelsius),
+		"celsius", validate.CodeOutOfRange, fmt.Sprintf("must be between %g and %g", validate.MinCelsius, validate.MaxCelsius))
+	v.OneOf("method", req.Method, validate.TempMethods...)
+}
+
+type symptomRequest struct {
+	ObservedAt string   `json:"observed_at"`
+	Symptoms   []string `json:"symptoms"`
+	Severity   string   `json:"severity"`
+	Notes      string   `json:"notes"`
+}
+
+func (req symptomRequest) validate(v *validate.Validator) {
+	v.Timestamp("observed_at", req.ObservedAt)
+	v.Check(req.Symptoms == nil || len(req.Symptoms) > 0, "symptoms", validate.CodeRequired, "at least one symptom is required")
+	v.OneOf("severity", req.Severity, validate.Severities...)
+}
+
+// normalizeSymptoms lowercases tags, joins multi-word tags with dashes
+// ("Runny nose" becomes "runny-nose") and drops blanks and duplicates. A nil
+// slice stays nil so updates keep the stored tags.
+func normalizeSymptoms(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	out := []string{}
+	for _, t := range tags {
+		t = strings.Join(strings.Fields(strings.ToLower(t)), "-")
+		if t != "" && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// ListTemperatures lists readings. Query params: ?date=YYYY-MM-DD.
+func (h *Handler) ListTemperatures(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	date, ok := h.queryDate(w, r, "date")
+	if !ok {
+		return
+	}
+	logs, err := h.Store.GetTemperatureLogs(childID, date)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if logs == nil {
+		logs = []*model.TemperatureLog{}
+	}
+	h.JSON(w, http.StatusOK, logs)
+}
+
+func (h *Handler) CreateTemperature(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	var req temperatureRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	v.Check(req.Celsius != nil, "celsius", validate.CodeRequired, "is required")
+	v.Required("method", req.Method)
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	log, err := h.storeFor(r).CreateTemperature(childID, req.TakenAt, req.Method, req.Notes, *req.Celsius)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusCreated, log)
+}
+
+func (h *Handler) UpdateTemperature(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "temperature")
+	if !ok {
+		return
+	}
+	var req temperatureRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	log, err := h.storeFor(r).UpdateTemperature(id, req.TakenAt, req.Method, req.Notes, req.Celsius)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, log)
+}
+
+func (h *Handler) DeleteTemperature(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "temperature")
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeleteTemperature(id); err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSymptoms lists symptom entries. Query params: ?date=YYYY-MM-DD.
+func (h *Handler) ListSymptoms(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	date, ok := h.queryDate(w, r, "date")
+	if !ok {
+		return
+	}
+	logs, err := h.Store.GetSymptomLogs(childID, date)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if logs == nil {
+		logs = []*model.SymptomLog{}
+	}
+	h.JSON(w, http.StatusOK, logs)
+}
+
+func (h *Handler) CreateSymptom(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	var req symptomRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	req.Symptoms = normalizeSymptoms(req.Symptoms)
+	if req.Severity == "" {
+		req.Severity = "mild"
+	}
+	v := h.validator()
+	v.Check(req.Symptoms != nil, "symptoms", validate.CodeRequired, "at least one symptom is required")
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	log, err := h.storeFor(r).CreateSymptom(childID, req.ObservedAt, req.Severity, req.Notes, req.Symptoms)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusCreated, log)
+}
+
+func (h *Handler) UpdateSymptom(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "symptom")
+	if !ok {
+		return
+	}
+	var req symptomRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	req.Symptoms = normalizeSymptoms(req.Symptoms)
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	log, err := h.storeFor(r).UpdateSymptom(id, req.ObservedAt, req.Severity, req.Notes, req.Symptoms)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, log)
+}
+
+func (h *Handler) DeleteSymptom(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "symptom")
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeleteSymptom(id); err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSickEpisodes groups days with fever or symptoms into episodes, newest
+// first. Query params: ?from=, ?to= (default: the last 90 days).
+func (h *Handler) ListSickEpisodes(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+
+	v := h.validator()
+	to := r.URL.Query().Get("to")
+	from := r.URL.Query().Get("from")
+	v.Date("from", from)
+	v.Date("to", to)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+
+	now := time.Now().In(h.Store.Location())
+	if to == "" {
+		to = now.Format("2006-01-02")
+	}
+	if from == "" {
+		from = now.AddDate(0, 0, -89).Format("2006-01-02")
+	}
+
+	episodes, err := h.Store.GetSickEpisodes(childID, from, to)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if episodes == nil {
+		episodes = []*store.SickEpisode{}
+	}
+	h.JSON(w, http.StatusOK, episodes)
+}
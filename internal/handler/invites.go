@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"baby-care/internal/auth"
+	"baby-care/internal/model"
+	"baby-care/internal/store"
+	"baby-care/internal/validate"
+)
+
+// inviteTTL is how long an invite link can be redeemed.
+const inviteTTL = 7 * 24 * time.Hour
+
+type inviteRequest struct {
+	Role string `json:"role"`
+}
+
+type acceptInviteRequest struct {
+	Token    string `json:"token"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := h.Store.ListUsers()
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if users == nil {
+		users = []*model.User{}
+	}
+	h.JSON(w, http.StatusOK, users)
+}
+
+func (h *Handler) ListInvites(w http.ResponseWriter, r *http.Request) {
+	invites, err := h.Store.ListInvites()
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if invites == nil {
+		invites = []*model.Invite{}
+	}
+	h.JSON(w, http.StatusOK, invites)
+}
+
+// CreateInvite returns the invite together with its one-time token and a
+// link to share. The token is not stored and cannot be retrieved later.
+func (h *Handler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	var req inviteRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	if v.Required("role", req.Role) {
+		v.OneOf("role", req.Role, auth.Roles...)
+	}
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	token, hash, err := auth.NewToken()
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	inv, err := h.storeFor(r).CreateInvite(hash, req.Role, auth.UserFrom(r.Context()).ID, inviteTTL)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	type createInviteResponse struct {
+		*model.Invite
+		Token string `json:"token"`
+		URL   string `json:"url"`
+	}
+	h.JSON(w, http.StatusCreated, createInviteResponse{
+		Invite: inv,
+		Token:  token,
+		URL:    requestOrigin(r) + "/#/invite?token=" + token,
+	})
+}
+
+func (h *Handler) DeleteInvite(w http.ResponseWriter, r *http.Request) {
+	if err := h.storeFor(r).DeleteInvite(r.PathValue("inviteId")); err != nil {
+		if h.IsNotFound(err) {
+			h.Error(w, http.StatusNotFound, "invite not found")
+			return
+		}
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AcceptInvite creates the invited caregiver's account and logs them in.
+func (h *Handler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
+	var req acceptInviteRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	v.Required("token", req.Token)
+	v.Required("username", req.Username)
+	v.Check(len(req.Password) >= auth.MinPasswordLength, "password", validate.CodeOutOfRange, auth.ErrPasswordTooShort.Error())
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	user, err := h.storeFor(r).AcceptInvite(auth.HashToken(req.Token), req.Username, hash)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrInviteInvalid):
+			h.Error(w, http.StatusGone, err.Error())
+		case errors.Is(err, store.ErrUsernameTaken):
+			h.Error(w, http.StatusConflict, err.Error())
+		default:
+			h.Error(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	if err := h.startSession(w, r, user); err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusCreated, user)
+}
+
+// requestOrigin returns the scheme and host the client used to reach us.
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"baby-care/internal/model"
+	"baby-care/internal/store"
+	"baby-care/internal/validate"
+)
+
+type medicationRequest struct {
+	Name               string   `json:"name"`
+	DoseUnit           string   `json:"dose_unit"`
+	DefaultDose        *float64 `json:"default_dose"`
+	MinIntervalMinutes *int     `json:"min_interval_minutes"`
+	MaxDailyDoses      *int     `json:"max_daily_doses"`
+	Notes              string   `json:"notes"`
+}
+
+func (req medicationRequest) validate(v *validate.Validator) {
+	v.Check(req.DefaultDose == nil || *req.DefaultDose > 0, "default_dose", validate.CodeOutOfRange, "must be greater than 0")
+	v.Range("min_interval_minutes", req.MinIntervalMinutes, 1, validate.MaxDoseIntervalMinutes)
+	v.Range("max_daily_doses", req.MaxDailyDoses, 1, validate.MaxDailyDoses)
+}
+
+type medicationLogRequest struct {
+	MedicationID string   `json:"medication_id"`
+	GivenAt      string   `json:"given_at"`
+	Dose         *float64 `json:"dose"`
+	Notes        string   `json:"notes"`
+}
+
+func (req medicationLogRequest) validate(v *validate.Validator) {
+	v.Timestamp("given_at", req.GivenAt)
+	v.Check(req.Dose == nil || *req.Dose > 0, "dose", validate.CodeOutOfRange, "must be greater than 0")
+}
+
+// resolveMedication checks that the {medId} medication belongs to the child
+// addressed by the request and is not archived.
+func (h *Handler) resolveMedication(w http.ResponseWriter, r *http.Request) (*model.Medication, bool) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return nil, false
+	}
+	med, err := h.Store.GetMedication(r.PathValue("medId"))
+	if err != nil && !h.IsNotFound(err) {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if err != nil || med.ChildID != childID || med.ArchivedAt != nil {
+		h.Error(w, http.StatusNotFound, "medication not found")
+		return nil, false
+	}
+	return med, true
+}
+
+func (h *Handler) ListMedications(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	meds, err := h.Store.ListMedications(childID)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if meds == nil {
+		meds = []*model.Medication{}
+	}
+	h.JSON(w, http.StatusOK, meds)
+}
+
+func (h *Handler) CreateMedication(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	var req medicationRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	v.Required("name", req.Name)
+	v.Required("dose_unit", req.DoseUnit)
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	med, err := h.storeFor(r).CreateMedication(childID, req.Name, req.DoseUnit, req.Notes, req.DefaultDose, req.MinIntervalMinutes, req.MaxDailyDoses)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusCreated, med)
+}
+
+func (h *Handler) GetMedication(w http.ResponseWriter, r *http.Request) {
+	med, ok := h.resolveMedication(w, r)
+	if !ok {
+		return
+	}
+	h.JSON(w, http.StatusOK, med)
+}
+
+func (h *Handler) UpdateMedication(w http.ResponseWriter, r *http.Request) {
+	med, ok := h.resolveMedication(w, r)
+	if !ok {
+		return
+	}
+	var req medicationRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	updated, err := h.storeFor(r).UpdateMedication(med.ID, req.Name, req.DoseUnit, req.Notes, req.DefaultDose, req.MinIntervalMinutes, req.MaxDailyDoses)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, updated)
+}
+
+// DeleteMedication archives a medication; its doses stay in the history.
+func (h *Handler) DeleteMedication(w http.ResponseWriter, r *http.Request) {
+	med, ok := h.resolveMedication(w, r)
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).ArchiveMedication(med.ID); err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CanGiveMedication reports whether the medication may be given now and, if
+// not, when it next may be.
+func (h *Handler) CanGiveMedication(w http.ResponseWriter, r *http.Request) {
+	med, ok := h.resolveMedication(w, r)
+	if !ok {
+		return
+	}
+	check, err := h.Store.CheckDose(med.ID, time.Now())
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, check)
+}
+
+// ListMedicationLogs lists doses. Query params: ?date=YYYY-MM-DD,
+// ?medication_id=.
+func (h *Handler) ListMedicationLogs(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	date, ok := h.queryDate(w, r, "date")
+	if !ok {
+		return
+	}
+	logs, err := h.Store.GetMedicationLogs(childID, date, r.URL.Query().Get("medication_id"))
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if logs == nil {
+		logs = []*model.MedicationLog{}
+	}
+	h.JSON(w, http.StatusOK, logs)
+}
+
+func (h *Handler) CreateMedicationLog(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	var req medicationLogRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	v.Required("medication_id", req.MedicationID)
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	log, err := h.storeFor(r).CreateMedicationLog(childID, req.MedicationID, req.GivenAt, req.Notes, req.Dose)
+	if err != nil {
+		switch {
+		case h.IsNotFound(err):
+			h.Invalid(w, validate.Field("medication_id", validate.CodeInvalidChoice, "must be a medication of this child"))
+		case errors.Is(err, store.ErrMedicationArchived):
+			h.Invalid(w, validate.Field("medication_id", validate.CodeInvalidChoice, err.Error()))
+		default:
+			h.Error(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	h.JSON(w, http.StatusCreated, log)
+}
+
+func (h *Handler) UpdateMedicationLog(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "medication_log")
+	if !ok {
+		return
+	}
+	var req medicationLogRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	log, err := h.storeFor(r).UpdateMedicationLog(id, req.GivenAt, req.Notes, req.Dose)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, log)
+}
+
+func (h *Handler) DeleteMedicationLog(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "medication_log")
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeleteMedicationLog(id); err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"baby-care/internal/catalog"
+	"baby-care/internal/model"
+	"baby-care/internal/store"
+	"baby-care/internal/validate"
+)
+
+type milestoneRequest struct {
+	Code       string `json:"code"`
+	Title      string `json:"title"`
+	Category   string `json:"category"`
+	AchievedOn string `json:"achieved_on"`
+	PhotoURL   string `json:"photo_url"`
+	Notes      string `json:"notes"`
+}
+
+func (req milestoneRequest) validate(v *validate.Validator) {
+	v.OneOf("category", req.Category, catalog.MilestoneCategories...)
+	v.PastDate("achieved_on", req.AchievedOn)
+}
+
+func (h *Handler) ListMilestones(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	list, err := h.Store.GetMilestones(childID)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []*model.Milestone{}
+	}
+	h.JSON(w, http.StatusOK, list)
+}
+
+// CreateMilestone records a milestone, either from the catalogue by code or a
+// custom one with its own title and category.
+func (h *Handler) CreateMilestone(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	var req milestoneRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	if req.Code != "" {
+		_, known := catalog.MilestoneByID(req.Code)
+		v.Check(known, "code", validate.CodeInvalidChoice, "must be a milestone from the catalogue")
+	} else {
+		v.Required("title", req.Title)
+		v.Required("category", req.Category)
+	}
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	m, err := h.storeFor(r).CreateMilestone(childID, req.Code, req.Title, req.Category, req.AchievedOn, req.PhotoURL, req.Notes)
+	if err != nil {
+		if errors.Is(err, store.ErrMilestoneRecorded) {
+			h.Error(w, http.StatusConflict, err.Error())
+			return
+		}
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusCreated, m)
+}
+
+func (h *Handler) UpdateMilestone(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "milestone")
+	if !ok {
+		return
+	}
+	var req milestoneRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	m, err := h.storeFor(r).UpdateMilestone(id, req.Title, req.Category, req.AchievedOn, req.PhotoURL, req.Notes)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, m)
+}
+
+func (h *Handler) DeleteMilestone(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "milestone")
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeleteMilestone(id); err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetMilestoneCatalog returns the standard milestones with their typical age
+// windows.
+func (h *Handler) GetMilestoneCatalog(w http.ResponseWriter, r *http.Request) {
+	h.JSON(w, http.StatusOK, catalog.Milestones())
+}
+
+// GetExpectedMilestones lists catalogued milestones the child is of age for
+// but has not reached yet.
+func (h *Handler) GetExpectedMilestones(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	expected, err := h.Store.GetExpectedMilestones(childID, time.Now())
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, expected)
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"baby-care/internal/store"
+	"baby-care/internal/validate"
+)
+
+// defaultExpiringSoon is the GET /milk-stash window for bags about to expire.
+const defaultExpiringSoon = 24 * time.Hour
+
+type milkBagRequest struct {
+	PumpingID string `json:"pumping_id"`
+	VolumeML  *int   `json:"volume_ml"`
+	Location  string `json:"location"`
+	StoredAt  string `json:"stored_at"`
+	Notes     string `json:"notes"`
+}
+
+func (req milkBagRequest) validate(v *validate.Validator) {
+	v.OneOf("location", req.Location, validate.MilkLocations...)
+	v.Timestamp("stored_at", req.StoredAt)
+	v.Range("volume_ml", req.VolumeML, validate.MinQuantityML, validate.MaxQuantityML)
+}
+
+// resolveMilkBag checks that the {bagId} bag belongs to the child addressed
+// by the request and returns its ID.
+func (h *Handler) resolveMilkBag(w http.ResponseWriter, r *http.Request) (string, bool) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return "", false
+	}
+	bag, err := h.Store.GetMilkBag(r.PathValue("bagId"))
+	if err != nil && !h.IsNotFound(err) {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return "", false
+	}
+	if err != nil || bag.ChildID != childID {
+		h.Error(w, http.StatusNotFound, "milk bag not found")
+		return "", false
+	}
+	return bag.ID, true
+}
+
+// GetMilkStash reports stored milk. Query params: ?within_hours=24 sets the
+// window for expiring_soon (max 720).
+func (h *Handler) GetMilkStash(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	soon := defaultExpiringSoon
+	if s := r.URL.Query().Get("within_hours"); s != "" {
+		n, err := strconv.Atoi(s)
+		v := h.validator()
+		v.Check(err == nil && n >= 1 && n <= 720, "within_hours", validate.CodeOutOfRange, "must be between 1 and 720")
+		if err := v.Err(); err != nil {
+			h.Invalid(w, err)
+			return
+		}
+		soon = time.Duration(n) * time.Hour
+	}
+	stash, err := h.Store.GetMilkStash(childID, soon)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, stash)
+}
+
+func (h *Handler) CreateMilkBag(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	var req milkBagRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	v.Required("location", req.Location)
+	if req.PumpingID == "" {
+		v.Check(req.VolumeML != nil, "volume_ml", validate.CodeRequired, "is required without pumping_id")
+	}
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	bag, err := h.storeFor(r).CreateMilkBag(childID, req.PumpingID, req.Location, req.StoredAt, req.Notes, req.VolumeML)
+	if err != nil {
+		switch {
+		case h.IsNotFound(err):
+			h.Invalid(w, validate.Field("pumping_id", validate.CodeInvalidChoice, "must be a pumping session of this child"))
+		case errors.Is(err, store.ErrExceedsPumped):
+			h.Invalid(w, validate.Field("volume_ml", validate.CodeOutOfRange, err.Error()))
+		default:
+			h.Error(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	h.JSON(w, http.StatusCreated, bag)
+}
+
+func (h *Handler) UpdateMilkBag(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveMilkBag(w, r)
+	if !ok {
+		return
+	}
+	var req milkBagRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	v.OneOf("location", req.Location, validate.MilkLocations...)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	bag, err := h.storeFor(r).UpdateMilkBag(id, req.Location, req.Notes)
+	if err != nil {
+		if errors.Is(err, store.ErrRefreeze) {
+			h.Invalid(w, validate.Field("location", validate.CodeInvalidChoice, err.Error()))
+			return
+		}
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, bag)
+}
+
+// DeleteMilkBag discards a bag, e.g. once it has expired.
+func (h *Handler) DeleteMilkBag(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveMilkBag(w, r)
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeleteMilkBag(id); err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+)
+
+// GetPredictions estimates the next feed and nap from the past week.
+func (h *Handler) GetPredictions(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	p, err := h.Store.GetPredictions(childID, time.Now())
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, p)
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"baby-care/internal/model"
+	"baby-care/internal/store"
+	"baby-care/internal/validate"
+)
+
+type pumpingRequest struct {
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	LeftML    *int   `json:"left_ml"`
+	RightML   *int   `json:"right_ml"`
+	Notes     string `json:"notes"`
+}
+
+func (req pumpingRequest) validate(v *validate.Validator) {
+	start := v.Timestamp("start_time", req.StartTime)
+	end := v.Timestamp("end_time", req.EndTime)
+	v.EndAfterStart("end_time", start, end)
+	v.Range("left_ml", req.LeftML, 0, validate.MaxPumpedML)
+	v.Range("right_ml", req.RightML, 0, validate.MaxPumpedML)
+}
+
+func (h *Handler) ListPumping(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	date, ok := h.queryDate(w, r, "date")
+	if !ok {
+		return
+	}
+	logs, err := h.Store.GetPumpingLogs(childID, date)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if logs == nil {
+		logs = []*model.PumpingLog{}
+	}
+	h.JSON(w, http.StatusOK, logs)
+}
+
+func (h *Handler) CreatePumping(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	var req pumpingRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	st, ok := h.timedStore(w, r)
+	if !ok {
+		return
+	}
+	log, err := st.CreatePumping(childID, req.StartTime, req.EndTime, req.Notes, req.LeftML, req.RightML)
+	if err != nil {
+		if h.Conflict(w, err) {
+			return
+		}
+		if errors.Is(err, store.ErrEndBeforeStart) {
+			h.Invalid(w, validate.Field("end_time", validate.CodeBeforeStart, "must not be before the start time"))
+			return
+		}
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusCreated, log)
+}
+
+func (h *Handler) GetActivePumping(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	log, err := h.Store.GetActivePumping(childID)
+	if err != nil {
+		if h.IsNotFound(err) {
+			h.JSON(w, http.StatusOK, nil)
+			return
+		}
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, log)
+}
+
+func (h *Handler) UpdatePumping(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "pumping")
+	if !ok {
+		return
+	}
+	var req pumpingRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	st, ok := h.timedStore(w, r)
+	if !ok {
+		return
+	}
+	log, err := st.UpdatePumping(id, req.StartTime, req.EndTime, req.Notes, req.LeftML, req.RightML)
+	if err != nil {
+		if h.Conflict(w, err) {
+			return
+		}
+		if errors.Is(err, store.ErrEndBeforeStart) {
+			h.Invalid(w, validate.Field("end_time", validate.CodeBeforeStart, "must not be before the start time"))
+			return
+		}
+		if errors.Is(err, store.ErrExceedsPumped) {
+			msg := "must not total less than the milk already bagged from the session"
+			h.Invalid(w, validate.Errors{
+				{Field: "left_ml", Code: validate.CodeOutOfRange, Message: msg},
+				{Field: "right_ml", Code: validate.CodeOutOfRange, Message: msg},
+			})
+			return
+		}
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, log)
+}
+
+func (h *Handler) DeletePumping(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "pumping")
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeletePumping(id); err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
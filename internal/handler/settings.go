@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"baby-care/internal/catalog"
+	"baby-care/internal/store"
+	"baby-care/internal/validate"
+)
+
+type settingsRequest struct {
+	Timezone            string `json:"timezone"`
+	VaccinationSchedule string `json:"vaccination_schedule"`
+	NightStart          string `json:"night_start"`
+	NightEnd            string `json:"night_end"`
+}
+
+func (h *Handler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.Store.GetSettings()
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, settings)
+}
+
+func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var req settingsRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	v.Clock("night_start", req.NightStart)
+	v.Clock("night_end", req.NightEnd)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	if req.Timezone != "" {
+		if err := h.storeFor(r).SetTimezone(req.Timezone); err != nil {
+			if errors.Is(err, store.ErrInvalidTimezone) {
+				h.Invalid(w, validate.Field("timezone", validate.CodeInvalidChoice, "must be an IANA timezone name, e.g. Europe/Berlin"))
+				return
+			}
+			h.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	if req.VaccinationSchedule != "" {
+		if err := h.storeFor(r).SetVaccinationSchedule(req.VaccinationSchedule); err != nil {
+			if errors.Is(err, store.ErrUnknownSchedule) {
+				h.Invalid(w, validate.Field("vaccination_schedule", validate.CodeInvalidChoice,
+					"must be one of "+strings.Join(catalog.VaccineScheduleIDs(), ", ")))
+				return
+			}
+			h.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	if req.NightStart != "" || req.NightEnd != "" {
+		if err := h.storeFor(r).SetNightWindow(req.NightStart, req.NightEnd); err != nil {
+			if errors.Is(err, store.ErrInvalidNightWindow) {
+				h.Invalid(w, validate.Field("night_end", validate.CodeInvalidChoice, "must differ from night_start"))
+				return
+			}
+			h.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	h.GetSettings(w, r)
+}
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"baby-care/internal/model"
 	"baby-care/internal/store"
+	"baby-care/internal/validate"
 )
 
 type sleepRequest struct {
@@ -13,25 +15,21 @@ type sleepRequest struct {
 	Notes     string `json:"notes"`
 }
 
-func (h *Handler) requireChild(w http.ResponseWriter) (string, bool) {
-	child, err := h.Store.GetChild()
-	if err != nil {
-		if h.IsNotFound(err) {
-			h.Error(w, http.StatusBadRequest, "no child profile found")
-		} else {
-			h.Error(w, http.StatusInternalServerError, err.Error())
-		}
-		return "", false
-	}
-	return child.ID, true
+func (req sleepRequest) validate(v *validate.Validator) {
+	start := v.Timestamp("start_time", req.StartTime)
+	end := v.Timestamp("end_time", req.EndTime)
+	v.EndAfterStart("end_time", start, end)
 }
 
 func (h *Handler) ListSleep(w http.ResponseWriter, r *http.Request) {
-	childID, ok := h.requireChild(w)
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	date, ok := h.queryDate(w, r, "date")
 	if !ok {
 		return
 	}
-	date := r.URL.Query().Get("date")
 	logs, err := h.Store.GetSleepLogs(childID, date)
 	if err != nil {
 		h.Error(w, http.StatusInternalServerError, err.Error())
@@ -44,7 +42,7 @@ func (h *Handler) ListSleep(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) CreateSleep(w http.ResponseWriter, r *http.Request) {
-	childID, ok := h.requireChild(w)
+	childID, ok := h.resolveChild(w, r)
 	if !ok {
 		return
 	}
@@ -53,8 +51,25 @@ func (h *Handler) CreateSleep(w http.ResponseWriter, r *http.Request) {
 		h.Error(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	log, stopped, err := h.Store.CreateSleep(childID, req.StartTime, req.Notes)
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	st, ok := h.timedStore(w, r)
+	if !ok {
+		return
+	}
+	log, stopped, err := st.CreateSleepSpan(childID, req.StartTime, req.EndTime, req.Notes)
 	if err != nil {
+		if h.Conflict(w, err) {
+			return
+		}
+		if errors.Is(err, store.ErrEndBeforeStart) {
+			h.Invalid(w, validate.Field("end_time", validate.CodeBeforeStart, "must not be before the start time"))
+			return
+		}
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -66,7 +81,7 @@ func (h *Handler) CreateSleep(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetActiveSleep(w http.ResponseWriter, r *http.Request) {
-	childID, ok := h.requireChild(w)
+	childID, ok := h.resolveChild(w, r)
 	if !ok {
 		return
 	}
@@ -83,14 +98,34 @@ func (h *Handler) GetActiveSleep(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) UpdateSleep(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("logId")
+	id, ok := h.resolveLog(w, r, "sleep")
+	if !ok {
+		return
+	}
 	var req sleepRequest
 	if err := h.Decode(r, &req); err != nil {
 		h.Error(w, http.StatusBadRequest, "invalid JSON")
 		return
 	}
-	log, err := h.Store.UpdateSleep(id, req.StartTime, req.EndTime, req.Notes)
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	st, ok := h.timedStore(w, r)
+	if !ok {
+		return
+	}
+	log, err := st.UpdateSleep(id, req.StartTime, req.EndTime, req.Notes)
 	if err != nil {
+		if h.Conflict(w, err) {
+			return
+		}
+		if errors.Is(err, store.ErrEndBeforeStart) {
+			h.Invalid(w, validate.Field("end_time", validate.CodeBeforeStart, "must not be before the start time"))
+			return
+		}
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -98,8 +133,11 @@ func (h *Handler) UpdateSleep(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) DeleteSleep(w http.ResponseWriter, r *http.Request) {
-	id := r.PathValue("logId")
-	if err := h.Store.DeleteSleep(id); err != nil {
+	id, ok := h.resolveLog(w, r, "sleep")
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeleteSleep(id); err != nil {
 		h.Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"baby-care/internal/catalog"
+	"baby-care/internal/model"
+	"baby-care/internal/validate"
+)
+
+type foodRequest struct {
+	Name      string   `json:"name"`
+	Category  string   `json:"category"`
+	Allergens []string `json:"allergens"`
+}
+
+type solidFeedingRequest struct {
+	FoodID        string `json:"food_id"`
+	FedAt         string `json:"fed_at"`
+	AmountGrams   *int   `json:"amount_grams"`
+	Reaction      string `json:"reaction"`
+	ReactionNotes string `json:"reaction_notes"`
+	Notes         string `json:"notes"`
+}
+
+func (req solidFeedingRequest) validate(v *validate.Validator) {
+	v.Timestamp("fed_at", req.FedAt)
+	v.Range("amount_grams", req.AmountGrams, 1, validate.MaxSolidGrams)
+	v.OneOf("reaction", req.Reaction, validate.Reactions...)
+}
+
+func (h *Handler) ListFoods(w http.ResponseWriter, r *http.Request) {
+	foods, err := h.Store.ListFoods()
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if foods == nil {
+		foods = []*model.Food{}
+	}
+	h.JSON(w, http.StatusOK, foods)
+}
+
+// CreateFood adds a household food that is not in the catalogue.
+func (h *Handler) CreateFood(w http.ResponseWriter, r *http.Request) {
+	var req foodRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	var allergens []string
+	for _, a := range req.Allergens {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if !slices.Contains(allergens, a) {
+			allergens = append(allergens, a)
+		}
+	}
+	v := h.validator()
+	v.Required("name", req.Name)
+	if v.Required("category", req.Category) {
+		v.OneOf("category", req.Category, catalog.FoodCategories...)
+	}
+	for _, a := range allergens {
+		v.OneOf("allergens", a, catalog.Allergens...)
+	}
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	food, err := h.storeFor(r).CreateFood(strings.TrimSpace(req.Name), req.Category, allergens)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusCreated, food)
+}
+
+// ListSolidFeedings lists servings. Query params: ?date=YYYY-MM-DD.
+func (h *Handler) ListSolidFeedings(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	date, ok := h.queryDate(w, r, "date")
+	if !ok {
+		return
+	}
+	logs, err := h.Store.GetSolidFeedings(childID, date)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if logs == nil {
+		logs = []*model.SolidFeedingLog{}
+	}
+	h.JSON(w, http.StatusOK, logs)
+}
+
+func (h *Handler) CreateSolidFeeding(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	var req solidFeedingRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	v.Required("food_id", req.FoodID)
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	log, err := h.storeFor(r).CreateSolidFeeding(childID, req.FoodID, req.FedAt, req.Reaction, req.ReactionNotes, req.Notes, req.AmountGrams)
+	if err != nil {
+		if h.IsNotFound(err) {
+			h.Invalid(w, validate.Field("food_id", validate.CodeInvalidChoice, "must be a known food"))
+			return
+		}
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusCreated, log)
+}
+
+func (h *Handler) UpdateSolidFeeding(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "solid_feeding")
+	if !ok {
+		return
+	}
+	var req solidFeedingRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	log, err := h.storeFor(r).UpdateSolidFeeding(id, req.FoodID, req.FedAt, req.Reaction, req.ReactionNotes, req.Notes, req.AmountGrams)
+	if err != nil {
+		if h.IsNotFound(err) && req.FoodID != "" {
+			h.Invalid(w, validate.Field("food_id", validate.CodeInvalidChoice, "must be a known food"))
+			return
+		}
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, log)
+}
+
+func (h *Handler) DeleteSolidFeeding(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "solid_feeding")
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeleteSolidFeeding(id); err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetFoodIntroductions reports foods tried, allergens introduced and pending,
+// and foods still in their wait window.
+func (h *Handler) GetFoodIntroductions(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	intro, err := h.Store.GetFoodIntroductions(childID, time.Now())
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, intro)
+}
@@ -5,16 +5,17 @@ import (
 	"time"
 )
 
-var hcmcTZ = time.FixedZone("Asia/Ho_Chi_Minh", 7*60*60)
-
 func (h *Handler) GetSummary(w http.ResponseWriter, r *http.Request) {
-	childID, ok := h.requireChild(w)
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	date, ok := h.queryDate(w, r, "date")
 	if !ok {
 		return
 	}
-	date := r.URL.Query().Get("date")
 	if date == "" {
-		date = time.Now().In(hcmcTZ).Format("2006-01-02")
+		date = time.Now().In(h.Store.Location()).Format("2006-01-02")
 	}
 	summary, err := h.Store.GetDaySummary(childID, date)
 	if err != nil {
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"baby-care/internal/store"
+	"baby-care/internal/validate"
+)
+
+// timerRequest is the optional body of the timer actions: pause, resume and
+// switching sides. At defaults to now.
+type timerRequest struct {
+	At string `json:"at"`
+}
+
+// decodeTimerRequest reads an optional timerRequest. It writes an error
+// response and returns false when the body is malformed.
+func (h *Handler) decodeTimerRequest(w http.ResponseWriter, r *http.Request) (timerRequest, bool) {
+	var req timerRequest
+	if err := h.Decode(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return req, false
+	}
+	v := h.validator()
+	v.Timestamp("at", req.At)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return req, false
+	}
+	return req, true
+}
+
+// PauseTimer serves POST /{kind}/{logId}/pause for the running sleep or
+// breast feed.
+func (h *Handler) PauseTimer(kind string) http.HandlerFunc {
+	return h.timerAction(kind, (*store.Store).PauseTimer, "must not be before the timer started or was last resumed")
+}
+
+// ResumeTimer serves POST /{kind}/{logId}/resume for a paused sleep or
+// breast feed.
+func (h *Handler) ResumeTimer(kind string) http.HandlerFunc {
+	return h.timerAction(kind, (*store.Store).ResumeTimer, "must not be before the timer was paused")
+}
+
+func (h *Handler) timerAction(kind string, action func(st *store.Store, kind, id, at string) (any, error), beforeMsg string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := h.resolveLog(w, r, kind)
+		if !ok {
+			return
+		}
+		req, ok := h.decodeTimerRequest(w, r)
+		if !ok {
+			return
+		}
+		log, err := action(h.storeFor(r), kind, id, req.At)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrTimerNotRunning), errors.Is(err, store.ErrTimerPaused), errors.Is(err, store.ErrTimerNotPaused):
+				h.Error(w, http.StatusConflict, err.Error())
+			case errors.Is(err, store.ErrEndBeforeStart):
+				h.Invalid(w, validate.Field("at", validate.CodeBeforeStart, beforeMsg))
+			default:
+				h.Error(w, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		h.JSON(w, http.StatusOK, log)
+	}
+}
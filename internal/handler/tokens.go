@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"baby-care/internal/auth"
+	"baby-care/internal/model"
+	"baby-care/internal/validate"
+)
+
+type tokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+func (h *Handler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.Store.ListAPITokens(auth.UserFrom(r.Context()).ID)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if tokens == nil {
+		tokens = []*model.APIToken{}
+	}
+	h.JSON(w, http.StatusOK, tokens)
+}
+
+// CreateToken issues an API token for the current user. Scopes must be
+// permissions the user's role holds; the secret is only returned here.
+func (h *Handler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFrom(r.Context())
+	if user.Scopes != nil {
+		h.Error(w, http.StatusForbidden, "API tokens can only be managed from a logged-in session")
+		return
+	}
+	var req tokenRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	v := h.validator()
+	v.Required("name", req.Name)
+	v.Check(len(req.Scopes) > 0, "scopes", validate.CodeRequired, "is required")
+	for _, scope := range req.Scopes {
+		v.Check(slices.Contains(auth.Permissions, scope), "scopes", validate.CodeInvalidChoice, "unknown scope "+scope)
+	}
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !auth.Allowed(user.Role, scope) {
+			h.Error(w, http.StatusForbidden, "your role cannot grant scope: "+scope)
+			return
+		}
+	}
+	slices.Sort(req.Scopes)
+	req.Scopes = slices.Compact(req.Scopes)
+
+	token, hash, err := auth.NewToken()
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	t, err := h.storeFor(r).CreateAPIToken(user.ID, req.Name, hash, req.Scopes)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	type createTokenResponse struct {
+		*model.APIToken
+		Token string `json:"token"`
+	}
+	h.JSON(w, http.StatusCreated, createTokenResponse{APIToken: t, Token: token})
+}
+
+func (h *Handler) DeleteToken(w http.ResponseWriter, r *http.Request) {
+	user := auth.UserFrom(r.Context())
+	if user.Scopes != nil {
+		h.Error(w, http.StatusForbidden, "API tokens can only be managed from a logged-in session")
+		return
+	}
+	if err := h.storeFor(r).DeleteAPIToken(user.ID, r.PathValue("tokenId")); err != nil {
+		if h.IsNotFound(err) {
+			h.Error(w, http.StatusNotFound, "token not found")
+			return
+		}
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"baby-care/internal/auth"
+	"baby-care/internal/model"
+	"baby-care/internal/store"
+)
+
+// logReadPerms maps each log kind to the permission needed to see it.
+var logReadPerms = map[string]string{
+	"sleep":          auth.PermSleepRead,
+	"feeding":        auth.PermFeedingRead,
+	"pumping":        auth.PermPumpingRead,
+	"diaper":         auth.PermDiaperRead,
+	"growth":         auth.PermGrowthRead,
+	"medication_log": auth.PermHealthRead,
+	"temperature":    auth.PermHealthRead,
+	"symptom":        auth.PermHealthRead,
+	"vaccination":    auth.PermHealthRead,
+	"milestone":      auth.PermGrowthRead,
+	"solid_feeding":  auth.PermFeedingRead,
+}
+
+// logWritePerms maps each log kind to the permission needed to change it.
+var logWritePerms = map[string]string{
+	"sleep":          auth.PermSleepWrite,
+	"feeding":        auth.PermFeedingWrite,
+	"pumping":        auth.PermPumpingWrite,
+	"diaper":         auth.PermDiaperWrite,
+	"growth":         auth.PermGrowthWrite,
+	"medication_log": auth.PermHealthWrite,
+	"temperature":    auth.PermHealthWrite,
+	"symptom":        auth.PermHealthWrite,
+	"vaccination":    auth.PermHealthWrite,
+	"milestone":      auth.PermGrowthWrite,
+	"solid_feeding":  auth.PermFeedingWrite,
+}
+
+// ListTrash lists the child's deleted log entries that the user may read.
+func (h *Handler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	items, err := h.Store.ListTrash(childID)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	user := auth.UserFrom(r.Context())
+	visible := []*model.TrashItem{}
+	for _, item := range items {
+		if auth.Can(user, logReadPerms[item.Kind]) {
+			visible = append(visible, item)
+		}
+	}
+	h.JSON(w, http.StatusOK, visible)
+}
+
+// RestoreLog serves POST /{kind}/{logId}/restore, taking an entry of the
+// given kind out of the trash.
+func (h *Handler) RestoreLog(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := h.resolveTrashedLog(w, r, kind)
+		if !ok {
+			return
+		}
+		log, err := h.storeFor(r).RestoreLog(kind, id)
+		if err != nil {
+			if h.Conflict(w, err) {
+				return
+			}
+			if errors.Is(err, store.ErrMilestoneRecorded) {
+				h.Error(w, http.StatusConflict, err.Error())
+				return
+			}
+			if h.IsNotFound(err) {
+				h.Error(w, http.StatusNotFound, logNotFound(kind))
+				return
+			}
+			h.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		h.JSON(w, http.StatusOK, log)
+	}
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"baby-care/internal/model"
+	"baby-care/internal/validate"
+)
+
+// defaultDueWithinDays is how far ahead /vaccinations/due looks for upcoming
+// doses unless ?within_days= says otherwise.
+const defaultDueWithinDays = 60
+
+type vaccinationRequest struct {
+	Vaccine    string `json:"vaccine"`
+	DoseNumber *int   `json:"dose_number"`
+	GivenOn    string `json:"given_on"`
+	Clinic     string `json:"clinic"`
+	LotNumber  string `json:"lot_number"`
+	Notes      string `json:"notes"`
+}
+
+func (req vaccinationRequest) validate(v *validate.Validator) {
+	v.Range("dose_number", req.DoseNumber, 1, validate.MaxVaccineDose)
+	v.PastDate("given_on", req.GivenOn)
+}
+
+// normalizeVaccine lowercases a vaccine code so "BCG" matches the schedule's
+// "bcg".
+func normalizeVaccine(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+func (h *Handler) ListVaccinations(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	logs, err := h.Store.GetVaccinationLogs(childID)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if logs == nil {
+		logs = []*model.VaccinationLog{}
+	}
+	h.JSON(w, http.StatusOK, logs)
+}
+
+func (h *Handler) CreateVaccination(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	var req vaccinationRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	req.Vaccine = normalizeVaccine(req.Vaccine)
+	v := h.validator()
+	v.Required("vaccine", req.Vaccine)
+	v.Check(req.DoseNumber != nil, "dose_number", validate.CodeRequired, "is required")
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	log, err := h.storeFor(r).CreateVaccination(childID, req.Vaccine, *req.DoseNumber, req.GivenOn, req.Clinic, req.LotNumber, req.Notes)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusCreated, log)
+}
+
+func (h *Handler) UpdateVaccination(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "vaccination")
+	if !ok {
+		return
+	}
+	var req vaccinationRequest
+	if err := h.Decode(r, &req); err != nil {
+		h.Error(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	v := h.validator()
+	req.validate(v)
+	if err := v.Err(); err != nil {
+		h.Invalid(w, err)
+		return
+	}
+	log, err := h.storeFor(r).UpdateVaccination(id, normalizeVaccine(req.Vaccine), req.DoseNumber, req.GivenOn, req.Clinic, req.LotNumber, req.Notes)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, log)
+}
+
+func (h *Handler) DeleteVaccination(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.resolveLog(w, r, "vaccination")
+	if !ok {
+		return
+	}
+	if err := h.storeFor(r).DeleteVaccination(id); err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetVaccinationSchedule returns the household's vaccination schedule.
+func (h *Handler) GetVaccinationSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, err := h.Store.VaccineSchedule()
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, schedule)
+}
+
+// GetVaccinationsDue lists overdue, due and upcoming doses. Query params:
+// ?within_days= (default 60, max 730) limits how far ahead upcoming doses are listed.
+func (h *Handler) GetVaccinationsDue(w http.ResponseWriter, r *http.Request) {
+	childID, ok := h.resolveChild(w, r)
+	if !ok {
+		return
+	}
+	within := defaultDueWithinDays
+	if s := r.URL.Query().Get("within_days"); s != "" {
+		n, err := strconv.Atoi(s)
+		v := h.validator()
+		v.Check(err == nil && n >= 0 && n <= 730, "within_days", validate.CodeOutOfRange, "must be between 0 and 730")
+		if err := v.Err(); err != nil {
+			h.Invalid(w, err)
+			return
+		}
+		within = n
+	}
+	due, err := h.Store.GetVaccinationsDue(childID, time.Now(), within)
+	if err != nil {
+		h.Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.JSON(w, http.StatusOK, due)
+}
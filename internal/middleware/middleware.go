@@ -1,9 +1,15 @@
 package middleware
 
 import (
+	"encoding/json"
 	"log"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
+
+	"baby-care/internal/auth"
+	"baby-care/internal/model"
 )
 
 func Chain(h http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
@@ -22,17 +28,77 @@ func Logger(next http.Handler) http.Handler {
 	})
 }
 
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent)
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
+// CORS allows cross-origin requests, with credentials, from the listed
+// origins only. Requests from any other origin get no CORS headers, so the
+// browser enforces same-origin.
+func CORS(allowedOrigins ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin != "" && slices.Contains(allowedOrigins, origin) {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+				w.Header().Add("Vary", "Origin")
+			}
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Authenticator identifies the caller of a request. It returns a nil user
+// when the request carries no valid credentials.
+type Authenticator func(r *http.Request) (*model.User, error)
+
+// Auth requires an authenticated user for every /api/ request except the
+// listed public paths. The user is stored in the request context for
+// handlers to read with auth.UserFrom.
+func Auth(authenticate Authenticator, publicPaths ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.URL.Path, "/api/") || slices.Contains(publicPaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			user, err := authenticate(r)
+			if err != nil {
+				log.Printf("authenticate: %v", err)
+				writeError(w, http.StatusInternalServerError, "authentication failed")
+				return
+			}
+			if user == nil {
+				writeError(w, http.StatusUnauthorized, "authentication required")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+		})
+	}
+}
+
+// Require rejects requests whose user lacks perm, by role or by API token
+// scope, with 403. It must be wrapped by Auth so the user is in the request
+// context.
+func Require(perm string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !auth.Can(auth.UserFrom(r.Context()), perm) {
+				writeError(w, http.StatusForbidden, "forbidden: requires "+perm)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
 }
 
 type responseWriter struct {
@@ -5,7 +5,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"baby-care/internal/auth"
 	"baby-care/internal/middleware"
+	"baby-care/internal/model"
 )
 
 func TestChain_AppliesInOrder(t *testing.T) {
@@ -43,16 +45,21 @@ func TestChain_AppliesInOrder(t *testing.T) {
 	}
 }
 
-func TestCORS_HeadersSet(t *testing.T) {
-	handler := middleware.CORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+func TestCORS_AllowedOrigin(t *testing.T) {
+	handler := middleware.CORS("https://app.example")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
 	w := httptest.NewRecorder()
-	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Origin", "https://app.example")
+	handler.ServeHTTP(w, req)
 
-	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
-		t.Errorf("ACAO = %q, want *", got)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example" {
+		t.Errorf("ACAO = %q, want https://app.example", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("ACAC = %q, want true", got)
 	}
 	if got := w.Header().Get("Access-Control-Allow-Methods"); got == "" {
 		t.Error("expected Access-Control-Allow-Methods header")
@@ -62,8 +69,23 @@ func TestCORS_HeadersSet(t *testing.T) {
 	}
 }
 
+func TestCORS_UnlistedOrigin(t *testing.T) {
+	handler := middleware.CORS("https://app.example")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	handler.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("ACAO = %q, want none", got)
+	}
+}
+
 func TestCORS_Preflight(t *testing.T) {
-	handler := middleware.CORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := middleware.CORS()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t.Error("base handler should not be called for OPTIONS preflight")
 	}))
 
@@ -75,6 +97,80 @@ func TestCORS_Preflight(t *testing.T) {
 	}
 }
 
+func TestAuth(t *testing.T) {
+	parent := &model.User{ID: "u1", Username: "mom"}
+	authenticate := func(r *http.Request) (*model.User, error) {
+		if r.Header.Get("X-Test-User") == "mom" {
+			return parent, nil
+		}
+		return nil, nil
+	}
+	var seen *model.User
+	handler := middleware.Auth(authenticate, "/api/v1/auth/login")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = auth.UserFrom(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tc := range []struct {
+		name, path, user string
+		want             int
+		wantUser         *model.User
+	}{
+		{"anonymous api", "/api/v1/sleep", "", http.StatusUnauthorized, nil},
+		{"authenticated api", "/api/v1/sleep", "mom", http.StatusOK, parent},
+		{"public api path", "/api/v1/auth/login", "", http.StatusOK, nil},
+		{"health", "/health", "", http.StatusOK, nil},
+		{"static", "/app.js", "", http.StatusOK, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			seen = nil
+			req := httptest.NewRequest("GET", tc.path, nil)
+			if tc.user != "" {
+				req.Header.Set("X-Test-User", tc.user)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Errorf("status = %d, want %d", w.Code, tc.want)
+			}
+			if seen != tc.wantUser {
+				t.Errorf("context user = %v, want %v", seen, tc.wantUser)
+			}
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	handler := middleware.Require(auth.PermGrowthWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tc := range []struct {
+		name string
+		user *model.User
+		want int
+	}{
+		{"no user", nil, http.StatusForbidden},
+		{"parent", &model.User{Role: auth.RoleParent}, http.StatusOK},
+		{"nanny", &model.User{Role: auth.RoleNanny}, http.StatusForbidden},
+		{"parent token in scope", &model.User{Role: auth.RoleParent, Scopes: []string{auth.PermGrowthWrite}}, http.StatusOK},
+		{"parent token out of scope", &model.User{Role: auth.RoleParent, Scopes: []string{auth.PermDiaperWrite}}, http.StatusForbidden},
+		{"parent token without scopes", &model.User{Role: auth.RoleParent, Scopes: []string{}}, http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/growth", nil)
+			if tc.user != nil {
+				req = req.WithContext(auth.WithUser(req.Context(), tc.user))
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tc.want {
+				t.Errorf("status = %d, want %d", w.Code, tc.want)
+			}
+		})
+	}
+}
+
 func TestLogger_PassesThrough(t *testing.T) {
 	called := false
 	handler := middleware.Logger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,19 @@
+package model
+
+// Attachment is an uploaded file linked to a log entry, or to the child
+// itself when LogKind is "child". The file content is kept in the blob store
+// under SHA256.
+type Attachment struct {
+	ID              string `json:"id"`
+	ChildID         string `json:"child_id"`
+	LogKind         string `json:"log_kind"`
+	LogID           string `json:"log_id"`
+	Filename        string `json:"filename"`
+	ContentType     string `json:"content_type"`
+	SizeBytes       int64  `json:"size_bytes"`
+	SHA256          string `json:"sha256"`
+	ThumbnailSHA256 string `json:"-"`
+	HasThumbnail    bool   `json:"has_thumbnail"`
+	CreatedAt       string `json:"created_at"`
+	CreatedBy       string `json:"created_by,omitempty"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "encoding/json"
+
+// AuditEntry records one mutation. Before is null for creations and After
+// is null for deletions.
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	ActorID   string          `json:"actor_id,omitempty"`
+	ActorName string          `json:"actor_name,omitempty"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  string          `json:"entity_id"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	CreatedAt string          `json:"created_at"`
+}
@@ -3,11 +3,38 @@ package model
 type FeedingLog struct {
 	ID              string  `json:"id"`
 	ChildID         string  `json:"child_id"`
-	FeedType        string  `json:"feed_type"`
+	FeedType        string  `json:"feed_type"` // breast feeds: the side they started on
 	StartTime       string  `json:"start_time"`
 	EndTime         *string `json:"end_time"`
 	DurationMinutes *int    `json:"duration_minutes"`
 	QuantityML      *int    `json:"quantity_ml,omitempty"`
+	MilkType        string  `json:"milk_type,omitempty"` // bottle only: breast_milk or formula
 	Notes           string  `json:"notes,omitempty"`
 	CreatedAt       string  `json:"created_at"`
+	CreatedBy       string  `json:"created_by,omitempty"`
+	DeletedAt       *string `json:"deleted_at,omitempty"`
+
+	// Breast milk bottles only: the milk drawn from each bag of the stash,
+	// oldest bag first. Deleting the feed puts it back.
+	MilkUsed []MilkUse `json:"milk_used,omitempty"`
+
+	// Pauses of a breast feed timer, oldest first; DurationMinutes excludes
+	// them. PausedAt is set while the timer is paused.
+	Pauses   []TimerPause `json:"pauses,omitempty"`
+	PausedAt *string      `json:"paused_at,omitempty"`
+
+	// Breast feeds only: the sides fed in order, and the minutes of the
+	// finished segments on each side.
+	Segments     []FeedingSegment `json:"segments,omitempty"`
+	LeftMinutes  *int             `json:"left_minutes,omitempty"`
+	RightMinutes *int             `json:"right_minutes,omitempty"`
+}
+
+// FeedingSegment is a stretch of a breast feed on one side. The last segment
+// of an ongoing feed has no end.
+type FeedingSegment struct {
+	Side            string  `json:"side"` // left or right
+	StartTime       string  `json:"start_time"`
+	EndTime         *string `json:"end_time"`
+	DurationMinutes *int    `json:"duration_minutes"`
 }
@@ -9,4 +9,6 @@ type GrowthLog struct {
 	HeadCircumferenceMM   *int   `json:"head_circumference_mm,omitempty"`
 	Notes                 string `json:"notes,omitempty"`
 	CreatedAt             string `json:"created_at"`
+	CreatedBy             string `json:"created_by,omitempty"`
+	DeletedAt             *string `json:"deleted_at,omitempty"`
 }
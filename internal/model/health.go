@@ -0,0 +1,30 @@
+package model
+
+// TemperatureLog is one temperature reading. Fever is derived from the
+// reading and the measurement method; it is not stored.
+type TemperatureLog struct {
+	ID        string  `json:"id"`
+	ChildID   string  `json:"child_id"`
+	TakenAt   string  `json:"taken_at"`
+	Celsius   float64 `json:"celsius"`
+	Method    string  `json:"method"`
+	Fever     bool    `json:"fever"`
+	Notes     string  `json:"notes,omitempty"`
+	CreatedAt string  `json:"created_at"`
+	CreatedBy string  `json:"created_by,omitempty"`
+	DeletedAt *string `json:"deleted_at,omitempty"`
+}
+
+// SymptomLog records symptoms observed together, as free tags such as
+// "cough" or "rash", with an overall severity.
+type SymptomLog struct {
+	ID         string   `json:"id"`
+	ChildID    string   `json:"child_id"`
+	ObservedAt string   `json:"observed_at"`
+	Symptoms   []string `json:"symptoms"`
+	Severity   string   `json:"severity"`
+	Notes      string   `json:"notes,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+	CreatedBy  string   `json:"created_by,omitempty"`
+	DeletedAt  *string  `json:"deleted_at,omitempty"`
+}
@@ -0,0 +1,11 @@
+package model
+
+type Invite struct {
+	ID         string  `json:"id"`
+	Role       string  `json:"role"`
+	CreatedBy  string  `json:"created_by"`
+	CreatedAt  string  `json:"created_at"`
+	ExpiresAt  string  `json:"expires_at"`
+	AcceptedBy *string `json:"accepted_by,omitempty"`
+	AcceptedAt *string `json:"accepted_at,omitempty"`
+}
@@ -0,0 +1,30 @@
+package model
+
+// Medication is a medicine or supplement given to a child, with the limits
+// that decide when the next dose may be given.
+type Medication struct {
+	ID                 string   `json:"id"`
+	ChildID            string   `json:"child_id"`
+	Name               string   `json:"name"`
+	DoseUnit           string   `json:"dose_unit"`
+	DefaultDose        *float64 `json:"default_dose"`
+	MinIntervalMinutes *int     `json:"min_interval_minutes"`
+	MaxDailyDoses      *int     `json:"max_daily_doses"` // per rolling 24 hours
+	Notes              string   `json:"notes,omitempty"`
+	CreatedAt          string   `json:"created_at"`
+	CreatedBy          string   `json:"created_by,omitempty"`
+	ArchivedAt         *string  `json:"archived_at,omitempty"`
+}
+
+// MedicationLog is one dose given.
+type MedicationLog struct {
+	ID           string   `json:"id"`
+	ChildID      string   `json:"child_id"`
+	MedicationID string   `json:"medication_id"`
+	GivenAt      string   `json:"given_at"`
+	Dose         *float64 `json:"dose"`
+	Notes        string   `json:"notes,omitempty"`
+	CreatedAt    string   `json:"created_at"`
+	CreatedBy    string   `json:"created_by,omitempty"`
+	DeletedAt    *string  `json:"deleted_at,omitempty"`
+}
@@ -0,0 +1,17 @@
+package model
+
+// Milestone is a developmental first recorded for a child. Code links it to
+// the milestone catalogue; custom milestones have no code.
+type Milestone struct {
+	ID         string  `json:"id"`
+	ChildID    string  `json:"child_id"`
+	Code       string  `json:"code,omitempty"`
+	Title      string  `json:"title"`
+	Category   string  `json:"category"`
+	AchievedOn string  `json:"achieved_on"`
+	PhotoURL   string  `json:"photo_url,omitempty"`
+	Notes      string  `json:"notes,omitempty"`
+	CreatedAt  string  `json:"created_at"`
+	CreatedBy  string  `json:"created_by,omitempty"`
+	DeletedAt  *string `json:"deleted_at,omitempty"`
+}
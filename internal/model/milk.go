@@ -0,0 +1,23 @@
+package model
+
+// MilkBag is a labelled bag of expressed breast milk in storage.
+type MilkBag struct {
+	ID          string  `json:"id"`
+	ChildID     string  `json:"child_id"`
+	PumpingID   *string `json:"pumping_id"`
+	VolumeML    int     `json:"volume_ml"`
+	RemainingML int     `json:"remaining_ml"`
+	Location    string  `json:"location"`
+	StoredAt    string  `json:"stored_at"`
+	ThawedAt    *string `json:"thawed_at,omitempty"`
+	ExpiresAt   string  `json:"expires_at"`
+	Notes       string  `json:"notes,omitempty"`
+	CreatedAt   string  `json:"created_at"`
+	CreatedBy   string  `json:"created_by,omitempty"`
+}
+
+// MilkUse is milk drawn from one bag for a bottle feed.
+type MilkUse struct {
+	BagID string `json:"bag_id"`
+	ML    int    `json:"ml"`
+}
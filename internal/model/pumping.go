@@ -0,0 +1,17 @@
+package model
+
+// PumpingLog is an expressing session. Volumes are recorded per breast and
+// are usually filled in when the session is stopped.
+type PumpingLog struct {
+	ID              string  `json:"id"`
+	ChildID         string  `json:"child_id"`
+	StartTime       string  `json:"start_time"`
+	EndTime         *string `json:"end_time"`
+	DurationMinutes *int    `json:"duration_minutes"`
+	LeftML          *int    `json:"left_ml"`
+	RightML         *int    `json:"right_ml"`
+	Notes           string  `json:"notes,omitempty"`
+	CreatedAt       string  `json:"created_at"`
+	CreatedBy       string  `json:"created_by,omitempty"`
+	DeletedAt       *string `json:"deleted_at,omitempty"`
+}
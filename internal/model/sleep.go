@@ -8,4 +8,11 @@ type SleepLog struct {
 	DurationMinutes *int    `json:"duration_minutes"`
 	Notes           string  `json:"notes,omitempty"`
 	CreatedAt       string  `json:"created_at"`
+	CreatedBy       string  `json:"created_by,omitempty"`
+	DeletedAt       *string `json:"deleted_at,omitempty"`
+
+	// Pauses of the timer, oldest first; DurationMinutes excludes them.
+	// PausedAt is set while the timer is paused.
+	Pauses   []TimerPause `json:"pauses,omitempty"`
+	PausedAt *string      `json:"paused_at,omitempty"`
 }
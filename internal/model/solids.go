@@ -0,0 +1,27 @@
+package model
+
+// Food is something a child can be fed as solids: a catalogue food or one
+// the household added. Allergens use the catalogue's allergen codes.
+type Food struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Category  string   `json:"category"`
+	Allergens []string `json:"allergens"`
+	Custom    bool     `json:"custom"`
+}
+
+// SolidFeedingLog is one serving of a food.
+type SolidFeedingLog struct {
+	ID            string  `json:"id"`
+	ChildID       string  `json:"child_id"`
+	FoodID        string  `json:"food_id"`
+	FoodName      string  `json:"food_name"`
+	FedAt         string  `json:"fed_at"`
+	AmountGrams   *int    `json:"amount_grams,omitempty"`
+	Reaction      string  `json:"reaction"` // none, mild, moderate or severe
+	ReactionNotes string  `json:"reaction_notes,omitempty"`
+	Notes         string  `json:"notes,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+	CreatedBy     string  `json:"created_by,omitempty"`
+	DeletedAt     *string `json:"deleted_at,omitempty"`
+}
@@ -0,0 +1,8 @@
+package model
+
+// TimerPause is a break in a sleep or breast feed timer, such as a brief
+// wake-up or burping. ResumedAt is nil while the timer is paused.
+type TimerPause struct {
+	PausedAt  string  `json:"paused_at"`
+	ResumedAt *string `json:"resumed_at"`
+}
@@ -0,0 +1,10 @@
+package model
+
+// TrashItem is a deleted log entry awaiting restore or purge. Entry holds the
+// full log of the given kind.
+type TrashItem struct {
+	Kind      string `json:"kind"`
+	ID        string `json:"id"`
+	DeletedAt string `json:"deleted_at"`
+	Entry     any    `json:"entry"`
+}
@@ -0,0 +1,21 @@
+package model
+
+type User struct {
+	ID        string `json:"id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at"`
+	// Scopes is set when the request authenticated with an API token and
+	// limits it to those permissions. It is nil for session logins.
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// APIToken is a long-lived credential for scripts and home automation. The
+// token itself is only returned once, on creation.
+type APIToken struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  string   `json:"created_at"`
+	LastUsedAt *string  `json:"last_used_at"`
+}
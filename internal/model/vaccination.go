@@ -0,0 +1,17 @@
+package model
+
+// VaccinationLog is one vaccine dose given. Vaccine is the schedule code
+// (e.g. "bcg") so the dose can be matched against the vaccination schedule.
+type VaccinationLog struct {
+	ID         string  `json:"id"`
+	ChildID    string  `json:"child_id"`
+	Vaccine    string  `json:"vaccine"`
+	DoseNumber int     `json:"dose_number"`
+	GivenOn    string  `json:"given_on"`
+	Clinic     string  `json:"clinic,omitempty"`
+	LotNumber  string  `json:"lot_number,omitempty"`
+	Notes      string  `json:"notes,omitempty"`
+	CreatedAt  string  `json:"created_at"`
+	CreatedBy  string  `json:"created_by,omitempty"`
+	DeletedAt  *string `json:"deleted_at,omitempty"`
+}
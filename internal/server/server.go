@@ -4,56 +4,228 @@ import (
 	"io/fs"
 	"net/http"
 
+	"baby-care/internal/auth"
+	"baby-care/internal/blob"
 	"baby-care/internal/handler"
 	"baby-care/internal/middleware"
 	"baby-care/internal/store"
 )
 
-func New(st *store.Store, staticFS fs.FS) http.Handler {
-	h := &handler.Handler{Store: st}
+// Options configures the HTTP server.
+type Options struct {
+	// AllowedOrigins lists the cross-origin callers allowed to use the API
+	// with credentials. The bundled frontend is same-origin and needs none.
+	AllowedOrigins []string
+	// Blobs stores attachment files. It defaults to a directory next to the
+	// database file.
+	Blobs blob.Store
+}
+
+func New(st *store.Store, staticFS fs.FS, opts Options) http.Handler {
+	if opts.Blobs == nil {
+		opts.Blobs = blob.NewDir(st.AttachmentDir())
+	}
+	h := &handler.Handler{Store: st, Blobs: opts.Blobs}
 	mux := http.NewServeMux()
 
+	// can guards a handler with a role permission.
+	can := func(perm string, fn http.HandlerFunc) http.Handler {
+		return middleware.Require(perm)(fn)
+	}
+
 	// Health check
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("OK"))
 	})
 
-	// Child API
-	mux.HandleFunc("GET /api/v1/child", h.GetChild)
-	mux.HandleFunc("POST /api/v1/child", h.CreateChild)
-	mux.HandleFunc("PUT /api/v1/child", h.UpdateChild)
-
-	// Sleep API
-	mux.HandleFunc("GET /api/v1/sleep", h.ListSleep)
-	mux.HandleFunc("POST /api/v1/sleep", h.CreateSleep)
-	mux.HandleFunc("GET /api/v1/sleep/active", h.GetActiveSleep)
-	mux.HandleFunc("PUT /api/v1/sleep/{logId}", h.UpdateSleep)
-	mux.HandleFunc("DELETE /api/v1/sleep/{logId}", h.DeleteSleep)
-
-	// Feeding API
-	mux.HandleFunc("GET /api/v1/feeding", h.ListFeeding)
-	mux.HandleFunc("POST /api/v1/feeding", h.CreateFeeding)
-	mux.HandleFunc("GET /api/v1/feeding/active", h.GetActiveFeeding)
-	mux.HandleFunc("PUT /api/v1/feeding/{logId}", h.UpdateFeeding)
-	mux.HandleFunc("DELETE /api/v1/feeding/{logId}", h.DeleteFeeding)
-
-	// Diaper API
-	mux.HandleFunc("GET /api/v1/diaper", h.ListDiaper)
-	mux.HandleFunc("POST /api/v1/diaper", h.CreateDiaper)
-	mux.HandleFunc("PUT /api/v1/diaper/{logId}", h.UpdateDiaper)
-	mux.HandleFunc("DELETE /api/v1/diaper/{logId}", h.DeleteDiaper)
-
-	// Growth API
-	mux.HandleFunc("GET /api/v1/growth", h.ListGrowth)
-	mux.HandleFunc("POST /api/v1/growth", h.CreateGrowth)
-	mux.HandleFunc("PUT /api/v1/growth/{logId}", h.UpdateGrowth)
-	mux.HandleFunc("DELETE /api/v1/growth/{logId}", h.DeleteGrowth)
-
-	// Summary API
-	mux.HandleFunc("GET /api/v1/summary", h.GetSummary)
-
-	// Analytics API
-	mux.HandleFunc("GET /api/v1/analytics", h.GetAnalytics)
+	// Auth API
+	mux.HandleFunc("POST /api/v1/auth/login", h.Login)
+	mux.HandleFunc("POST /api/v1/auth/accept-invite", h.AcceptInvite)
+	mux.HandleFunc("POST /api/v1/auth/logout", h.Logout)
+	mux.HandleFunc("GET /api/v1/auth/me", h.Me)
+
+	// API tokens of the logged-in user
+	mux.HandleFunc("GET /api/v1/tokens", h.ListTokens)
+	mux.HandleFunc("POST /api/v1/tokens", h.CreateToken)
+	mux.HandleFunc("DELETE /api/v1/tokens/{tokenId}", h.DeleteToken)
+
+	// Household API
+	mux.Handle("GET /api/v1/users", can(auth.PermHouseholdWrite, h.ListUsers))
+	mux.Handle("GET /api/v1/invites", can(auth.PermHouseholdWrite, h.ListInvites))
+	mux.Handle("POST /api/v1/invites", can(auth.PermHouseholdWrite, h.CreateInvite))
+	mux.Handle("DELETE /api/v1/invites/{inviteId}", can(auth.PermHouseholdWrite, h.DeleteInvite))
+
+	// Audit API
+	mux.Handle("GET /api/v1/audit", can(auth.PermAuditRead, h.ListAudit))
+
+	// Settings API
+	mux.Handle("GET /api/v1/settings", can(auth.PermSettingsRead, h.GetSettings))
+	mux.Handle("PUT /api/v1/settings", can(auth.PermSettingsWrite, h.UpdateSettings))
+
+	// Children API
+	mux.Handle("GET /api/v1/children", can(auth.PermChildRead, h.ListChildren))
+	mux.Handle("POST /api/v1/children", can(auth.PermChildWrite, h.CreateChild))
+	mux.Handle("GET /api/v1/children/{childId}", can(auth.PermChildRead, h.GetChild))
+	mux.Handle("PUT /api/v1/children/{childId}", can(auth.PermChildWrite, h.UpdateChild))
+
+	// Legacy single-child API, aliased to the first child
+	mux.Handle("GET /api/v1/child", can(auth.PermChildRead, h.GetChild))
+	mux.Handle("POST /api/v1/child", can(auth.PermChildWrite, h.CreateChild))
+	mux.Handle("PUT /api/v1/child", can(auth.PermChildWrite, h.UpdateChild))
+
+	// Per-child log routes are served both under /api/v1/children/{childId}
+	// and under the legacy /api/v1 prefix, which resolves to the first child.
+	for _, prefix := range []string{"/api/v1/children/{childId}", "/api/v1"} {
+		// Sleep API
+		mux.Handle("GET "+prefix+"/sleep", can(auth.PermSleepRead, h.ListSleep))
+		mux.Handle("POST "+prefix+"/sleep", can(auth.PermSleepWrite, h.CreateSleep))
+		mux.Handle("GET "+prefix+"/sleep/active", can(auth.PermSleepRead, h.GetActiveSleep))
+		mux.Handle("PUT "+prefix+"/sleep/{logId}", can(auth.PermSleepWrite, h.UpdateSleep))
+		mux.Handle("DELETE "+prefix+"/sleep/{logId}", can(auth.PermSleepWrite, h.DeleteSleep))
+		mux.Handle("POST "+prefix+"/sleep/{logId}/pause", can(auth.PermSleepWrite, h.PauseTimer("sleep")))
+		mux.Handle("POST "+prefix+"/sleep/{logId}/resume", can(auth.PermSleepWrite, h.ResumeTimer("sleep")))
+		mux.Handle("GET "+prefix+"/sleep/{logId}/history", can(auth.PermSleepRead, h.LogHistory("sleep")))
+		mux.Handle("POST "+prefix+"/sleep/{logId}/restore", can(auth.PermSleepWrite, h.RestoreLog("sleep")))
+
+		// Feeding API
+		mux.Handle("GET "+prefix+"/feeding", can(auth.PermFeedingRead, h.ListFeeding))
+		mux.Handle("POST "+prefix+"/feeding", can(auth.PermFeedingWrite, h.CreateFeeding))
+		mux.Handle("GET "+prefix+"/feeding/active", can(auth.PermFeedingRead, h.GetActiveFeeding))
+		mux.Handle("GET "+prefix+"/feeding/next-side", can(auth.PermFeedingRead, h.GetNextBreastSide))
+		mux.Handle("POST "+prefix+"/feeding/{logId}/switch", can(auth.PermFeedingWrite, h.SwitchFeedingSide))
+		mux.Handle("PUT "+prefix+"/feeding/{logId}", can(auth.PermFeedingWrite, h.UpdateFeeding))
+		mux.Handle("DELETE "+prefix+"/feeding/{logId}", can(auth.PermFeedingWrite, h.DeleteFeeding))
+		mux.Handle("POST "+prefix+"/feeding/{logId}/pause", can(auth.PermFeedingWrite, h.PauseTimer("feeding")))
+		mux.Handle("POST "+prefix+"/feeding/{logId}/resume", can(auth.PermFeedingWrite, h.ResumeTimer("feeding")))
+		mux.Handle("GET "+prefix+"/feeding/{logId}/history", can(auth.PermFeedingRead, h.LogHistory("feeding")))
+		mux.Handle("POST "+prefix+"/feeding/{logId}/restore", can(auth.PermFeedingWrite, h.RestoreLog("feeding")))
+
+		// Pumping API
+		mux.Handle("GET "+prefix+"/pumping", can(auth.PermPumpingRead, h.ListPumping))
+		mux.Handle("POST "+prefix+"/pumping", can(auth.PermPumpingWrite, h.CreatePumping))
+		mux.Handle("GET "+prefix+"/pumping/active", can(auth.PermPumpingRead, h.GetActivePumping))
+		mux.Handle("PUT "+prefix+"/pumping/{logId}", can(auth.PermPumpingWrite, h.UpdatePumping))
+		mux.Handle("DELETE "+prefix+"/pumping/{logId}", can(auth.PermPumpingWrite, h.DeletePumping))
+		mux.Handle("GET "+prefix+"/pumping/{logId}/history", can(auth.PermPumpingRead, h.LogHistory("pumping")))
+		mux.Handle("POST "+prefix+"/pumping/{logId}/restore", can(auth.PermPumpingWrite, h.RestoreLog("pumping")))
+
+		// Milk stash API
+		mux.Handle("GET "+prefix+"/milk-stash", can(auth.PermPumpingRead, h.GetMilkStash))
+		mux.Handle("POST "+prefix+"/milk-stash", can(auth.PermPumpingWrite, h.CreateMilkBag))
+		mux.Handle("PUT "+prefix+"/milk-stash/{bagId}", can(auth.PermPumpingWrite, h.UpdateMilkBag))
+		mux.Handle("DELETE "+prefix+"/milk-stash/{bagId}", can(auth.PermPumpingWrite, h.DeleteMilkBag))
+
+		// Diaper API
+		mux.Handle("GET "+prefix+"/diaper", can(auth.PermDiaperRead, h.ListDiaper))
+		mux.Handle("POST "+prefix+"/diaper", can(auth.PermDiaperWrite, h.CreateDiaper))
+		mux.Handle("PUT "+prefix+"/diaper/{logId}", can(auth.PermDiaperWrite, h.UpdateDiaper))
+		mux.Handle("DELETE "+prefix+"/diaper/{logId}", can(auth.PermDiaperWrite, h.DeleteDiaper))
+		mux.Handle("GET "+prefix+"/diaper/{logId}/history", can(auth.PermDiaperRead, h.LogHistory("diaper")))
+		mux.Handle("POST "+prefix+"/diaper/{logId}/restore", can(auth.PermDiaperWrite, h.RestoreLog("diaper")))
+
+		// Growth API
+		mux.Handle("GET "+prefix+"/growth", can(auth.PermGrowthRead, h.ListGrowth))
+		mux.Handle("POST "+prefix+"/growth", can(auth.PermGrowthWrite, h.CreateGrowth))
+		mux.Handle("GET "+prefix+"/growth/curves", can(auth.PermGrowthRead, h.GetGrowthCurves))
+		mux.Handle("PUT "+prefix+"/growth/{logId}", can(auth.PermGrowthWrite, h.UpdateGrowth))
+		mux.Handle("DELETE "+prefix+"/growth/{logId}", can(auth.PermGrowthWrite, h.DeleteGrowth))
+		mux.Handle("GET "+prefix+"/growth/{logId}/history", can(auth.PermGrowthRead, h.LogHistory("growth")))
+		mux.Handle("POST "+prefix+"/growth/{logId}/restore", can(auth.PermGrowthWrite, h.RestoreLog("growth")))
+
+		// Medication API
+		mux.Handle("GET "+prefix+"/medications", can(auth.PermHealthRead, h.ListMedications))
+		mux.Handle("POST "+prefix+"/medications", can(auth.PermHealthWrite, h.CreateMedication))
+		mux.Handle("GET "+prefix+"/medications/{medId}", can(auth.PermHealthRead, h.GetMedication))
+		mux.Handle("PUT "+prefix+"/medications/{medId}", can(auth.PermHealthWrite, h.UpdateMedication))
+		mux.Handle("DELETE "+prefix+"/medications/{medId}", can(auth.PermHealthWrite, h.DeleteMedication))
+		mux.Handle("GET "+prefix+"/medications/{medId}/can-give", can(auth.PermHealthRead, h.CanGiveMedication))
+		mux.Handle("GET "+prefix+"/medication-logs", can(auth.PermHealthRead, h.ListMedicationLogs))
+		mux.Handle("POST "+prefix+"/medication-logs", can(auth.PermHealthWrite, h.CreateMedicationLog))
+		mux.Handle("PUT "+prefix+"/medication-logs/{logId}", can(auth.PermHealthWrite, h.UpdateMedicationLog))
+		mux.Handle("DELETE "+prefix+"/medication-logs/{logId}", can(auth.PermHealthWrite, h.DeleteMedicationLog))
+		mux.Handle("GET "+prefix+"/medication-logs/{logId}/history", can(auth.PermHealthRead, h.LogHistory("medication_log")))
+		mux.Handle("POST "+prefix+"/medication-logs/{logId}/restore", can(auth.PermHealthWrite, h.RestoreLog("medication_log")))
+
+		// Temperature & symptom API
+		mux.Handle("GET "+prefix+"/temperature", can(auth.PermHealthRead, h.ListTemperatures))
+		mux.Handle("POST "+prefix+"/temperature", can(auth.PermHealthWrite, h.CreateTemperature))
+		mux.Handle("PUT "+prefix+"/temperature/{logId}", can(auth.PermHealthWrite, h.UpdateTemperature))
+		mux.Handle("DELETE "+prefix+"/temperature/{logId}", can(auth.PermHealthWrite, h.DeleteTemperature))
+		mux.Handle("GET "+prefix+"/temperature/{logId}/history", can(auth.PermHealthRead, h.LogHistory("temperature")))
+		mux.Handle("POST "+prefix+"/temperature/{logId}/restore", can(auth.PermHealthWrite, h.RestoreLog("temperature")))
+		mux.Handle("GET "+prefix+"/symptoms", can(auth.PermHealthRead, h.ListSymptoms))
+		mux.Handle("POST "+prefix+"/symptoms", can(auth.PermHealthWrite, h.CreateSymptom))
+		mux.Handle("PUT "+prefix+"/symptoms/{logId}", can(auth.PermHealthWrite, h.UpdateSymptom))
+		mux.Handle("DELETE "+prefix+"/symptoms/{logId}", can(auth.PermHealthWrite, h.DeleteSymptom))
+		mux.Handle("GET "+prefix+"/symptoms/{logId}/history", can(auth.PermHealthRead, h.LogHistory("symptom")))
+		mux.Handle("POST "+prefix+"/symptoms/{logId}/restore", can(auth.PermHealthWrite, h.RestoreLog("symptom")))
+		mux.Handle("GET "+prefix+"/sick-episodes", can(auth.PermHealthRead, h.ListSickEpisodes))
+
+		// Vaccination API
+		mux.Handle("GET "+prefix+"/vaccinations", can(auth.PermHealthRead, h.ListVaccinations))
+		mux.Handle("POST "+prefix+"/vaccinations", can(auth.PermHealthWrite, h.CreateVaccination))
+		mux.Handle("GET "+prefix+"/vaccinations/due", can(auth.PermHealthRead, h.GetVaccinationsDue))
+		mux.Handle("GET "+prefix+"/vaccinations/schedule", can(auth.PermHealthRead, h.GetVaccinationSchedule))
+		mux.Handle("PUT "+prefix+"/vaccinations/{logId}", can(auth.PermHealthWrite, h.UpdateVaccination))
+		mux.Handle("DELETE "+prefix+"/vaccinations/{logId}", can(auth.PermHealthWrite, h.DeleteVaccination))
+		mux.Handle("GET "+prefix+"/vaccinations/{logId}/history", can(auth.PermHealthRead, h.LogHistory("vaccination")))
+		mux.Handle("POST "+prefix+"/vaccinations/{logId}/restore", can(auth.PermHealthWrite, h.RestoreLog("vaccination")))
+
+		// Milestone API
+		mux.Handle("GET "+prefix+"/milestones", can(auth.PermGrowthRead, h.ListMilestones))
+		mux.Handle("POST "+prefix+"/milestones", can(auth.PermGrowthWrite, h.CreateMilestone))
+		mux.Handle("GET "+prefix+"/milestones/catalog", can(auth.PermGrowthRead, h.GetMilestoneCatalog))
+		mux.Handle("GET "+prefix+"/milestones/expected", can(auth.PermGrowthRead, h.GetExpectedMilestones))
+		mux.Handle("PUT "+prefix+"/milestones/{logId}", can(auth.PermGrowthWrite, h.UpdateMilestone))
+		mux.Handle("DELETE "+prefix+"/milestones/{logId}", can(auth.PermGrowthWrite, h.DeleteMilestone))
+		mux.Handle("GET "+prefix+"/milestones/{logId}/history", can(auth.PermGrowthRead, h.LogHistory("milestone")))
+		mux.Handle("POST "+prefix+"/milestones/{logId}/restore", can(auth.PermGrowthWrite, h.RestoreLog("milestone")))
+
+		// Solid food API
+		mux.Handle("GET "+prefix+"/foods", can(auth.PermFeedingRead, h.ListFoods))
+		mux.Handle("POST "+prefix+"/foods", can(auth.PermFeedingWrite, h.CreateFood))
+		mux.Handle("GET "+prefix+"/solid-feedings", can(auth.PermFeedingRead, h.ListSolidFeedings))
+		mux.Handle("POST "+prefix+"/solid-feedings", can(auth.PermFeedingWrite, h.CreateSolidFeeding))
+		mux.Handle("GET "+prefix+"/solid-feedings/introductions", can(auth.PermFeedingRead, h.GetFoodIntroductions))
+		mux.Handle("PUT "+prefix+"/solid-feedings/{logId}", can(auth.PermFeedingWrite, h.UpdateSolidFeeding))
+		mux.Handle("DELETE "+prefix+"/solid-feedings/{logId}", can(auth.PermFeedingWrite, h.DeleteSolidFeeding))
+		mux.Handle("GET "+prefix+"/solid-feedings/{logId}/history", can(auth.PermFeedingRead, h.LogHistory("solid_feeding")))
+		mux.Handle("POST "+prefix+"/solid-feedings/{logId}/restore", can(auth.PermFeedingWrite, h.RestoreLog("solid_feeding")))
+
+		// Attachment API: files about the child, and files on any log entry.
+		mux.Handle("GET "+prefix+"/attachments", can(auth.PermChildRead, h.ListAttachments(store.AttachChild)))
+		mux.Handle("POST "+prefix+"/attachments", can(auth.PermChildWrite, h.UploadAttachment(store.AttachChild)))
+		mux.HandleFunc("GET "+prefix+"/attachments/{attId}/file", h.GetAttachmentFile)
+		mux.HandleFunc("GET "+prefix+"/attachments/{attId}/thumbnail", h.GetAttachmentThumbnail)
+		mux.HandleFunc("DELETE "+prefix+"/attachments/{attId}", h.DeleteAttachment)
+		for _, l := range []struct{ kind, path, read, write string }{
+			{"sleep", "sleep", auth.PermSleepRead, auth.PermSleepWrite},
+			{"feeding", "feeding", auth.PermFeedingRead, auth.PermFeedingWrite},
+			{"pumping", "pumping", auth.PermPumpingRead, auth.PermPumpingWrite},
+			{"diaper", "diaper", auth.PermDiaperRead, auth.PermDiaperWrite},
+			{"growth", "growth", auth.PermGrowthRead, auth.PermGrowthWrite},
+			{"medication_log", "medication-logs", auth.PermHealthRead, auth.PermHealthWrite},
+			{"temperature", "temperature", auth.PermHealthRead, auth.PermHealthWrite},
+			{"symptom", "symptoms", auth.PermHealthRead, auth.PermHealthWrite},
+			{"vaccination", "vaccinations", auth.PermHealthRead, auth.PermHealthWrite},
+			{"milestone", "milestones", auth.PermGrowthRead, auth.PermGrowthWrite},
+			{"solid_feeding", "solid-feedings", auth.PermFeedingRead, auth.PermFeedingWrite},
+		} {
+			mux.Handle("GET "+prefix+"/"+l.path+"/{logId}/attachments", can(l.read, h.ListAttachments(l.kind)))
+			mux.Handle("POST "+prefix+"/"+l.path+"/{logId}/attachments", can(l.write, h.UploadAttachment(l.kind)))
+		}
+
+		// Trash API
+		mux.HandleFunc("GET "+prefix+"/trash", h.ListTrash)
+
+		// Summary API
+		mux.Handle("GET "+prefix+"/summary", can(auth.PermSummaryRead, h.GetSummary))
+		mux.Handle("GET "+prefix+"/predictions", can(auth.PermSummaryRead, h.GetPredictions))
+
+		// Analytics API
+		mux.Handle("GET "+prefix+"/analytics", can(auth.PermAnalyticsRead, h.GetAnalytics))
+		mux.Handle("GET "+prefix+"/analytics/sleep", can(auth.PermAnalyticsRead, h.GetSleepChecks))
+	}
 
 	// Static file server with SPA fallback
 	static := http.FileServer(http.FS(staticFS))
@@ -74,5 +246,9 @@ func New(st *store.Store, staticFS fs.FS) http.Handler {
 		static.ServeHTTP(w, r)
 	})
 
-	return middleware.Chain(mux, middleware.Logger, middleware.CORS)
+	return middleware.Chain(mux,
+		middleware.Logger,
+		middleware.CORS(opts.AllowedOrigins...),
+		middleware.Auth(h.Authenticate, "/api/v1/auth/login", "/api/v1/auth/accept-invite"),
+	)
 }
@@ -7,115 +7,217 @@ import (
 
 // DayStats holds aggregated data for a single day.
 type DayStats struct {
-	Date            string `json:"date"`
-	SleepMinutes    int    `json:"sleep_minutes"`
-	SleepCount      int    `json:"sleep_count"`
-	FeedingCount    int    `json:"feeding_count"`
-	BreastFeedCount int    `json:"breast_feed_count"`
-	BottleFeedCount int    `json:"bottle_feed_count"`
-	BottleMLTotal   int    `json:"bottle_ml_total"`
-	DiaperCount     int    `json:"diaper_count"`
-	WetCount        int    `json:"wet_count"`
-	DirtyCount      int    `json:"dirty_count"`
+	Date              string      `json:"date"`
+	SleepMinutes      int         `json:"sleep_minutes"` // day sleep plus the night that follows
+	SleepCount        int         `json:"sleep_count"`
+	DaySleepMinutes   int         `json:"day_sleep_minutes"`
+	NightSleepMinutes int         `json:"night_sleep_minutes"`
+	Night             *NightStats `json:"night,omitempty"`      // the night starting this evening
+	NapCount          int         `json:"nap_count"`            // sleeps started outside the night
+	LongestWakeMin    int         `json:"longest_wake_minutes"` // between sleeps, by the sleep day the child woke
+	FeedingCount      int         `json:"feeding_count"`
+	BreastFeedCount   int         `json:"breast_feed_count"`
+	BottleFeedCount   int         `json:"bottle_feed_count"`
+	BottleMLTotal     int         `json:"bottle_ml_total"`
+	SolidFeedCount    int         `json:"solid_feed_count"`
+	PumpingCount      int         `json:"pumping_count"`
+	PumpedMLTotal     int         `json:"pumped_ml_total"`
+	DiaperCount       int         `json:"diaper_count"`
+	WetCount          int         `json:"wet_count"`
+	DirtyCount        int         `json:"dirty_count"`
 }
 
-// GetAnalytics returns per-day stats for the child in the [from, to] date range.
+// GetAnalytics returns per-day stats for the child in the [from, to] date
+// range. Events are bucketed by their calendar date in the household timezone,
+// except sleep, which is bucketed by sleep day: see nightWindow.sleepDay.
 func (s *Store) GetAnalytics(childID, from, to string) ([]DayStats, error) {
+	start, end, err := s.rangeBounds(from, to)
+	if err != nil {
+		return nil, err
+	}
 	stats := map[string]*DayStats{}
+	on := func(date string) *DayStats {
+		if stats[date] == nil {
+			stats[date] = &DayStats{Date: date}
+		}
+		return stats[date]
+	}
+	day := func(ts string) *DayStats { return on(s.localDate(ts)) }
 
-	// Sleep aggregation
+	// Sleep aggregation, by sleep day: night sleep counts towards the
+	// evening the night began, so the night after `to` is read in full and
+	// the early hours of `from` belong to the day before.
+	night, err := s.nightWindow()
+	if err != nil {
+		return nil, err
+	}
 	sleepRows, err := s.db.Query(`
-		SELECT substr(start_time,1,10) as day,
-		       COALESCE(SUM(duration_minutes),0),
-		       COUNT(*)
+		SELECT id, start_time, end_time, COALESCE(duration_minutes,0)
 		FROM sleep_logs
 		WHERE child_id=?
-		  AND substr(start_time,1,10) BETWEEN ? AND ?
+		  AND unixepoch(start_time) >= ? AND unixepoch(start_time) < ?
 		  AND end_time IS NOT NULL
-		GROUP BY day
-		ORDER BY day ASC`, childID, from, to)
+		  AND deleted_at IS NULL
+		ORDER BY unixepoch(start_time)`, childID, start, end+24*60*60)
 	if err != nil {
 		return nil, fmt.Errorf("analytics sleep: %w", err)
 	}
 	defer sleepRows.Close()
+	type sleep struct {
+		id         string
+		start, end time.Time
+		mins       int
+	}
+	var sleeps []sleep
+	var ids []string
 	for sleepRows.Next() {
-		var day string
-		var mins, count int
-		if err := sleepRows.Scan(&day, &mins, &count); err != nil {
+		var id, startTime, endTime string
+		var mins int
+		if err := sleepRows.Scan(&id, &startTime, &endTime, &mins); err != nil {
 			return nil, err
 		}
-		stats[day] = &DayStats{Date: day, SleepMinutes: mins, SleepCount: count}
+		from, err1 := time.Parse(time.RFC3339, startTime)
+		to, err2 := time.Parse(time.RFC3339, endTime)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		sleeps = append(sleeps, sleep{id, from.In(s.Location()), to.In(s.Location()), mins})
+		ids = append(ids, id)
 	}
 	if err := sleepRows.Err(); err != nil {
 		return nil, err
 	}
+	pauses, err := s.loadPauses("sleep", ids...)
+	if err != nil {
+		return nil, err
+	}
+	var awake time.Time
+	for _, sl := range sleeps {
+		date, atNight := night.sleepDay(sl.start)
+		d := on(date)
+		d.SleepMinutes += sl.mins
+		d.SleepCount++
+		if atNight {
+			d.NightSleepMinutes += sl.mins
+			if d.Night == nil {
+				d.Night = &NightStats{}
+			}
+			d.Night.addNightSleep(sl.start, sl.end, pauses[sl.id])
+		} else {
+			d.DaySleepMinutes += sl.mins
+			d.NapCount++
+		}
+		if gap := sl.start.Sub(awake); !awake.IsZero() && gap > 0 && gap <= maxLogGap {
+			wokeOn, _ := night.sleepDay(awake)
+			w := on(wokeOn)
+			w.LongestWakeMin = max(w.LongestWakeMin, int(gap.Minutes()))
+		}
+		if sl.end.After(awake) {
+			awake = sl.end
+		}
+	}
 
 	// Feeding aggregation
 	feedRows, err := s.db.Query(`
-		SELECT substr(start_time,1,10) as day,
-		       feed_type,
-		       COUNT(*),
-		       COALESCE(SUM(quantity_ml),0)
+		SELECT start_time, feed_type, COALESCE(quantity_ml,0)
 		FROM feeding_logs
 		WHERE child_id=?
-		  AND substr(start_time,1,10) BETWEEN ? AND ?
-		GROUP BY day, feed_type
-		ORDER BY day ASC`, childID, from, to)
+		  AND unixepoch(start_time) >= ? AND unixepoch(start_time) < ?
+		  AND deleted_at IS NULL`, childID, start, end)
 	if err != nil {
 		return nil, fmt.Errorf("analytics feeding: %w", err)
 	}
 	defer feedRows.Close()
 	for feedRows.Next() {
-		var day, feedType string
-		var count, ml int
-		if err := feedRows.Scan(&day, &feedType, &count, &ml); err != nil {
+		var startTime, feedType string
+		var ml int
+		if err := feedRows.Scan(&startTime, &feedType, &ml); err != nil {
 			return nil, err
 		}
-		if stats[day] == nil {
-			stats[day] = &DayStats{Date: day}
-		}
-		d := stats[day]
-		d.FeedingCount += count
+		d := day(startTime)
+		d.FeedingCount++
 		if feedType == "bottle" {
-			d.BottleFeedCount += count
+			d.BottleFeedCount++
 			d.BottleMLTotal += ml
 		} else {
-			d.BreastFeedCount += count
+			d.BreastFeedCount++
 		}
 	}
 	if err := feedRows.Err(); err != nil {
 		return nil, err
 	}
 
+	// Solid feeds count as feedings too
+	solidRows, err := s.db.Query(`
+		SELECT fed_at
+		FROM solid_feeding_logs
+		WHERE child_id=?
+		  AND unixepoch(fed_at) >= ? AND unixepoch(fed_at) < ?
+		  AND deleted_at IS NULL`, childID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("analytics solid feeding: %w", err)
+	}
+	defer solidRows.Close()
+	for solidRows.Next() {
+		var fedAt string
+		if err := solidRows.Scan(&fedAt); err != nil {
+			return nil, err
+		}
+		d := day(fedAt)
+		d.FeedingCount++
+		d.SolidFeedCount++
+	}
+	if err := solidRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Pumping aggregation
+	pumpRows, err := s.db.Query(`
+		SELECT start_time, COALESCE(left_ml,0) + COALESCE(right_ml,0)
+		FROM pumping_logs
+		WHERE child_id=?
+		  AND unixepoch(start_time) >= ? AND unixepoch(start_time) < ?
+		  AND deleted_at IS NULL`, childID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("analytics pumping: %w", err)
+	}
+	defer pumpRows.Close()
+	for pumpRows.Next() {
+		var startTime string
+		var ml int
+		if err := pumpRows.Scan(&startTime, &ml); err != nil {
+			return nil, err
+		}
+		d := day(startTime)
+		d.PumpingCount++
+		d.PumpedMLTotal += ml
+	}
+	if err := pumpRows.Err(); err != nil {
+		return nil, err
+	}
+
 	// Diaper aggregation
 	diaperRows, err := s.db.Query(`
-		SELECT substr(changed_at,1,10) as day,
-		       diaper_type,
-		       COUNT(*)
+		SELECT changed_at, diaper_type
 		FROM diaper_logs
 		WHERE child_id=?
-		  AND substr(changed_at,1,10) BETWEEN ? AND ?
-		GROUP BY day, diaper_type
-		ORDER BY day ASC`, childID, from, to)
+		  AND unixepoch(changed_at) >= ? AND unixepoch(changed_at) < ?
+		  AND deleted_at IS NULL`, childID, start, end)
 	if err != nil {
 		return nil, fmt.Errorf("analytics diaper: %w", err)
 	}
 	defer diaperRows.Close()
 	for diaperRows.Next() {
-		var day, dType string
-		var count int
-		if err := diaperRows.Scan(&day, &dType, &count); err != nil {
+		var changedAt, dType string
+		if err := diaperRows.Scan(&changedAt, &dType); err != nil {
 			return nil, err
 		}
-		if stats[day] == nil {
-			stats[day] = &DayStats{Date: day}
-		}
-		d := stats[day]
-		d.DiaperCount += count
+		d := day(changedAt)
+		d.DiaperCount++
 		if dType == "wet" {
-			d.WetCount += count
+			d.WetCount++
 		} else if dType == "dirty" {
-			d.DirtyCount += count
+			d.DirtyCount++
 		}
 	}
 	if err := diaperRows.Err(); err != nil {
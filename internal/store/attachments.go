@@ -0,0 +1,141 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"baby-care/internal/model"
+	"github.com/google/uuid"
+)
+
+// AttachChild is the attachment kind for files about the child itself, such
+// as a profile photo or a scan of the growth booklet.
+const AttachChild = "child"
+
+const attachmentColumns = `id, child_id, log_kind, log_id, filename, content_type, size_bytes, sha256, COALESCE(thumbnail_sha256,''), created_at, COALESCE(created_by,'')`
+
+// CreateAttachment records a stored file against a log entry of kind, or
+// against the child when kind is AttachChild. thumbHash may be empty.
+func (s *Store) CreateAttachment(childID, kind, logID, filename, contentType, hash, thumbHash string, size int64) (*model.Attachment, error) {
+	a := &model.Attachment{
+		ID:              uuid.NewString(),
+		ChildID:         childID,
+		LogKind:         kind,
+		LogID:           logID,
+		Filename:        filename,
+		ContentType:     contentType,
+		SizeBytes:       size,
+		SHA256:          hash,
+		ThumbnailSHA256: thumbHash,
+		HasThumbnail:    thumbHash != "",
+		CreatedAt:       s.nowLocal(),
+		CreatedBy:       s.actor,
+	}
+	err := s.inTx(func(tx *Store) error {
+		if _, err := tx.db.Exec(
+			`INSERT INTO attachments (id, child_id, log_kind, log_id, filename, content_type, size_bytes, sha256, thumbnail_sha256, created_at, created_by) VALUES (?,?,?,?,?,?,?,?,NULLIF(?,''),?,?)`,
+			a.ID, a.ChildID, a.LogKind, a.LogID, a.Filename, a.ContentType, a.SizeBytes, a.SHA256, a.ThumbnailSHA256, a.CreatedAt, tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert attachment: %w", err)
+		}
+		return tx.audit(AuditCreate, "attachment", a.ID, nil, a)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// ListAttachments returns the files attached to one log entry (or to the
+// child, for AttachChild), oldest first.
+func (s *Store) ListAttachments(kind, logID string) ([]*model.Attachment, error) {
+	rows, err := s.db.Query(
+		`SELECT `+attachmentColumns+` FROM attachments WHERE log_kind=? AND log_id=? ORDER BY created_at ASC`,
+		kind, logID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query attachments: %w", err)
+	}
+	defer rows.Close()
+	var list []*model.Attachment
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+func (s *Store) GetAttachment(id string) (*model.Attachment, error) {
+	a, err := scanAttachment(s.db.QueryRow(`SELECT `+attachmentColumns+` FROM attachments WHERE id=?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return a, err
+}
+
+// DeleteAttachment removes an attachment for good. The caller deletes the
+// blobs once BlobInUse reports they are no longer referenced.
+func (s *Store) DeleteAttachment(id string) error {
+	return s.inTx(func(tx *Store) error {
+		before, err := tx.GetAttachment(id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.db.Exec(`DELETE FROM attachments WHERE id=?`, id); err != nil {
+			return fmt.Errorf("delete attachment: %w", err)
+		}
+		return tx.audit(AuditDelete, "attachment", id, before, nil)
+	})
+}
+
+// BlobInUse reports whether any attachment still refers to hash, as its file
+// or its thumbnail.
+func (s *Store) BlobInUse(hash string) (bool, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT COUNT(*) FROM attachments WHERE sha256=? OR thumbnail_sha256=?`, hash, hash).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("check blob: %w", err)
+	}
+	return n > 0, nil
+}
+
+// AttachmentHashes returns the hashes of every file and thumbnail still
+// referenced by an attachment, for sweeping unreferenced blobs.
+func (s *Store) AttachmentHashes() (map[string]bool, error) {
+	rows, err := s.db.Query(`SELECT sha256 FROM attachments UNION SELECT thumbnail_sha256 FROM attachments WHERE thumbnail_sha256 IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("query attachment hashes: %w", err)
+	}
+	defer rows.Close()
+	hashes := map[string]bool{}
+	for rows.Next() {
+		var h string
+		if err := rows.Scan(&h); err != nil {
+			return nil, err
+		}
+		hashes[h] = true
+	}
+	return hashes, rows.Err()
+}
+
+// deleteLogAttachments removes the attachment rows of a purged log entry.
+// Their blobs are left for the blob store's sweep.
+func (s *Store) deleteLogAttachments(db execer, kind, logID string) error {
+	if _, err := db.Exec(`DELETE FROM attachments WHERE log_kind=? AND log_id=?`, kind, logID); err != nil {
+		return fmt.Errorf("delete %s attachments: %w", kind, err)
+	}
+	return nil
+}
+
+func scanAttachment(row rowScanner) (*model.Attachment, error) {
+	var a model.Attachment
+	if err := row.Scan(&a.ID, &a.ChildID, &a.LogKind, &a.LogID, &a.Filename, &a.ContentType, &a.SizeBytes, &a.SHA256, &a.ThumbnailSHA256, &a.CreatedAt, &a.CreatedBy); err != nil {
+		return nil, err
+	}
+	a.HasThumbnail = a.ThumbnailSHA256 != ""
+	return &a, nil
+}
@@ -0,0 +1,57 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+
+	"baby-care/internal/store"
+)
+
+func TestAttachments(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	diaper, err := st.CreateDiaper(childID, "dirty", "2024-07-01T08:00:00+07:00", "")
+	if err != nil {
+		t.Fatalf("CreateDiaper: %v", err)
+	}
+
+	photo, err := st.CreateAttachment(childID, "diaper", diaper.ID, "rash.jpg", "image/jpeg", "aaa", "bbb", 2048)
+	if err != nil {
+		t.Fatalf("CreateAttachment: %v", err)
+	}
+	if !photo.HasThumbnail {
+		t.Error("HasThumbnail = false, want true")
+	}
+	if _, err := st.CreateAttachment(childID, store.AttachChild, childID, "birth.pdf", "application/pdf", "ccc", "", 512); err != nil {
+		t.Fatalf("CreateAttachment child: %v", err)
+	}
+
+	list, err := st.ListAttachments("diaper", diaper.ID)
+	if err != nil || len(list) != 1 || list[0].ID != photo.ID || list[0].ThumbnailSHA256 != "bbb" {
+		t.Fatalf("ListAttachments = %+v, %v; want the photo", list, err)
+	}
+	if inUse, _ := st.BlobInUse("bbb"); !inUse {
+		t.Error("thumbnail blob not reported in use")
+	}
+	hashes, err := st.AttachmentHashes()
+	if err != nil || len(hashes) != 3 {
+		t.Errorf("AttachmentHashes = %v, %v; want 3", hashes, err)
+	}
+
+	// Purging the diaper change drops its attachment but not the child's.
+	if err := st.DeleteDiaper(diaper.ID); err != nil {
+		t.Fatalf("DeleteDiaper: %v", err)
+	}
+	if _, err := st.PurgeTrash(0); err != nil {
+		t.Fatalf("PurgeTrash: %v", err)
+	}
+	if _, err := st.GetAttachment(photo.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("GetAttachment after purge error = %v, want ErrNotFound", err)
+	}
+	if inUse, _ := st.BlobInUse("aaa"); inUse {
+		t.Error("purged attachment blob still in use")
+	}
+	if list, _ := st.ListAttachments(store.AttachChild, childID); len(list) != 1 {
+		t.Errorf("child attachments = %d, want 1", len(list))
+	}
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"baby-care/internal/model"
+)
+
+// Audit actions.
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+	AuditPurge   = "purge"
+)
+
+const auditColumns = `a.id, COALESCE(a.actor_id,''), COALESCE(u.username,''), a.action, a.entity, a.entity_id, a.before, a.after, a.created_at`
+
+// audit records a mutation of an entity by the store's actor. before and
+// after are stored as JSON; pass nil for the side where the row did not
+// exist. Call it in the transaction of the mutation (see inTx) so the two are
+// recorded together or not at all.
+func (s *Store) audit(action, entity, entityID string, before, after any) error {
+	b, err := auditJSON(before)
+	if err != nil {
+		return err
+	}
+	a, err := auditJSON(after)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(
+		`INSERT INTO audit_log (actor_id, action, entity, entity_id, before, after, created_at) VALUES (?,?,?,?,?,?,?)`,
+		s.actorID(), action, entity, entityID, b, a, s.nowLocal(),
+	)
+	if err != nil {
+		return fmt.Errorf("record audit %s %s: %w", action, entity, err)
+	}
+	return nil
+}
+
+func auditJSON(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal audit state: %w", err)
+	}
+	return string(b), nil
+}
+
+// GetLogHistory returns every recorded change to a log entry, oldest first.
+func (s *Store) GetLogHistory(kind, id string) ([]*model.AuditEntry, error) {
+	if _, ok := logTables[kind]; !ok {
+		return nil, fmt.Errorf("unknown log kind %q", kind)
+	}
+	rows, err := s.db.Query(
+		`SELECT `+auditColumns+` FROM audit_log a LEFT JOIN users u ON u.id = a.actor_id
+		 WHERE a.entity=? AND a.entity_id=? ORDER BY a.id ASC`,
+		kind, id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query history: %w", err)
+	}
+	defer rows.Close()
+	return scanAuditRows(rows)
+}
+
+// ListAudit returns up to limit entries, newest first, optionally only those
+// older than the entry with ID before and only for one entity type.
+func (s *Store) ListAudit(entity string, before int64, limit int) ([]*model.AuditEntry, error) {
+	query := `SELECT ` + auditColumns + ` FROM audit_log a LEFT JOIN users u ON u.id = a.actor_id WHERE 1=1`
+	var args []any
+	if entity != "" {
+		query += ` AND a.entity=?`
+		args = append(args, entity)
+	}
+	if before > 0 {
+		query += ` AND a.id < ?`
+		args = append(args, before)
+	}
+	query += ` ORDER BY a.id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query audit: %w", err)
+	}
+	defer rows.Close()
+	return scanAuditRows(rows)
+}
+
+func scanAuditRows(rows *sql.Rows) ([]*model.AuditEntry, error) {
+	var entries []*model.AuditEntry
+	for rows.Next() {
+		var e model.AuditEntry
+		var before, after sql.NullString
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.ActorName, &e.Action, &e.Entity, &e.EntityID, &before, &after, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Before = rawJSON(before)
+		e.After = rawJSON(after)
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+func rawJSON(s sql.NullString) json.RawMessage {
+	if !s.Valid {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(s.String)
+}
@@ -0,0 +1,127 @@
+package store_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"baby-care/internal/store"
+)
+
+func TestAudit_LogHistory(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	mom, _ := st.CreateUser("mom", "hash", "parent")
+	nanny, _ := st.CreateUser("lan", "hash", "nanny")
+
+	log, _, err := st.WithActor(mom.ID).CreateSleep(childID, "2024-01-15T13:00:00+07:00", "")
+	if err != nil {
+		t.Fatalf("CreateSleep: %v", err)
+	}
+	if _, err := st.WithActor(nanny.ID).UpdateSleep(log.ID, "", "2024-01-15T14:30:00+07:00", ""); err != nil {
+		t.Fatalf("UpdateSleep: %v", err)
+	}
+	if err := st.WithActor(mom.ID).DeleteSleep(log.ID); err != nil {
+		t.Fatalf("DeleteSleep: %v", err)
+	}
+
+	history, err := st.GetLogHistory("sleep", log.ID)
+	if err != nil {
+		t.Fatalf("GetLogHistory: %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("got %d history entries, want 3", len(history))
+	}
+	wantActions := []string{store.AuditCreate, store.AuditUpdate, store.AuditDelete}
+	wantActors := []string{"mom", "lan", "mom"}
+	for i, e := range history {
+		if e.Action != wantActions[i] || e.ActorName != wantActors[i] {
+			t.Errorf("entry %d = %s by %s, want %s by %s", i, e.Action, e.ActorName, wantActions[i], wantActors[i])
+		}
+	}
+
+	var before, after struct {
+		EndTime *string `json:"end_time"`
+	}
+	json.Unmarshal(history[1].Before, &before)
+	json.Unmarshal(history[1].After, &after)
+	if before.EndTime != nil || after.EndTime == nil || *after.EndTime != "2024-01-15T14:30:00+07:00" {
+		t.Errorf("update before/after = %s / %s", history[1].Before, history[1].After)
+	}
+	if string(history[0].Before) != "null" || string(history[2].After) != "null" {
+		t.Errorf("create before = %s, delete after = %s, want null", history[0].Before, history[2].After)
+	}
+}
+
+func TestAudit_ListPagination(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	for i := 0; i < 5; i++ {
+		if _, err := st.CreateDiaper(childID, "wet", "", ""); err != nil {
+			t.Fatalf("CreateDiaper: %v", err)
+		}
+	}
+
+	page1, err := st.ListAudit("diaper", 0, 3)
+	if err != nil {
+		t.Fatalf("ListAudit: %v", err)
+	}
+	if len(page1) != 3 || page1[0].ID < page1[2].ID {
+		t.Fatalf("page 1 = %d entries, want 3 newest first", len(page1))
+	}
+	page2, err := st.ListAudit("diaper", page1[2].ID, 3)
+	if err != nil {
+		t.Fatalf("ListAudit: %v", err)
+	}
+	if len(page2) != 2 || page2[0].ID >= page1[2].ID {
+		t.Errorf("page 2 = %d entries, want the 2 older ones", len(page2))
+	}
+
+	// The child creation is in the feed but not in the diaper filter.
+	all, _ := st.ListAudit("", 0, 100)
+	if len(all) != 6 || all[len(all)-1].Entity != "child" {
+		t.Errorf("unfiltered feed has %d entries, want 6 ending with the child", len(all))
+	}
+}
+
+func TestAudit_FailureRollsBackChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	st, err := store.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer st.Close()
+	childID := mustCreateChild(t, st)
+	diaper, _ := st.CreateDiaper(childID, "wet", "2024-01-15T08:00:00+07:00", "")
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TRIGGER audit_down BEFORE INSERT ON audit_log BEGIN SELECT RAISE(ABORT, 'audit down'); END`); err != nil {
+		t.Fatalf("create trigger: %v", err)
+	}
+
+	if _, err := st.CreateDiaper(childID, "dirty", "", ""); err == nil {
+		t.Error("CreateDiaper succeeded without its audit entry")
+	}
+	if _, err := st.UpdateDiaper(diaper.ID, "dirty", "", "changed"); err == nil {
+		t.Error("UpdateDiaper succeeded without its audit entry")
+	}
+	if err := st.DeleteDiaper(diaper.ID); err == nil {
+		t.Error("DeleteDiaper succeeded without its audit entry")
+	}
+	if err := st.SetTimezone("Europe/Berlin"); err == nil {
+		t.Error("SetTimezone succeeded without its audit entry")
+	}
+
+	logs, _ := st.GetDiaperLogs(childID, "")
+	if len(logs) != 1 || logs[0].DiaperType != "wet" || logs[0].Notes != "" {
+		t.Errorf("diapers after failed audits = %+v, want the original only", logs)
+	}
+	if settings, _ := st.GetSettings(); settings.Timezone != store.DefaultTimezone {
+		t.Errorf("Timezone = %q after a failed audit, want %q", settings.Timezone, store.DefaultTimezone)
+	}
+}
@@ -12,21 +12,42 @@ import (
 
 var ErrNotFound = errors.New("not found")
 
+const childColumns = `id, name, date_of_birth, gender, photo_url, notes, created_at, updated_at`
+
+// GetChild returns the first child created in the household. It backs the
+// legacy single-child /api/v1/child routes.
 func (s *Store) GetChild() (*model.Child, error) {
-	row := s.db.QueryRow(`SELECT id, name, date_of_birth, gender, photo_url, notes, created_at, updated_at FROM children LIMIT 1`)
-	var c model.Child
-	err := row.Scan(&c.ID, &c.Name, &c.DateOfBirth, &c.Gender, &c.PhotoURL, &c.Notes, &c.CreatedAt, &c.UpdatedAt)
-	if errors.Is(err, sql.ErrNoRows) {
-		return nil, ErrNotFound
-	}
+	row := s.db.QueryRow(`SELECT ` + childColumns + ` FROM children ORDER BY created_at ASC, rowid ASC LIMIT 1`)
+	return scanChildRow(row)
+}
+
+// GetChildByID returns the child with the given ID.
+func (s *Store) GetChildByID(id string) (*model.Child, error) {
+	row := s.db.QueryRow(`SELECT `+childColumns+` FROM children WHERE id=?`, id)
+	return scanChildRow(row)
+}
+
+// ListChildren returns every child in creation order.
+func (s *Store) ListChildren() ([]*model.Child, error) {
+	rows, err := s.db.Query(`SELECT ` + childColumns + ` FROM children ORDER BY created_at ASC, rowid ASC`)
 	if err != nil {
-		return nil, fmt.Errorf("scan child: %w", err)
+		return nil, fmt.Errorf("query children: %w", err)
 	}
-	return &c, nil
+	defer rows.Close()
+
+	var children []*model.Child
+	for rows.Next() {
+		var c model.Child
+		if err := rows.Scan(&c.ID, &c.Name, &c.DateOfBirth, &c.Gender, &c.PhotoURL, &c.Notes, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		children = append(children, &c)
+	}
+	return children, rows.Err()
 }
 
 func (s *Store) CreateChild(name, dob, gender, photoURL, notes string) (*model.Child, error) {
-	now := nowHCMC()
+	now := s.nowLocal()
 	c := &model.Child{
 		ID:          uuid.NewString(),
 		Name:        name,
@@ -37,24 +58,56 @@ func (s *Store) CreateChild(name, dob, gender, photoURL, notes string) (*model.C
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
-	_, err := s.db.Exec(
-		`INSERT INTO children (id, name, date_of_birth, gender, photo_url, notes, created_at, updated_at) VALUES (?,?,?,?,?,?,?,?)`,
-		c.ID, c.Name, c.DateOfBirth, c.Gender, c.PhotoURL, c.Notes, c.CreatedAt, c.UpdatedAt,
-	)
+	err := s.inTx(func(tx *Store) error {
+		if _, err := tx.db.Exec(
+			`INSERT INTO children (id, name, date_of_birth, gender, photo_url, notes, created_at, updated_at) VALUES (?,?,?,?,?,?,?,?)`,
+			c.ID, c.Name, c.DateOfBirth, c.Gender, c.PhotoURL, c.Notes, c.CreatedAt, c.UpdatedAt,
+		); err != nil {
+			return fmt.Errorf("insert child: %w", err)
+		}
+		return tx.audit(AuditCreate, "child", c.ID, nil, c)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("insert child: %w", err)
+		return nil, err
 	}
 	return c, nil
 }
 
 func (s *Store) UpdateChild(id, name, dob, gender, photoURL, notes string) (*model.Child, error) {
-	now := nowHCMC()
-	_, err := s.db.Exec(
-		`UPDATE children SET name=?, date_of_birth=?, gender=?, photo_url=?, notes=?, updated_at=? WHERE id=?`,
-		name, dob, gender, photoURL, notes, now, id,
-	)
+	var updated *model.Child
+	err := s.inTx(func(tx *Store) error {
+		existing, err := tx.GetChildByID(id)
+		if err != nil {
+			return err
+		}
+		now := tx.nowLocal()
+		_, err = tx.db.Exec(
+			`UPDATE children SET name=?, date_of_birth=?, gender=?, photo_url=?, notes=?, updated_at=? WHERE id=?`,
+			name, dob, gender, photoURL, notes, now, id,
+		)
+		if err != nil {
+			return fmt.Errorf("update child: %w", err)
+		}
+		updated, err = tx.GetChildByID(id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "child", id, existing, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func scanChildRow(row *sql.Row) (*model.Child, error) {
+	var c model.Child
+	err := row.Scan(&c.ID, &c.Name, &c.DateOfBirth, &c.Gender, &c.PhotoURL, &c.Notes, &c.CreatedAt, &c.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
 	if err != nil {
-		return nil, fmt.Errorf("update child: %w", err)
+		return nil, fmt.Errorf("scan child: %w", err)
 	}
-	return s.GetChild()
+	return &c, nil
 }
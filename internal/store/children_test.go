@@ -75,3 +75,63 @@ func TestUpdateChild(t *testing.T) {
 		t.Errorf("PhotoURL = %q, want %q", updated.PhotoURL, "http://photo.url")
 	}
 }
+
+func TestListChildren(t *testing.T) {
+	st := newTestStore(t)
+	first, _ := st.CreateChild("An", "2024-01-01", "female", "", "")
+	second, _ := st.CreateChild("Binh", "2024-01-01", "male", "", "")
+
+	children, err := st.ListChildren()
+	if err != nil {
+		t.Fatalf("ListChildren: %v", err)
+	}
+	if len(children) != 2 {
+		t.Fatalf("got %d children, want 2", len(children))
+	}
+	if children[0].ID != first.ID || children[1].ID != second.ID {
+		t.Errorf("children not in creation order: %q, %q", children[0].Name, children[1].Name)
+	}
+
+	got, err := st.GetChild()
+	if err != nil {
+		t.Fatalf("GetChild: %v", err)
+	}
+	if got.ID != first.ID {
+		t.Errorf("GetChild returned %q, want first child %q", got.Name, first.Name)
+	}
+}
+
+func TestGetChildByID(t *testing.T) {
+	st := newTestStore(t)
+	st.CreateChild("An", "2024-01-01", "female", "", "")
+	second, _ := st.CreateChild("Binh", "2024-01-01", "male", "", "")
+
+	got, err := st.GetChildByID(second.ID)
+	if err != nil {
+		t.Fatalf("GetChildByID: %v", err)
+	}
+	if got.Name != "Binh" {
+		t.Errorf("Name = %q, want Binh", got.Name)
+	}
+
+	if _, err := st.GetChildByID("missing"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestLogChildID(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	d, _ := st.CreateDiaper(childID, "wet", "2024-01-15T06:00:00+07:00", "")
+
+	owner, err := st.LogChildID("diaper", d.ID)
+	if err != nil {
+		t.Fatalf("LogChildID: %v", err)
+	}
+	if owner != childID {
+		t.Errorf("owner = %q, want %q", owner, childID)
+	}
+	if _, err := st.LogChildID("sleep", d.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for wrong kind, got %v", err)
+	}
+}
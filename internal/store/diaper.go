@@ -9,8 +9,10 @@ import (
 	"github.com/google/uuid"
 )
 
+const diaperColumns = `id, child_id, diaper_type, changed_at, notes, created_at, COALESCE(created_by,''), deleted_at`
+
 func (s *Store) CreateDiaper(childID, diaperType, changedAt, notes string) (*model.DiaperLog, error) {
-	now := nowHCMC()
+	now := s.nowLocal()
 	if changedAt == "" {
 		changedAt = now
 	}
@@ -21,25 +23,35 @@ func (s *Store) CreateDiaper(childID, diaperType, changedAt, notes string) (*mod
 		ChangedAt:  changedAt,
 		Notes:      notes,
 		CreatedAt:  now,
+		CreatedBy:  s.actor,
 	}
-	_, err := s.db.Exec(
-		`INSERT INTO diaper_logs (id, child_id, diaper_type, changed_at, notes, created_at) VALUES (?,?,?,?,?,?)`,
-		log.ID, log.ChildID, log.DiaperType, log.ChangedAt, log.Notes, log.CreatedAt,
-	)
+	err := s.inTx(func(tx *Store) error {
+		if _, err := tx.db.Exec(
+			`INSERT INTO diaper_logs (id, child_id, diaper_type, changed_at, notes, created_at, created_by) VALUES (?,?,?,?,?,?,?)`,
+			log.ID, log.ChildID, log.DiaperType, log.ChangedAt, log.Notes, log.CreatedAt, tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert diaper: %w", err)
+		}
+		return tx.audit(AuditCreate, "diaper", log.ID, nil, log)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("insert diaper: %w", err)
+		return nil, err
 	}
 	return log, nil
 }
 
 func (s *Store) GetDiaperLogs(childID, date string) ([]*model.DiaperLog, error) {
-	query := `SELECT id, child_id, diaper_type, changed_at, notes, created_at FROM diaper_logs WHERE child_id=?`
+	query := `SELECT ` + diaperColumns + ` FROM diaper_logs WHERE child_id=? AND deleted_at IS NULL`
 	args := []any{childID}
 	if date != "" {
-		query += ` AND substr(changed_at,1,10)=?`
-		args = append(args, date)
+		start, end, err := s.dayBounds(date)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND unixepoch(changed_at) >= ? AND unixepoch(changed_at) < ?`
+		args = append(args, start, end)
 	}
-	query += ` ORDER BY changed_at DESC`
+	query += ` ORDER BY unixepoch(changed_at) DESC`
 
 	rows, err := s.db.Query(query, args...)
 	if err != nil {
@@ -50,34 +62,51 @@ func (s *Store) GetDiaperLogs(childID, date string) ([]*model.DiaperLog, error)
 }
 
 func (s *Store) UpdateDiaper(id, diaperType, changedAt, notes string) (*model.DiaperLog, error) {
-	existing, err := getDiaperByID(s, id)
+	var updated *model.DiaperLog
+	err := s.inTx(func(tx *Store) error {
+		existing, err := getDiaperByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if existing.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if diaperType == "" {
+			diaperType = existing.DiaperType
+		}
+		if changedAt == "" {
+			changedAt = existing.ChangedAt
+		}
+		_, err = tx.db.Exec(
+			`UPDATE diaper_logs SET diaper_type=?, changed_at=?, notes=? WHERE id=?`,
+			diaperType, changedAt, notes, id,
+		)
+		if err != nil {
+			return fmt.Errorf("update diaper: %w", err)
+		}
+		updated, err = getDiaperByID(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "diaper", id, existing, updated)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if changedAt == "" {
-		changedAt = existing.ChangedAt
-	}
-	_, err = s.db.Exec(
-		`UPDATE diaper_logs SET diaper_type=?, changed_at=?, notes=? WHERE id=?`,
-		diaperType, changedAt, notes, id,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("update diaper: %w", err)
-	}
-	return getDiaperByID(s, id)
+	return updated, nil
 }
 
+// DeleteDiaper moves a diaper log to the trash; see RestoreLog.
 func (s *Store) DeleteDiaper(id string) error {
-	_, err := s.db.Exec(`DELETE FROM diaper_logs WHERE id=?`, id)
-	return err
+	return s.trashLog("diaper", id)
 }
 
 func getDiaperByID(s *Store, id string) (*model.DiaperLog, error) {
 	row := s.db.QueryRow(
-		`SELECT id, child_id, diaper_type, changed_at, notes, created_at FROM diaper_logs WHERE id=?`, id,
+		`SELECT ` + diaperColumns + ` FROM diaper_logs WHERE id=?`, id,
 	)
 	var l model.DiaperLog
-	err := row.Scan(&l.ID, &l.ChildID, &l.DiaperType, &l.ChangedAt, &l.Notes, &l.CreatedAt)
+	err := row.Scan(&l.ID, &l.ChildID, &l.DiaperType, &l.ChangedAt, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrNotFound
 	}
@@ -91,7 +120,7 @@ func scanDiaperRows(rows *sql.Rows) ([]*model.DiaperLog, error) {
 	var logs []*model.DiaperLog
 	for rows.Next() {
 		var l model.DiaperLog
-		if err := rows.Scan(&l.ID, &l.ChildID, &l.DiaperType, &l.ChangedAt, &l.Notes, &l.CreatedAt); err != nil {
+		if err := rows.Scan(&l.ID, &l.ChildID, &l.DiaperType, &l.ChangedAt, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt); err != nil {
 			return nil, err
 		}
 		logs = append(logs, &l)
@@ -0,0 +1,199 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"baby-care/internal/model"
+)
+
+// SickEpisode is a run of consecutive days on which the child had a fever or
+// any symptoms, with the readings, symptoms and medication doses recorded on
+// those days.
+type SickEpisode struct {
+	StartDate    string                  `json:"start_date"`
+	EndDate      string                  `json:"end_date"`
+	Days         int                     `json:"days"`
+	Ongoing      bool                    `json:"ongoing"` // the episode includes today
+	FeverDays    int                     `json:"fever_days"`
+	MaxCelsius   *float64                `json:"max_celsius,omitempty"`
+	Symptoms     []string                `json:"symptoms"` // every tag seen, sorted
+	Temperatures []*model.TemperatureLog `json:"temperatures"`
+	SymptomLogs  []*model.SymptomLog     `json:"symptom_logs"`
+	Doses        []*GivenDose            `json:"doses"`
+}
+
+// GetSickEpisodes groups the sick days in [from, to] into episodes, newest
+// first. An episode running across from is cut off there.
+func (s *Store) GetSickEpisodes(childID, from, to string) ([]*SickEpisode, error) {
+	start, end, err := s.rangeBounds(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(
+		`SELECT `+temperatureColumns+` FROM temperature_logs
+		 WHERE child_id=? AND deleted_at IS NULL AND unixepoch(taken_at) >= ? AND unixepoch(taken_at) < ?
+		 ORDER BY unixepoch(taken_at)`,
+		childID, start, end,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query episode temperatures: %w", err)
+	}
+	temps, err := scanTemperatureRows(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.Query(
+		`SELECT `+symptomColumns+` FROM symptom_logs
+		 WHERE child_id=? AND deleted_at IS NULL AND unixepoch(observed_at) >= ? AND unixepoch(observed_at) < ?
+		 ORDER BY unixepoch(observed_at)`,
+		childID, start, end,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query episode symptoms: %w", err)
+	}
+	symptoms, err := scanSymptomRows(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// A day is sick when it has a fever reading or any symptom.
+	sick := map[string]bool{}
+	for _, t := range temps {
+		if t.Fever {
+			sick[s.localDate(t.TakenAt)] = true
+		}
+	}
+	for _, sy := range symptoms {
+		sick[s.localDate(sy.ObservedAt)] = true
+	}
+	if len(sick) == 0 {
+		return nil, nil
+	}
+	dates := make([]string, 0, len(sick))
+	for d := range sick {
+		dates = append(dates, d)
+	}
+	slices.Sort(dates)
+
+	var episodes []*SickEpisode
+	var cur *SickEpisode
+	for _, d := range dates {
+		if cur != nil && d == nextDate(cur.EndDate) {
+			cur.EndDate = d
+			cur.Days++
+			continue
+		}
+		cur = &SickEpisode{
+			StartDate:    d,
+			EndDate:      d,
+			Days:         1,
+			Symptoms:     []string{},
+			Temperatures: []*model.TemperatureLog{},
+			SymptomLogs:  []*model.SymptomLog{},
+			Doses:        []*GivenDose{},
+		}
+		episodes = append(episodes, cur)
+	}
+
+	// episodeOn returns the episode covering date, if any.
+	episodeOn := func(date string) *SickEpisode {
+		for _, e := range episodes {
+			if date >= e.StartDate && date <= e.EndDate {
+				return e
+			}
+		}
+		return nil
+	}
+	feverDates := map[string]bool{}
+	for _, t := range temps {
+		date := s.localDate(t.TakenAt)
+		e := episodeOn(date)
+		if e == nil {
+			continue
+		}
+		e.Temperatures = append(e.Temperatures, t)
+		if e.MaxCelsius == nil || t.Celsius > *e.MaxCelsius {
+			c := t.Celsius
+			e.MaxCelsius = &c
+		}
+		if t.Fever && !feverDates[date] {
+			feverDates[date] = true
+			e.FeverDays++
+		}
+	}
+	for _, sy := range symptoms {
+		e := episodeOn(s.localDate(sy.ObservedAt))
+		e.SymptomLogs = append(e.SymptomLogs, sy)
+		for _, tag := range sy.Symptoms {
+			if !slices.Contains(e.Symptoms, tag) {
+				e.Symptoms = append(e.Symptoms, tag)
+			}
+		}
+	}
+
+	doses, err := s.dosesBetween(childID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range doses {
+		if e := episodeOn(s.localDate(d.GivenAt)); e != nil {
+			e.Doses = append(e.Doses, d)
+		}
+	}
+
+	today := s.todayLocal()
+	for _, e := range episodes {
+		slices.Sort(e.Symptoms)
+		e.Ongoing = e.EndDate == today
+	}
+	slices.Reverse(episodes)
+	return episodes, nil
+}
+
+// dosesBetween returns the child's doses given in [start, end) Unix seconds,
+// oldest first, with their medication names.
+func (s *Store) dosesBetween(childID string, start, end int64) ([]*GivenDose, error) {
+	rows, err := s.db.Query(
+		`SELECT `+medicationLogColumns+` FROM medication_logs
+		 WHERE child_id=? AND deleted_at IS NULL AND unixepoch(given_at) >= ? AND unixepoch(given_at) < ?
+		 ORDER BY unixepoch(given_at)`,
+		childID, start, end,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query doses: %w", err)
+	}
+	logs, err := scanMedicationLogRows(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	meds := map[string]*model.Medication{}
+	doses := make([]*GivenDose, 0, len(logs))
+	for _, l := range logs {
+		med, ok := meds[l.MedicationID]
+		if !ok {
+			if med, err = s.GetMedication(l.MedicationID); err != nil {
+				return nil, err
+			}
+			meds[l.MedicationID] = med
+		}
+		doses = append(doses, &GivenDose{MedicationLog: l, MedicationName: med.Name, DoseUnit: med.DoseUnit})
+	}
+	return doses, nil
+}
+
+// nextDate returns the calendar day after date (YYYY-MM-DD).
+func nextDate(date string) string {
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return ""
+	}
+	return d.AddDate(0, 0, 1).Format("2006-01-02")
+}
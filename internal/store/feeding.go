@@ -4,12 +4,17 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"time"
 
 	"baby-care/internal/model"
 	"github.com/google/uuid"
 )
 
+// ErrMilkTypeNotBottle is returned when a milk type is given for a breast
+// feed.
+var ErrMilkTypeNotBottle = errors.New("milk type only applies to bottle feeds")
+
+const feedingColumns = `id, child_id, feed_type, start_time, end_time, duration_minutes, quantity_ml, COALESCE(milk_type,''), notes, created_at, COALESCE(created_by,''), deleted_at`
+
 // StoppedSleep is returned by CreateFeeding when an active sleep was auto-stopped.
 type StoppedSleep struct {
 	ID              string `json:"id"`
@@ -17,125 +22,231 @@ type StoppedSleep struct {
 }
 
 func (s *Store) CreateFeeding(childID, feedType, startTime, notes string, quantityML *int) (*model.FeedingLog, *StoppedSleep, error) {
-	now := nowHCMC()
+	return s.CreateFeedingSpan(childID, feedType, "", startTime, "", notes, quantityML)
+}
+
+// CreateFeedingSpan creates a feeding that is already finished when endTime
+// is set, as when logging one after the fact. Only an ongoing breast feed
+// auto-stops the active sleep. milkType says what a bottle held and is empty
+// for breast feeds; a breast milk bottle draws its quantity from the stash.
+func (s *Store) CreateFeedingSpan(childID, feedType, milkType, startTime, endTime, notes string, quantityML *int) (*model.FeedingLog, *StoppedSleep, error) {
+	now := s.nowLocal()
 	if startTime == "" {
 		startTime = now
 	}
-
-	// Auto-stop any active sleep when starting a timed breast feed.
+	sp := span{start: startTime, end: endTime, notes: notes}
+	if err := sp.check(); err != nil {
+		return nil, nil, err
+	}
+	var log *model.FeedingLog
 	var stopped *StoppedSleep
-	if feedType == "breast_left" || feedType == "breast_right" {
-		if activeSleep, err := s.GetActiveSleep(childID); err == nil {
-			updated, err := s.UpdateSleep(activeSleep.ID, "", nowHCMC(), activeSleep.Notes)
-			if err == nil && updated.DurationMinutes != nil {
-				stopped = &StoppedSleep{ID: updated.ID, DurationMinutes: *updated.DurationMinutes}
+	err := s.inTx(func(tx *Store) error {
+		segs, err := shapeSegments(nil, feedType, sp)
+		if err != nil {
+			return err
+		}
+		if feedType != "bottle" {
+			if err := tx.resolveOverlaps("feeding", childID, "", &sp); err != nil {
+				return err
 			}
 		}
-	}
-	log := &model.FeedingLog{
-		ID:         uuid.NewString(),
-		ChildID:    childID,
-		FeedType:   feedType,
-		StartTime:  startTime,
-		QuantityML: quantityML,
-		Notes:      notes,
-		CreatedAt:  now,
-	}
-	_, err := s.db.Exec(
-		`INSERT INTO feeding_logs (id, child_id, feed_type, start_time, quantity_ml, notes, created_at) VALUES (?,?,?,?,?,?,?)`,
-		log.ID, log.ChildID, log.FeedType, log.StartTime, log.QuantityML, log.Notes, log.CreatedAt,
-	)
+		if len(sp.merged) > 0 {
+			segs, feedType = mergeSegments(append(segs, sp.merged...), sp)
+		} else if segs, err = shapeSegments(segs, feedType, sp); err != nil {
+			return err
+		}
+
+		// Auto-stop any active sleep when starting a timed breast feed.
+		if endTime == "" && (feedType == "breast_left" || feedType == "breast_right") {
+			if activeSleep, err := tx.GetActiveSleep(childID); err == nil {
+				updated, err := tx.UpdateSleep(activeSleep.ID, "", tx.nowLocal(), activeSleep.Notes)
+				if err == nil && updated.DurationMinutes != nil {
+					stopped = &StoppedSleep{ID: updated.ID, DurationMinutes: *updated.DurationMinutes}
+				}
+			}
+		}
+		log = &model.FeedingLog{
+			ID:              uuid.NewString(),
+			ChildID:         childID,
+			FeedType:        feedType,
+			StartTime:       sp.start,
+			DurationMinutes: sp.durationMinutes(),
+			QuantityML:      quantityML,
+			MilkType:        milkType,
+			Notes:           sp.notes,
+			CreatedAt:       now,
+			CreatedBy:       tx.actor,
+		}
+		if sp.end != "" {
+			log.EndTime = &sp.end
+		}
+		if _, err := tx.db.Exec(
+			`INSERT INTO feeding_logs (id, child_id, feed_type, start_time, end_time, duration_minutes, quantity_ml, milk_type, notes, created_at, created_by) VALUES (?,?,?,?,?,?,?,NULLIF(?,''),?,?,?)`,
+			log.ID, log.ChildID, log.FeedType, log.StartTime, sp.endValue(), log.DurationMinutes, log.QuantityML, log.MilkType, log.Notes, log.CreatedAt, tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert feeding: %w", err)
+		}
+		if err := saveSegments(tx.db, log.ID, segs); err != nil {
+			return err
+		}
+		if err := tx.drawMilk(log.ID, childID, breastMilkML(feedType, milkType, quantityML)); err != nil {
+			return err
+		}
+		if err := tx.fillFeedings(log); err != nil {
+			return err
+		}
+		return tx.audit(AuditCreate, "feeding", log.ID, nil, log)
+	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("insert feeding: %w", err)
+		return nil, nil, err
 	}
 	return log, stopped, nil
 }
 
 func (s *Store) GetFeedingLogs(childID, date string) ([]*model.FeedingLog, error) {
-	query := `SELECT id, child_id, feed_type, start_time, end_time, duration_minutes, quantity_ml, notes, created_at FROM feeding_logs WHERE child_id=?`
+	query := `SELECT ` + feedingColumns + ` FROM feeding_logs WHERE child_id=? AND deleted_at IS NULL`
 	args := []any{childID}
 	if date != "" {
-		query += ` AND substr(start_time,1,10)=?`
-		args = append(args, date)
+		start, end, err := s.dayBounds(date)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND unixepoch(start_time) >= ? AND unixepoch(start_time) < ?`
+		args = append(args, start, end)
 	}
-	query += ` ORDER BY start_time DESC`
+	query += ` ORDER BY unixepoch(start_time) DESC`
 
 	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query feeding: %w", err)
 	}
 	defer rows.Close()
-	return scanFeedingRows(rows)
+	logs, err := scanFeedingRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillFeedings(logs...); err != nil {
+		return nil, err
+	}
+	return logs, nil
 }
 
 func (s *Store) GetActiveFeeding(childID string) (*model.FeedingLog, error) {
 	row := s.db.QueryRow(
-		`SELECT id, child_id, feed_type, start_time, end_time, duration_minutes, quantity_ml, notes, created_at FROM feeding_logs WHERE child_id=? AND end_time IS NULL AND feed_type != 'bottle' ORDER BY start_time DESC LIMIT 1`,
+		`SELECT ` + feedingColumns + ` FROM feeding_logs WHERE child_id=? AND end_time IS NULL AND feed_type != 'bottle' AND deleted_at IS NULL ORDER BY unixepoch(start_time) DESC LIMIT 1`,
 		childID,
 	)
-	return scanFeedingRow(row)
-}
-
-func (s *Store) UpdateFeeding(id, feedType, startTime, endTime, notes string, quantityML *int) (*model.FeedingLog, error) {
-	existing, err := getFeedingByID(s, id)
+	log, err := scanFeedingRow(row)
 	if err != nil {
 		return nil, err
 	}
-	if feedType == "" {
-		feedType = existing.FeedType
-	}
-	if startTime == "" {
-		startTime = existing.StartTime
-	}
-
-	// Use new endTime if provided; fall back to existing end_time for duration calc
-	effectiveEnd := endTime
-	if effectiveEnd == "" && existing.EndTime != nil {
-		effectiveEnd = *existing.EndTime
-	}
+	return log, s.fillFeedings(log)
+}
 
-	var durationMinutes *int
-	if effectiveEnd != "" {
-		st, e1 := time.Parse(time.RFC3339, startTime)
-		et, e2 := time.Parse(time.RFC3339, effectiveEnd)
-		if e1 == nil && e2 == nil {
-			d := int(et.Sub(st).Minutes())
-			durationMinutes = &d
+// UpdateFeeding edits a feed. Empty feedType and milkType keep their stored
+// values; a feed that stops being a bottle loses its milk type. A change to
+// how much breast milk a bottle held is redrawn from the stash.
+func (s *Store) UpdateFeeding(id, feedType, milkType, startTime, endTime, notes string, quantityML *int) (*model.FeedingLog, error) {
+	var updated *model.FeedingLog
+	err := s.inTx(func(tx *Store) error {
+		existing, err := getFeedingByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if existing.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if feedType == "" {
+			feedType = existing.FeedType
+		}
+		if milkType == "" && feedType == "bottle" {
+			milkType = existing.MilkType
+		}
+		if milkType != "" && feedType != "bottle" {
+			return ErrMilkTypeNotBottle
+		}
+		// Use new endTime if provided; fall back to existing end_time for duration calc
+		sp := span{start: existing.StartTime, end: endTime, notes: notes}
+		if startTime != "" {
+			sp.start = startTime
+		}
+		if sp.end == "" && existing.EndTime != nil {
+			sp.end = *existing.EndTime
+		}
+		if err := sp.check(); err != nil {
+			return err
+		}
+		segs, err := shapeSegments(existing.Segments, feedType, sp)
+		if err != nil {
+			return err
+		}
+		if feedType != "bottle" {
+			if err := tx.resolveOverlaps("feeding", existing.ChildID, id, &sp); err != nil {
+				return err
+			}
+		}
+		if len(sp.merged) > 0 {
+			segs, feedType = mergeSegments(append(segs, sp.merged...), sp)
+		} else if segs, err = shapeSegments(segs, feedType, sp); err != nil {
+			return err
+		}
+		// Stopping a paused feed ends its pause.
+		if sp.end != "" {
+			if err := closePauses(tx.db, "feeding", id, sp.end); err != nil {
+				return err
+			}
+		}
+		pauses, err := tx.loadPauses("feeding", id)
+		if err != nil {
+			return err
 		}
-	}
 
-	if effectiveEnd != "" {
-		_, err = s.db.Exec(
-			`UPDATE feeding_logs SET feed_type=?, start_time=?, end_time=?, duration_minutes=?, quantity_ml=?, notes=? WHERE id=?`,
-			feedType, startTime, effectiveEnd, durationMinutes, quantityML, notes, id,
+		_, err = tx.db.Exec(
+			`UPDATE feeding_logs SET feed_type=?, milk_type=NULLIF(?,''), start_time=?, end_time=?, duration_minutes=?, quantity_ml=?, notes=? WHERE id=?`,
+			feedType, milkType, sp.start, sp.endValue(), activeMinutes(sp, pauses[id]), quantityML, sp.notes, id,
 		)
-	} else {
-		_, err = s.db.Exec(
-			`UPDATE feeding_logs SET feed_type=?, start_time=?, quantity_ml=?, notes=? WHERE id=?`,
-			feedType, startTime, quantityML, notes, id,
-		)
-	}
+		if err != nil {
+			return fmt.Errorf("update feeding: %w", err)
+		}
+		if err := saveSegments(tx.db, id, segs); err != nil {
+			return err
+		}
+		if ml := breastMilkML(feedType, milkType, quantityML); ml != breastMilkML(existing.FeedType, existing.MilkType, existing.QuantityML) {
+			if err := tx.redrawMilk(id, existing.ChildID, ml); err != nil {
+				return err
+			}
+		}
+		updated, err = getFeedingByID(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "feeding", id, existing, updated)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("update feeding: %w", err)
+		return nil, err
 	}
-	return getFeedingByID(s, id)
+	return updated, nil
 }
 
+// DeleteFeeding moves a feeding log to the trash; see RestoreLog.
 func (s *Store) DeleteFeeding(id string) error {
-	_, err := s.db.Exec(`DELETE FROM feeding_logs WHERE id=?`, id)
-	return err
+	return s.trashLog("feeding", id)
 }
 
 func getFeedingByID(s *Store, id string) (*model.FeedingLog, error) {
 	row := s.db.QueryRow(
-		`SELECT id, child_id, feed_type, start_time, end_time, duration_minutes, quantity_ml, notes, created_at FROM feeding_logs WHERE id=?`, id,
+		`SELECT ` + feedingColumns + ` FROM feeding_logs WHERE id=?`, id,
 	)
-	return scanFeedingRow(row)
+	log, err := scanFeedingRow(row)
+	if err != nil {
+		return nil, err
+	}
+	return log, s.fillFeedings(log)
 }
 
 func scanFeedingRow(row *sql.Row) (*model.FeedingLog, error) {
 	var l model.FeedingLog
-	err := row.Scan(&l.ID, &l.ChildID, &l.FeedType, &l.StartTime, &l.EndTime, &l.DurationMinutes, &l.QuantityML, &l.Notes, &l.CreatedAt)
+	err := row.Scan(&l.ID, &l.ChildID, &l.FeedType, &l.StartTime, &l.EndTime, &l.DurationMinutes, &l.QuantityML, &l.MilkType, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrNotFound
 	}
@@ -149,7 +260,7 @@ func scanFeedingRows(rows *sql.Rows) ([]*model.FeedingLog, error) {
 	var logs []*model.FeedingLog
 	for rows.Next() {
 		var l model.FeedingLog
-		if err := rows.Scan(&l.ID, &l.ChildID, &l.FeedType, &l.StartTime, &l.EndTime, &l.DurationMinutes, &l.QuantityML, &l.Notes, &l.CreatedAt); err != nil {
+		if err := rows.Scan(&l.ID, &l.ChildID, &l.FeedType, &l.StartTime, &l.EndTime, &l.DurationMinutes, &l.QuantityML, &l.MilkType, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt); err != nil {
 			return nil, err
 		}
 		logs = append(logs, &l)
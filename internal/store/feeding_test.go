@@ -144,7 +144,7 @@ func TestUpdateFeeding_StopsWithDuration(t *testing.T) {
 	childID := mustCreateChild(t, st)
 
 	f, _, _ := st.CreateFeeding(childID, "breast_left", feed1Start, "", nil)
-	updated, err := st.UpdateFeeding(f.ID, "", "", feed1End, "", nil)
+	updated, err := st.UpdateFeeding(f.ID, "", "", "", feed1End, "", nil)
 	if err != nil {
 		t.Fatalf("UpdateFeeding: %v", err)
 	}
@@ -164,7 +164,7 @@ func TestUpdateFeeding_QuantityOnly(t *testing.T) {
 	childID := mustCreateChild(t, st)
 
 	f, _, _ := st.CreateFeeding(childID, "bottle", feed1Start, "", intPtr(60))
-	updated, err := st.UpdateFeeding(f.ID, "", "", "", "", intPtr(150))
+	updated, err := st.UpdateFeeding(f.ID, "", "", "", "", "", intPtr(150))
 	if err != nil {
 		t.Fatalf("UpdateFeeding: %v", err)
 	}
@@ -176,6 +176,31 @@ func TestUpdateFeeding_QuantityOnly(t *testing.T) {
 	}
 }
 
+func TestUpdateFeeding_MilkType(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	f, _, _ := st.CreateFeedingSpan(childID, "bottle", "formula", feed1Start, feed1End, "", intPtr(60))
+	updated, err := st.UpdateFeeding(f.ID, "", "breast_milk", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("UpdateFeeding: %v", err)
+	}
+	if updated.MilkType != "breast_milk" {
+		t.Errorf("MilkType = %q, want breast_milk", updated.MilkType)
+	}
+	if updated, _ = st.UpdateFeeding(f.ID, "", "", "", "", "", nil); updated.MilkType != "breast_milk" {
+		t.Errorf("MilkType after edit without it = %q, want kept", updated.MilkType)
+	}
+
+	b, _, _ := st.CreateFeeding(childID, "breast_left", feed2Start, "", nil)
+	if _, err := st.UpdateFeeding(b.ID, "", "formula", "", "", "", nil); !errors.Is(err, store.ErrMilkTypeNotBottle) {
+		t.Errorf("milk type on breast feed error = %v, want ErrMilkTypeNotBottle", err)
+	}
+	if updated, err = st.UpdateFeeding(f.ID, "breast_right", "", "", "", "", nil); err != nil || updated.MilkType != "" {
+		t.Errorf("switching to breast = %v, %v; want milk type cleared", updated, err)
+	}
+}
+
 func TestDeleteFeeding(t *testing.T) {
 	st := newTestStore(t)
 	childID := mustCreateChild(t, st)
@@ -240,3 +265,101 @@ func TestCreateFeeding_BottleDoesNotAutoStopSleep(t *testing.T) {
 		t.Errorf("expected sleep to still be active, got %v", err)
 	}
 }
+
+func TestSwitchFeedingSide(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	f, _, err := st.CreateFeeding(childID, "breast_left", feed1Start, "", nil)
+	if err != nil {
+		t.Fatalf("CreateFeeding: %v", err)
+	}
+	if len(f.Segments) != 1 || f.Segments[0].Side != "left" || f.Segments[0].EndTime != nil {
+		t.Fatalf("segments = %+v, want one open left segment", f.Segments)
+	}
+	if _, err := st.SwitchFeedingSide(f.ID, "2024-01-15T09:59:00+07:00"); !errors.Is(err, store.ErrEndBeforeStart) {
+		t.Errorf("switch before the start error = %v, want ErrEndBeforeStart", err)
+	}
+	f, err = st.SwitchFeedingSide(f.ID, "2024-01-15T10:12:00+07:00")
+	if err != nil {
+		t.Fatalf("SwitchFeedingSide: %v", err)
+	}
+	if len(f.Segments) != 2 || f.Segments[1].Side != "right" || f.FeedType != "breast_left" {
+		t.Fatalf("after switch = %s %+v, want left then right", f.FeedType, f.Segments)
+	}
+
+	f, err = st.UpdateFeeding(f.ID, "", "", "", feed1End, "", nil)
+	if err != nil {
+		t.Fatalf("UpdateFeeding: %v", err)
+	}
+	if *f.LeftMinutes != 12 || *f.RightMinutes != 8 || *f.DurationMinutes != 20 {
+		t.Errorf("minutes = left %d, right %d, total %d; want 12, 8, 20", *f.LeftMinutes, *f.RightMinutes, *f.DurationMinutes)
+	}
+	if _, err := st.SwitchFeedingSide(f.ID, ""); !errors.Is(err, store.ErrFeedingNotActive) {
+		t.Errorf("switch after the end error = %v, want ErrFeedingNotActive", err)
+	}
+	if _, err := st.UpdateFeeding(f.ID, "", "", "", "2024-01-15T10:05:00+07:00", "", nil); !errors.Is(err, store.ErrCutsSegments) {
+		t.Errorf("ending before the switch error = %v, want ErrCutsSegments", err)
+	}
+
+	// Starting on the right flips every segment.
+	f, err = st.UpdateFeeding(f.ID, "breast_right", "", "", "", "", nil)
+	if err != nil {
+		t.Fatalf("UpdateFeeding side: %v", err)
+	}
+	if f.Segments[0].Side != "right" || f.Segments[1].Side != "left" || *f.RightMinutes != 12 {
+		t.Errorf("segments = %+v, want right then left", f.Segments)
+	}
+}
+
+func TestGetNextBreastSide(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	if _, err := st.GetNextBreastSide(childID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("before any feed error = %v, want ErrNotFound", err)
+	}
+
+	// One side only: offer the other one next.
+	st.CreateFeedingSpan(childID, "breast_left", "", feed1Start, feed1End, "", nil)
+	if next, err := st.GetNextBreastSide(childID); err != nil || next.Side != "right" {
+		t.Errorf("after a left feed = %+v, %v; want right", next, err)
+	}
+
+	// Both sides: start again on the side the feed ended on.
+	f, _, _ := st.CreateFeeding(childID, "breast_right", feed2Start, "", nil)
+	st.SwitchFeedingSide(f.ID, "2024-01-16T14:10:00+07:00")
+	if next, _ := st.GetNextBreastSide(childID); next.Side != "right" {
+		t.Errorf("while feeding = %s, want the last finished feed's recommendation", next.Side)
+	}
+	st.UpdateFeeding(f.ID, "", "", "", "2024-01-16T14:15:00+07:00", "", nil)
+	if next, _ := st.GetNextBreastSide(childID); next.Side != "left" || next.LastSide != "left" {
+		t.Errorf("after right then left = %+v, want left", next)
+	}
+}
+
+func TestPauseTimer_Feeding(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	f, _, _ := st.CreateFeeding(childID, "breast_left", feed1Start, "", nil)
+
+	// Burp from 10:05 to 10:08, then the right side from 10:12 resumes a
+	// second break taken at 10:10.
+	st.PauseTimer("feeding", f.ID, "2024-01-15T10:05:00+07:00")
+	st.ResumeTimer("feeding", f.ID, "2024-01-15T10:08:00+07:00")
+	st.PauseTimer("feeding", f.ID, "2024-01-15T10:10:00+07:00")
+	if _, err := st.SwitchFeedingSide(f.ID, "2024-01-15T10:12:00+07:00"); err != nil {
+		t.Fatalf("SwitchFeedingSide: %v", err)
+	}
+	f, err := st.UpdateFeeding(f.ID, "", "", "", feed1End, "", nil)
+	if err != nil {
+		t.Fatalf("UpdateFeeding: %v", err)
+	}
+	if *f.DurationMinutes != 15 || *f.LeftMinutes != 7 || *f.RightMinutes != 8 {
+		t.Errorf("minutes = total %d, left %d, right %d; want 15, 7, 8", *f.DurationMinutes, *f.LeftMinutes, *f.RightMinutes)
+	}
+
+	bottle, _, _ := st.CreateFeeding(childID, "bottle", feed2Start, "", intPtr(90))
+	if _, err := st.PauseTimer("feeding", bottle.ID, ""); !errors.Is(err, store.ErrTimerNotRunning) {
+		t.Errorf("pause of a bottle error = %v, want ErrTimerNotRunning", err)
+	}
+}
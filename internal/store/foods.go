@@ -0,0 +1,101 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"baby-care/internal/catalog"
+	"baby-care/internal/model"
+	"github.com/google/uuid"
+)
+
+const foodColumns = `id, name, category, allergens, custom`
+
+// syncFoods writes the food catalogue into the foods table so solid feeds can
+// reference catalogue foods. Catalogue changes in a newer build overwrite the
+// stored copies; custom foods are left alone.
+func (s *Store) syncFoods() error {
+	tx, err := s.pool.Begin()
+	if err != nil {
+		return fmt.Errorf("begin food sync: %w", err)
+	}
+	defer tx.Rollback()
+	now := s.nowLocal()
+	for _, f := range catalog.Foods() {
+		_, err := tx.Exec(
+			`INSERT INTO foods (id, name, category, allergens, custom, created_at) VALUES (?,?,?,?,0,?)
+			 ON CONFLICT(id) DO UPDATE SET name=excluded.name, category=excluded.category, allergens=excluded.allergens
+			 WHERE custom=0`,
+			f.ID, f.Name, f.Category, strings.Join(f.Allergens, " "), now,
+		)
+		if err != nil {
+			return fmt.Errorf("sync food %s: %w", f.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// ListFoods returns the catalogue and custom foods, by name.
+func (s *Store) ListFoods() ([]*model.Food, error) {
+	rows, err := s.db.Query(`SELECT ` + foodColumns + ` FROM foods ORDER BY name COLLATE NOCASE`)
+	if err != nil {
+		return nil, fmt.Errorf("query foods: %w", err)
+	}
+	defer rows.Close()
+	var list []*model.Food
+	for rows.Next() {
+		f, err := scanFood(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, f)
+	}
+	return list, rows.Err()
+}
+
+func (s *Store) GetFood(id string) (*model.Food, error) {
+	f, err := scanFood(s.db.QueryRow(`SELECT `+foodColumns+` FROM foods WHERE id=?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// CreateFood adds a custom food for the household.
+func (s *Store) CreateFood(name, category string, allergens []string) (*model.Food, error) {
+	f := &model.Food{
+		ID:        uuid.NewString(),
+		Name:      name,
+		Category:  category,
+		Allergens: allergens,
+		Custom:    true,
+	}
+	if f.Allergens == nil {
+		f.Allergens = []string{}
+	}
+	err := s.inTx(func(tx *Store) error {
+		if _, err := tx.db.Exec(
+			`INSERT INTO foods (id, name, category, allergens, custom, created_at, created_by) VALUES (?,?,?,?,1,?,?)`,
+			f.ID, f.Name, f.Category, strings.Join(f.Allergens, " "), tx.nowLocal(), tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert food: %w", err)
+		}
+		return tx.audit(AuditCreate, "food", f.ID, nil, f)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func scanFood(row rowScanner) (*model.Food, error) {
+	var f model.Food
+	var allergens string
+	if err := row.Scan(&f.ID, &f.Name, &f.Category, &allergens, &f.Custom); err != nil {
+		return nil, err
+	}
+	f.Allergens = strings.Fields(allergens)
+	return &f, nil
+}
@@ -9,10 +9,12 @@ import (
 	"github.com/google/uuid"
 )
 
+const growthColumns = `id, child_id, measured_on, weight_grams, length_mm, head_circumference_mm, notes, created_at, COALESCE(created_by,''), deleted_at`
+
 func (s *Store) CreateGrowth(childID, measuredOn string, weightGrams, lengthMM, headCircMM *int, notes string) (*model.GrowthLog, error) {
-	now := nowHCMC()
+	now := s.nowLocal()
 	if measuredOn == "" {
-		measuredOn = todayHCMC()
+		measuredOn = s.todayLocal()
 	}
 	log := &model.GrowthLog{
 		ID:                  uuid.NewString(),
@@ -23,20 +25,26 @@ func (s *Store) CreateGrowth(childID, measuredOn string, weightGrams, lengthMM,
 		HeadCircumferenceMM: headCircMM,
 		Notes:               notes,
 		CreatedAt:           now,
+		CreatedBy:           s.actor,
 	}
-	_, err := s.db.Exec(
-		`INSERT INTO growth_logs (id, child_id, measured_on, weight_grams, length_mm, head_circumference_mm, notes, created_at) VALUES (?,?,?,?,?,?,?,?)`,
-		log.ID, log.ChildID, log.MeasuredOn, log.WeightGrams, log.LengthMM, log.HeadCircumferenceMM, log.Notes, log.CreatedAt,
-	)
+	err := s.inTx(func(tx *Store) error {
+		if _, err := tx.db.Exec(
+			`INSERT INTO growth_logs (id, child_id, measured_on, weight_grams, length_mm, head_circumference_mm, notes, created_at, created_by) VALUES (?,?,?,?,?,?,?,?,?)`,
+			log.ID, log.ChildID, log.MeasuredOn, log.WeightGrams, log.LengthMM, log.HeadCircumferenceMM, log.Notes, log.CreatedAt, tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert growth: %w", err)
+		}
+		return tx.audit(AuditCreate, "growth", log.ID, nil, log)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("insert growth: %w", err)
+		return nil, err
 	}
 	return log, nil
 }
 
 func (s *Store) GetGrowthLogs(childID string) ([]*model.GrowthLog, error) {
 	rows, err := s.db.Query(
-		`SELECT id, child_id, measured_on, weight_grams, length_mm, head_circumference_mm, notes, created_at FROM growth_logs WHERE child_id=? ORDER BY measured_on ASC`,
+		`SELECT ` + growthColumns + ` FROM growth_logs WHERE child_id=? AND deleted_at IS NULL ORDER BY measured_on ASC`,
 		childID,
 	)
 	if err != nil {
@@ -47,27 +55,45 @@ func (s *Store) GetGrowthLogs(childID string) ([]*model.GrowthLog, error) {
 }
 
 func (s *Store) UpdateGrowth(id, measuredOn string, weightGrams, lengthMM, headCircMM *int, notes string) (*model.GrowthLog, error) {
-	_, err := s.db.Exec(
-		`UPDATE growth_logs SET measured_on=?, weight_grams=?, length_mm=?, head_circumference_mm=?, notes=? WHERE id=?`,
-		measuredOn, weightGrams, lengthMM, headCircMM, notes, id,
-	)
+	var updated *model.GrowthLog
+	err := s.inTx(func(tx *Store) error {
+		existing, err := getGrowthByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if existing.DeletedAt != nil {
+			return ErrNotFound
+		}
+		_, err = tx.db.Exec(
+			`UPDATE growth_logs SET measured_on=?, weight_grams=?, length_mm=?, head_circumference_mm=?, notes=? WHERE id=?`,
+			measuredOn, weightGrams, lengthMM, headCircMM, notes, id,
+		)
+		if err != nil {
+			return fmt.Errorf("update growth: %w", err)
+		}
+		updated, err = getGrowthByID(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "growth", id, existing, updated)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("update growth: %w", err)
+		return nil, err
 	}
-	return getGrowthByID(s, id)
+	return updated, nil
 }
 
+// DeleteGrowth moves a growth log to the trash; see RestoreLog.
 func (s *Store) DeleteGrowth(id string) error {
-	_, err := s.db.Exec(`DELETE FROM growth_logs WHERE id=?`, id)
-	return err
+	return s.trashLog("growth", id)
 }
 
 func getGrowthByID(s *Store, id string) (*model.GrowthLog, error) {
 	row := s.db.QueryRow(
-		`SELECT id, child_id, measured_on, weight_grams, length_mm, head_circumference_mm, notes, created_at FROM growth_logs WHERE id=?`, id,
+		`SELECT ` + growthColumns + ` FROM growth_logs WHERE id=?`, id,
 	)
 	var l model.GrowthLog
-	err := row.Scan(&l.ID, &l.ChildID, &l.MeasuredOn, &l.WeightGrams, &l.LengthMM, &l.HeadCircumferenceMM, &l.Notes, &l.CreatedAt)
+	err := row.Scan(&l.ID, &l.ChildID, &l.MeasuredOn, &l.WeightGrams, &l.LengthMM, &l.HeadCircumferenceMM, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrNotFound
 	}
@@ -81,7 +107,7 @@ func scanGrowthRows(rows *sql.Rows) ([]*model.GrowthLog, error) {
 	var logs []*model.GrowthLog
 	for rows.Next() {
 		var l model.GrowthLog
-		if err := rows.Scan(&l.ID, &l.ChildID, &l.MeasuredOn, &l.WeightGrams, &l.LengthMM, &l.HeadCircumferenceMM, &l.Notes, &l.CreatedAt); err != nil {
+		if err := rows.Scan(&l.ID, &l.ChildID, &l.MeasuredOn, &l.WeightGrams, &l.LengthMM, &l.HeadCircumferenceMM, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt); err != nil {
 			return nil, err
 		}
 		logs = append(logs, &l)
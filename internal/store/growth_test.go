@@ -1,6 +1,7 @@
 package store_test
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -113,3 +114,84 @@ func TestDeleteGrowth(t *testing.T) {
 		t.Errorf("expected 0 logs after delete, got %d", len(logs))
 	}
 }
+
+func TestGetAssessedGrowth(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st) // a girl born 2024-01-01
+
+	// The WHO median birth weight for girls, and a measurement past the tables.
+	if _, err := st.CreateGrowth(childID, "2024-01-01", intPtr(3232), nil, nil, ""); err != nil {
+		t.Fatalf("CreateGrowth: %v", err)
+	}
+	if _, err := st.CreateGrowth(childID, "2026-06-01", intPtr(13000), intPtr(900), nil, ""); err != nil {
+		t.Fatalf("CreateGrowth: %v", err)
+	}
+
+	list, err := st.GetAssessedGrowth(childID)
+	if err != nil {
+		t.Fatalf("GetAssessedGrowth: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("got %d entries, want 2", len(list))
+	}
+	birth := list[0]
+	if birth.AgeDays != 0 {
+		t.Errorf("AgeDays = %d, want 0", birth.AgeDays)
+	}
+	if w := birth.WeightForAge; w == nil || w.Z != 0 || w.Percentile != 50 {
+		t.Errorf("WeightForAge = %+v, want z 0 at the 50th percentile", w)
+	}
+	if birth.LengthForAge != nil || birth.WeightForLength != nil {
+		t.Error("scores without a length measurement")
+	}
+
+	later := list[1]
+	if later.WeightForAge != nil || later.LengthForAge != nil {
+		t.Error("age-based scores past the end of the tables")
+	}
+	if later.WeightForLength == nil {
+		t.Error("WeightForLength missing; length is within the tables")
+	}
+}
+
+func TestGetAssessedGrowth_Trends(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st) // a girl born 2024-01-01
+
+	// On the median at two months, then losing weight for a month.
+	st.CreateGrowth(childID, "2024-03-01", intPtr(5100), intPtr(575), nil, "")
+	st.CreateGrowth(childID, "2024-03-05", nil, nil, intPtr(390), "")
+	st.CreateGrowth(childID, "2024-04-01", intPtr(5000), intPtr(610), nil, "")
+
+	list, err := st.GetAssessedGrowth(childID)
+	if err != nil {
+		t.Fatalf("GetAssessedGrowth: %v", err)
+	}
+	if list[0].WeightVelocity != nil || len(list[0].Warnings) != 0 {
+		t.Errorf("first entry has a velocity or warnings: %+v", list[0])
+	}
+	if list[1].WeightVelocity != nil {
+		t.Error("velocity on an entry without a weight")
+	}
+
+	last := list[2]
+	v := last.WeightVelocity
+	if v == nil {
+		t.Fatal("WeightVelocity missing")
+	}
+	if v.Since != "2024-03-01" || v.Days != 31 || v.Rate != -3.2 || v.Unit != "g/day" || v.Status != "low" {
+		t.Errorf("WeightVelocity = %+v, want -3.2 g/day over 31 days, low", v)
+	}
+	if lv := last.LengthVelocity; lv == nil || lv.Unit != "mm/month" || lv.Rate != 34.4 || lv.Status != "normal" {
+		t.Errorf("LengthVelocity = %+v, want 34.4 mm/month, normal", lv)
+	}
+
+	var codes []string
+	for _, w := range last.Warnings {
+		codes = append(codes, w.Code+" "+w.Indicator)
+	}
+	want := []string{"low_velocity weight_velocity", "percentile_drop weight_for_age", "percentile_drop weight_for_length"}
+	if !slices.Equal(codes, want) {
+		t.Errorf("warnings = %+v, want %v", last.Warnings, want)
+	}
+}
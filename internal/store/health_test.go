@@ -0,0 +1,92 @@
+package store_test
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTemperature_FeverByMethod(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	cases := []struct {
+		method  string
+		celsius float64
+		fever   bool
+	}{
+		{"axillary", 37.4, false},
+		{"axillary", 37.5, true},
+		{"rectal", 37.8, false},
+		{"rectal", 38.0, true},
+		{"ear", 38.2, true},
+	}
+	for _, c := range cases {
+		log, err := st.CreateTemperature(childID, "2024-01-15T08:00:00+07:00", c.method, "", c.celsius)
+		if err != nil {
+			t.Fatalf("CreateTemperature: %v", err)
+		}
+		if log.Fever != c.fever {
+			t.Errorf("%s %.1f: Fever = %v, want %v", c.method, c.celsius, log.Fever, c.fever)
+		}
+	}
+
+	// Switching method re-evaluates the stored reading.
+	log, _ := st.CreateTemperature(childID, "2024-01-15T09:00:00+07:00", "rectal", "", 37.6)
+	updated, err := st.UpdateTemperature(log.ID, "", "axillary", "", nil)
+	if err != nil {
+		t.Fatalf("UpdateTemperature: %v", err)
+	}
+	if !updated.Fever || updated.Celsius != 37.6 {
+		t.Errorf("updated = %+v, want a 37.6 axillary fever", updated)
+	}
+}
+
+func TestGetSickEpisodes(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	// Episode one: fever on the 10th, cough on the 11th.
+	st.CreateTemperature(childID, "2024-01-10T20:00:00+07:00", "axillary", "", 38.4)
+	st.CreateTemperature(childID, "2024-01-10T23:00:00+07:00", "axillary", "", 37.9)
+	st.CreateSymptom(childID, "2024-01-11T09:00:00+07:00", "mild", "", []string{"cough", "runny-nose"})
+	med, _ := st.CreateMedication(childID, "Paracetamol", "ml", "", floatPtr(2.5), nil, nil)
+	st.CreateMedicationLog(childID, med.ID, "2024-01-10T20:05:00+07:00", "", nil)
+	// A normal reading on the 12th ends it; a dose outside any episode is left out.
+	st.CreateTemperature(childID, "2024-01-12T08:00:00+07:00", "axillary", "", 36.8)
+	st.CreateMedicationLog(childID, med.ID, "2024-01-12T08:00:00+07:00", "", nil)
+	// Episode two: a rash on the 14th.
+	st.CreateSymptom(childID, "2024-01-14T10:00:00+07:00", "moderate", "", []string{"rash"})
+
+	episodes, err := st.GetSickEpisodes(childID, "2024-01-01", "2024-01-31")
+	if err != nil {
+		t.Fatalf("GetSickEpisodes: %v", err)
+	}
+	if len(episodes) != 2 {
+		t.Fatalf("got %d episodes, want 2", len(episodes))
+	}
+
+	rash := episodes[0]
+	if rash.StartDate != "2024-01-14" || rash.Days != 1 || rash.FeverDays != 0 || rash.MaxCelsius != nil {
+		t.Errorf("rash episode = %+v", rash)
+	}
+
+	cold := episodes[1]
+	if cold.StartDate != "2024-01-10" || cold.EndDate != "2024-01-11" || cold.Days != 2 {
+		t.Errorf("cold episode spans %s..%s (%d days), want 2024-01-10..2024-01-11", cold.StartDate, cold.EndDate, cold.Days)
+	}
+	if cold.FeverDays != 1 || cold.MaxCelsius == nil || *cold.MaxCelsius != 38.4 {
+		t.Errorf("FeverDays = %d, MaxCelsius = %v, want 1 and 38.4", cold.FeverDays, cold.MaxCelsius)
+	}
+	if len(cold.Temperatures) != 2 || len(cold.SymptomLogs) != 1 {
+		t.Errorf("got %d temperatures and %d symptom logs, want 2 and 1", len(cold.Temperatures), len(cold.SymptomLogs))
+	}
+	if !slices.Equal(cold.Symptoms, []string{"cough", "runny-nose"}) {
+		t.Errorf("Symptoms = %v", cold.Symptoms)
+	}
+	if len(cold.Doses) != 1 || cold.Doses[0].MedicationName != "Paracetamol" {
+		t.Errorf("Doses = %+v, want the one Paracetamol dose", cold.Doses)
+	}
+	if cold.Ongoing {
+		t.Error("a past episode should not be ongoing")
+	}
+}
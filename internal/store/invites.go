@@ -0,0 +1,146 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"baby-care/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ErrInviteInvalid is returned when an invite token is unknown, expired or
+// already redeemed.
+var ErrInviteInvalid = errors.New("invite is invalid, expired or already used")
+
+const inviteColumns = `id, role, created_by, created_at, expires_at, accepted_by, accepted_at`
+
+// CreateInvite stores a pending invite under the hash of its token.
+func (s *Store) CreateInvite(tokenHash, role, createdBy string, ttl time.Duration) (*model.Invite, error) {
+	now := time.Now().In(s.Location())
+	inv := &model.Invite{
+		ID:        uuid.NewString(),
+		Role:      role,
+		CreatedBy: createdBy,
+		CreatedAt: now.Format(time.RFC3339),
+		ExpiresAt: now.Add(ttl).Format(time.RFC3339),
+	}
+	err := s.inTx(func(tx *Store) error {
+		if _, err := tx.db.Exec(
+			`INSERT INTO invites (id, token_hash, role, created_by, created_at, expires_at) VALUES (?,?,?,?,?,?)`,
+			inv.ID, tokenHash, inv.Role, inv.CreatedBy, inv.CreatedAt, inv.ExpiresAt,
+		); err != nil {
+			return fmt.Errorf("insert invite: %w", err)
+		}
+		return tx.audit(AuditCreate, "invite", inv.ID, nil, inv)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// ListInvites returns all invites, newest first.
+func (s *Store) ListInvites() ([]*model.Invite, error) {
+	rows, err := s.db.Query(`SELECT ` + inviteColumns + ` FROM invites ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("query invites: %w", err)
+	}
+	defer rows.Close()
+
+	var invites []*model.Invite
+	for rows.Next() {
+		var inv model.Invite
+		if err := rows.Scan(&inv.ID, &inv.Role, &inv.CreatedBy, &inv.CreatedAt, &inv.ExpiresAt, &inv.AcceptedBy, &inv.AcceptedAt); err != nil {
+			return nil, err
+		}
+		invites = append(invites, &inv)
+	}
+	return invites, rows.Err()
+}
+
+// DeleteInvite revokes a pending invite.
+func (s *Store) DeleteInvite(id string) error {
+	return s.inTx(func(tx *Store) error {
+		existing, err := getInviteByID(tx.db, id)
+		if err != nil {
+			return err
+		}
+		res, err := tx.db.Exec(`DELETE FROM invites WHERE id=? AND accepted_at IS NULL`, id)
+		if err != nil {
+			return fmt.Errorf("delete invite: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+		return tx.audit(AuditDelete, "invite", id, existing, nil)
+	})
+}
+
+func getInviteByID(db querier, id string) (*model.Invite, error) {
+	var inv model.Invite
+	err := db.QueryRow(`SELECT `+inviteColumns+` FROM invites WHERE id=?`, id).
+		Scan(&inv.ID, &inv.Role, &inv.CreatedBy, &inv.CreatedAt, &inv.ExpiresAt, &inv.AcceptedBy, &inv.AcceptedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan invite: %w", err)
+	}
+	return &inv, nil
+}
+
+// AcceptInvite redeems a pending invite by creating an account with the
+// invite's role. The invite can only be used once.
+func (s *Store) AcceptInvite(tokenHash, username, passwordHash string) (*model.User, error) {
+	var u *model.User
+	err := s.inTx(func(tx *Store) error {
+		var inviteID, role string
+		err := tx.db.QueryRow(
+			`SELECT id, role FROM invites
+			 WHERE token_hash=? AND accepted_at IS NULL AND unixepoch(expires_at) > unixepoch('now')`,
+			tokenHash,
+		).Scan(&inviteID, &role)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInviteInvalid
+		}
+		if err != nil {
+			return fmt.Errorf("lookup invite: %w", err)
+		}
+
+		now := tx.nowLocal()
+		u = &model.User{
+			ID:        uuid.NewString(),
+			Username:  strings.ToLower(strings.TrimSpace(username)),
+			Role:      role,
+			CreatedAt: now,
+		}
+		if err := insertUser(tx.db, u, passwordHash); err != nil {
+			return err
+		}
+		before, err := getInviteByID(tx.db, inviteID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.db.Exec(`UPDATE invites SET accepted_by=?, accepted_at=? WHERE id=?`, u.ID, now, inviteID); err != nil {
+			return fmt.Errorf("mark invite accepted: %w", err)
+		}
+		after, err := getInviteByID(tx.db, inviteID)
+		if err != nil {
+			return err
+		}
+		// The new caregiver is the actor of their own sign-up.
+		as := tx.WithActor(u.ID)
+		if err := as.audit(AuditCreate, "user", u.ID, nil, u); err != nil {
+			return err
+		}
+		return as.audit(AuditUpdate, "invite", inviteID, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
@@ -0,0 +1,67 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"baby-care/internal/store"
+)
+
+func TestAcceptInvite(t *testing.T) {
+	st := newTestStore(t)
+	mom, _ := st.CreateUser("mom", "hash", "parent")
+
+	inv, err := st.CreateInvite("token-hash", "nanny", mom.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("CreateInvite: %v", err)
+	}
+
+	u, err := st.AcceptInvite("token-hash", "Lan", "pw-hash")
+	if err != nil {
+		t.Fatalf("AcceptInvite: %v", err)
+	}
+	if u.Username != "lan" || u.Role != "nanny" {
+		t.Errorf("user = %+v, want lan/nanny", u)
+	}
+
+	// Invites are single use.
+	if _, err := st.AcceptInvite("token-hash", "other", "pw-hash"); !errors.Is(err, store.ErrInviteInvalid) {
+		t.Errorf("second AcceptInvite error = %v, want ErrInviteInvalid", err)
+	}
+
+	invites, err := st.ListInvites()
+	if err != nil {
+		t.Fatalf("ListInvites: %v", err)
+	}
+	if len(invites) != 1 || invites[0].ID != inv.ID || invites[0].AcceptedBy == nil || *invites[0].AcceptedBy != u.ID {
+		t.Errorf("invites = %+v, want accepted by %s", invites, u.ID)
+	}
+	// Accepted invites can no longer be revoked.
+	if err := st.DeleteInvite(inv.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("DeleteInvite accepted error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestAcceptInvite_ExpiredOrRevoked(t *testing.T) {
+	st := newTestStore(t)
+	mom, _ := st.CreateUser("mom", "hash", "parent")
+
+	if _, err := st.CreateInvite("expired", "nanny", mom.ID, -time.Minute); err != nil {
+		t.Fatalf("CreateInvite: %v", err)
+	}
+	if _, err := st.AcceptInvite("expired", "lan", "pw-hash"); !errors.Is(err, store.ErrInviteInvalid) {
+		t.Errorf("expired AcceptInvite error = %v, want ErrInviteInvalid", err)
+	}
+
+	inv, _ := st.CreateInvite("revoked", "grandparent", mom.ID, time.Hour)
+	if err := st.DeleteInvite(inv.ID); err != nil {
+		t.Fatalf("DeleteInvite: %v", err)
+	}
+	if _, err := st.AcceptInvite("revoked", "ba", "pw-hash"); !errors.Is(err, store.ErrInviteInvalid) {
+		t.Errorf("revoked AcceptInvite error = %v, want ErrInviteInvalid", err)
+	}
+	if n, _ := st.CountUsers(); n != 1 {
+		t.Errorf("CountUsers = %d, want 1", n)
+	}
+}
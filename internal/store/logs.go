@@ -0,0 +1,382 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"baby-care/internal/model"
+)
+
+// logTables maps the API name of each log kind to the table that stores it.
+var logTables = map[string]string{
+	"sleep":          "sleep_logs",
+	"feeding":        "feeding_logs",
+	"pumping":        "pumping_logs",
+	"diaper":         "diaper_logs",
+	"growth":         "growth_logs",
+	"medication_log": "medication_logs",
+	"temperature":    "temperature_logs",
+	"symptom":        "symptom_logs",
+	"vaccination":    "vaccination_logs",
+	"milestone":      "milestones",
+	"solid_feeding":  "solid_feeding_logs",
+}
+
+// LogKinds lists the log kinds in display order.
+var LogKinds = []string{"sleep", "feeding", "pumping", "diaper", "growth", "medication_log", "temperature", "symptom", "vaccination", "milestone", "solid_feeding"}
+
+// LogChildID returns the ID of the child that owns the given log entry.
+// Entries in the trash are not found.
+func (s *Store) LogChildID(kind, id string) (string, error) {
+	return s.logChildID(kind, id, false)
+}
+
+// TrashedLogChildID is LogChildID for entries in the trash only.
+func (s *Store) TrashedLogChildID(kind, id string) (string, error) {
+	return s.logChildID(kind, id, true)
+}
+
+func (s *Store) logChildID(kind, id string, trashed bool) (string, error) {
+	table, ok := logTables[kind]
+	if !ok {
+		return "", fmt.Errorf("unknown log kind %q", kind)
+	}
+	cond := `deleted_at IS NULL`
+	if trashed {
+		cond = `deleted_at IS NOT NULL`
+	}
+	var childID string
+	err := s.db.QueryRow(`SELECT child_id FROM `+table+` WHERE id=? AND `+cond, id).Scan(&childID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("lookup %s owner: %w", kind, err)
+	}
+	return childID, nil
+}
+
+// getLog returns a log entry of any kind, including trashed ones.
+func (s *Store) getLog(kind, id string) (any, error) {
+	switch kind {
+	case "sleep":
+		return getSleepByID(s, id)
+	case "feeding":
+		return getFeedingByID(s, id)
+	case "pumping":
+		return getPumpingByID(s, id)
+	case "diaper":
+		return getDiaperByID(s, id)
+	case "growth":
+		return getGrowthByID(s, id)
+	case "medication_log":
+		return getMedicationLogByID(s, id)
+	case "temperature":
+		return getTemperatureByID(s, id)
+	case "symptom":
+		return getSymptomByID(s, id)
+	case "vaccination":
+		return getVaccinationByID(s, id)
+	case "milestone":
+		return getMilestoneByID(s, id)
+	case "solid_feeding":
+		return getSolidFeedingByID(s, id)
+	}
+	return nil, fmt.Errorf("unknown log kind %q", kind)
+}
+
+// trashLog soft-deletes a log entry by stamping deleted_at.
+func (s *Store) trashLog(kind, id string) error {
+	return s.inTx(func(tx *Store) error {
+		before, err := tx.getLog(kind, id)
+		if err != nil {
+			return err
+		}
+		res, err := tx.db.Exec(`UPDATE `+logTables[kind]+` SET deleted_at=? WHERE id=? AND deleted_at IS NULL`, tx.nowLocal(), id)
+		if err != nil {
+			return fmt.Errorf("delete %s: %w", kind, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+		// A trashed bottle puts its breast milk back in the stash.
+		if kind == "feeding" {
+			if err := tx.returnMilk(id); err != nil {
+				return err
+			}
+		}
+		return tx.audit(AuditDelete, kind, id, before, nil)
+	})
+}
+
+// RestoreLog takes a log entry out of the trash and returns it. A timed entry
+// that would overlap others fails with *ConflictError, whatever the store's
+// resolve mode, and a milestone recorded again since with
+// ErrMilestoneRecorded.
+func (s *Store) RestoreLog(kind, id string) (any, error) {
+	table, ok := logTables[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown log kind %q", kind)
+	}
+	var restored any
+	err := s.inTx(func(tx *Store) error {
+		before, err := tx.getLog(kind, id)
+		if err != nil {
+			return err
+		}
+		if sp, childID, ok := timedSpan(before); ok {
+			if err := tx.WithResolve("").resolveOverlaps(kind, childID, id, &sp); err != nil {
+				return err
+			}
+		}
+		if m, ok := before.(*model.Milestone); ok {
+			if err := checkMilestoneUnrecorded(tx.db, m.ChildID, m.Code, id); err != nil {
+				return err
+			}
+		}
+		res, err := tx.db.Exec(`UPDATE `+table+` SET deleted_at=NULL WHERE id=? AND deleted_at IS NOT NULL`, id)
+		if err != nil {
+			return fmt.Errorf("restore %s: %w", kind, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+		// A restored bottle draws its breast milk again, from whatever the
+		// stash holds now.
+		if feed, ok := before.(*model.FeedingLog); ok {
+			if err := tx.drawMilk(id, feed.ChildID, breastMilkML(feed.FeedType, feed.MilkType, feed.QuantityML)); err != nil {
+				return err
+			}
+		}
+		if restored, err = tx.getLog(kind, id); err != nil {
+			return err
+		}
+		return tx.audit(AuditRestore, kind, id, before, restored)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// ListTrash returns the child's trashed log entries, most recently deleted
+// first.
+func (s *Store) ListTrash(childID string) ([]*model.TrashItem, error) {
+	var items []*model.TrashItem
+	add := func(kind, id string, deletedAt *string, entry any) {
+		items = append(items, &model.TrashItem{Kind: kind, ID: id, DeletedAt: *deletedAt, Entry: entry})
+	}
+
+	for _, kind := range LogKinds {
+		rows, err := s.db.Query(
+			`SELECT `+logColumns(kind)+` FROM `+logTables[kind]+` WHERE child_id=? AND deleted_at IS NOT NULL`,
+			childID,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("query %s trash: %w", kind, err)
+		}
+		switch kind {
+		case "sleep":
+			logs, err := scanSleepRows(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			for _, l := range logs {
+				add(kind, l.ID, l.DeletedAt, l)
+			}
+		case "feeding":
+			logs, err := scanFeedingRows(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if err := s.fillFeedings(logs...); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			for _, l := range logs {
+				add(kind, l.ID, l.DeletedAt, l)
+			}
+		case "pumping":
+			logs, err := scanPumpingRows(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			for _, l := range logs {
+				add(kind, l.ID, l.DeletedAt, l)
+			}
+		case "diaper":
+			logs, err := scanDiaperRows(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			for _, l := range logs {
+				add(kind, l.ID, l.DeletedAt, l)
+			}
+		case "growth":
+			logs, err := scanGrowthRows(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			for _, l := range logs {
+				add(kind, l.ID, l.DeletedAt, l)
+			}
+		case "medication_log":
+			logs, err := scanMedicationLogRows(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			for _, l := range logs {
+				add(kind, l.ID, l.DeletedAt, l)
+			}
+		case "temperature":
+			logs, err := scanTemperatureRows(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			for _, l := range logs {
+				add(kind, l.ID, l.DeletedAt, l)
+			}
+		case "symptom":
+			logs, err := scanSymptomRows(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			for _, l := range logs {
+				add(kind, l.ID, l.DeletedAt, l)
+			}
+		case "vaccination":
+			logs, err := scanVaccinationRows(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			for _, l := range logs {
+				add(kind, l.ID, l.DeletedAt, l)
+			}
+		case "milestone":
+			logs, err := scanMilestoneRows(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			for _, l := range logs {
+				add(kind, l.ID, l.DeletedAt, l)
+			}
+		case "solid_feeding":
+			logs, err := scanSolidFeedingRows(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			for _, l := range logs {
+				add(kind, l.ID, l.DeletedAt, l)
+			}
+		}
+		rows.Close()
+	}
+
+	slices.SortStableFunc(items, func(a, b *model.TrashItem) int {
+		return compareTimestamps(b.DeletedAt, a.DeletedAt)
+	})
+	return items, nil
+}
+
+// PurgeTrash permanently removes log entries that have been in the trash for
+// longer than retention and returns how many were removed. An entry that
+// cannot be removed is left for the next run, and its error joined into the
+// one returned with the count of the rest.
+func (s *Store) PurgeTrash(retention time.Duration) (int, error) {
+	cutoff := time.Now().Add(-retention).Unix()
+	purged := 0
+	var errs []error
+	for _, kind := range LogKinds {
+		table := logTables[kind]
+		rows, err := s.db.Query(`SELECT id FROM `+table+` WHERE deleted_at IS NOT NULL AND unixepoch(deleted_at) <= ?`, cutoff)
+		if err != nil {
+			return purged, errors.Join(append(errs, fmt.Errorf("query %s to purge: %w", kind, err))...)
+		}
+		var ids []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return purged, errors.Join(append(errs, err)...)
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return purged, errors.Join(append(errs, err)...)
+		}
+
+		for _, id := range ids {
+			err := s.inTx(func(tx *Store) error {
+				if _, err := tx.db.Exec(`DELETE FROM `+table+` WHERE id=? AND deleted_at IS NOT NULL`, id); err != nil {
+					return err
+				}
+				if err := tx.deleteLogAttachments(tx.db, kind, id); err != nil {
+					return err
+				}
+				if err := deleteTimerPauses(tx.db, kind, id); err != nil {
+					return err
+				}
+				return tx.audit(AuditPurge, kind, id, nil, nil)
+			})
+			if err != nil {
+				// Leave the entry for the next run rather than holding
+				// back the rest of the trash.
+				errs = append(errs, fmt.Errorf("purge %s %s: %w", kind, id, err))
+				continue
+			}
+			purged++
+		}
+	}
+	return purged, errors.Join(errs...)
+}
+
+func logColumns(kind string) string {
+	switch kind {
+	case "sleep":
+		return sleepColumns
+	case "feeding":
+		return feedingColumns
+	case "pumping":
+		return pumpingColumns
+	case "diaper":
+		return diaperColumns
+	case "growth":
+		return growthColumns
+	case "medication_log":
+		return medicationLogColumns
+	case "temperature":
+		return temperatureColumns
+	case "symptom":
+		return symptomColumns
+	case "vaccination":
+		return vaccinationColumns
+	case "milestone":
+		return milestoneColumns
+	case "solid_feeding":
+		return solidFeedingColumns
+	}
+	return ""
+}
+
+// compareTimestamps orders RFC3339 timestamps by instant, whatever their
+// offsets.
+func compareTimestamps(a, b string) int {
+	ta, _ := time.Parse(time.RFC3339, a)
+	tb, _ := time.Parse(time.RFC3339, b)
+	return ta.Compare(tb)
+}
@@ -0,0 +1,391 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"baby-care/internal/model"
+	"github.com/google/uuid"
+)
+
+// ErrMedicationArchived is returned when logging a dose of an archived
+// medication.
+var ErrMedicationArchived = errors.New("medication is archived")
+
+const medicationColumns = `id, child_id, name, dose_unit, default_dose, min_interval_minutes, max_daily_doses, notes, created_at, COALESCE(created_by,''), archived_at`
+
+const medicationLogColumns = `id, child_id, medication_id, given_at, dose, notes, created_at, COALESCE(created_by,''), deleted_at`
+
+// DoseCheck says whether a medication may be given now under its schedule.
+type DoseCheck struct {
+	MedicationID  string  `json:"medication_id"`
+	CanGive       bool    `json:"can_give"`
+	Reason        string  `json:"reason,omitempty"` // min_interval or max_daily_doses when CanGive is false
+	NextAllowedAt *string `json:"next_allowed_at,omitempty"`
+	LastGivenAt   *string `json:"last_given_at,omitempty"`
+	DosesLast24h  int     `json:"doses_last_24h"`
+	MaxDailyDoses *int    `json:"max_daily_doses,omitempty"`
+}
+
+// GivenDose is a dose along with what was given, for views that list doses
+// of several medications.
+type GivenDose struct {
+	*model.MedicationLog
+	MedicationName string `json:"medication_name"`
+	DoseUnit       string `json:"dose_unit"`
+}
+
+func (s *Store) CreateMedication(childID, name, doseUnit, notes string, defaultDose *float64, minIntervalMinutes, maxDailyDoses *int) (*model.Medication, error) {
+	m := &model.Medication{
+		ID:                 uuid.NewString(),
+		ChildID:            childID,
+		Name:               name,
+		DoseUnit:           doseUnit,
+		DefaultDose:        defaultDose,
+		MinIntervalMinutes: minIntervalMinutes,
+		MaxDailyDoses:      maxDailyDoses,
+		Notes:              notes,
+		CreatedAt:          s.nowLocal(),
+		CreatedBy:          s.actor,
+	}
+	err := s.inTx(func(tx *Store) error {
+		if _, err := tx.db.Exec(
+			`INSERT INTO medications (id, child_id, name, dose_unit, default_dose, min_interval_minutes, max_daily_doses, notes, created_at, created_by) VALUES (?,?,?,?,?,?,?,?,?,?)`,
+			m.ID, m.ChildID, m.Name, m.DoseUnit, m.DefaultDose, m.MinIntervalMinutes, m.MaxDailyDoses, m.Notes, m.CreatedAt, tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert medication: %w", err)
+		}
+		return tx.audit(AuditCreate, "medication", m.ID, nil, m)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ListMedications returns the child's medications that are not archived, by
+// name.
+func (s *Store) ListMedications(childID string) ([]*model.Medication, error) {
+	rows, err := s.db.Query(
+		`SELECT `+medicationColumns+` FROM medications WHERE child_id=? AND archived_at IS NULL ORDER BY name COLLATE NOCASE`,
+		childID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query medications: %w", err)
+	}
+	defer rows.Close()
+	var meds []*model.Medication
+	for rows.Next() {
+		m, err := scanMedication(rows)
+		if err != nil {
+			return nil, err
+		}
+		meds = append(meds, m)
+	}
+	return meds, rows.Err()
+}
+
+// GetMedication returns a medication, including archived ones.
+func (s *Store) GetMedication(id string) (*model.Medication, error) {
+	m, err := scanMedication(s.db.QueryRow(`SELECT `+medicationColumns+` FROM medications WHERE id=?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return m, err
+}
+
+// UpdateMedication replaces a medication's details; empty name and doseUnit
+// keep the stored values.
+func (s *Store) UpdateMedication(id, name, doseUnit, notes string, defaultDose *float64, minIntervalMinutes, maxDailyDoses *int) (*model.Medication, error) {
+	var updated *model.Medication
+	err := s.inTx(func(tx *Store) error {
+		existing, err := tx.GetMedication(id)
+		if err != nil {
+			return err
+		}
+		if existing.ArchivedAt != nil {
+			return ErrNotFound
+		}
+		if name == "" {
+			name = existing.Name
+		}
+		if doseUnit == "" {
+			doseUnit = existing.DoseUnit
+		}
+		_, err = tx.db.Exec(
+			`UPDATE medications SET name=?, dose_unit=?, default_dose=?, min_interval_minutes=?, max_daily_doses=?, notes=? WHERE id=?`,
+			name, doseUnit, defaultDose, minIntervalMinutes, maxDailyDoses, notes, id,
+		)
+		if err != nil {
+			return fmt.Errorf("update medication: %w", err)
+		}
+		updated, err = tx.GetMedication(id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "medication", id, existing, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ArchiveMedication hides a medication from the list and stops new doses. Its
+// dose history is kept.
+func (s *Store) ArchiveMedication(id string) error {
+	return s.inTx(func(tx *Store) error {
+		existing, err := tx.GetMedication(id)
+		if err != nil {
+			return err
+		}
+		res, err := tx.db.Exec(`UPDATE medications SET archived_at=? WHERE id=? AND archived_at IS NULL`, tx.nowLocal(), id)
+		if err != nil {
+			return fmt.Errorf("archive medication: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+		return tx.audit(AuditDelete, "medication", id, existing, nil)
+	})
+}
+
+// CreateMedicationLog records a dose. A nil dose falls back to the
+// medication's default dose.
+func (s *Store) CreateMedicationLog(childID, medicationID, givenAt, notes string, dose *float64) (*model.MedicationLog, error) {
+	med, err := s.GetMedication(medicationID)
+	if err != nil {
+		return nil, err
+	}
+	if med.ChildID != childID {
+		return nil, ErrNotFound
+	}
+	if med.ArchivedAt != nil {
+		return nil, ErrMedicationArchived
+	}
+	if dose == nil {
+		dose = med.DefaultDose
+	}
+	now := s.nowLocal()
+	if givenAt == "" {
+		givenAt = now
+	}
+	log := &model.MedicationLog{
+		ID:           uuid.NewString(),
+		ChildID:      childID,
+		MedicationID: medicationID,
+		GivenAt:      givenAt,
+		Dose:         dose,
+		Notes:        notes,
+		CreatedAt:    now,
+		CreatedBy:    s.actor,
+	}
+	err = s.inTx(func(tx *Store) error {
+		if _, err := tx.db.Exec(
+			`INSERT INTO medication_logs (id, child_id, medication_id, given_at, dose, notes, created_at, created_by) VALUES (?,?,?,?,?,?,?,?)`,
+			log.ID, log.ChildID, log.MedicationID, log.GivenAt, log.Dose, log.Notes, log.CreatedAt, tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert medication log: %w", err)
+		}
+		return tx.audit(AuditCreate, "medication_log", log.ID, nil, log)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// GetMedicationLogs lists the child's doses, newest first, optionally for one
+// day and one medication.
+func (s *Store) GetMedicationLogs(childID, date, medicationID string) ([]*model.MedicationLog, error) {
+	query := `SELECT ` + medicationLogColumns + ` FROM medication_logs WHERE child_id=? AND deleted_at IS NULL`
+	args := []any{childID}
+	if date != "" {
+		start, end, err := s.dayBounds(date)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND unixepoch(given_at) >= ? AND unixepoch(given_at) < ?`
+		args = append(args, start, end)
+	}
+	if medicationID != "" {
+		query += ` AND medication_id=?`
+		args = append(args, medicationID)
+	}
+	query += ` ORDER BY unixepoch(given_at) DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query medication logs: %w", err)
+	}
+	defer rows.Close()
+	return scanMedicationLogRows(rows)
+}
+
+func (s *Store) UpdateMedicationLog(id, givenAt, notes string, dose *float64) (*model.MedicationLog, error) {
+	var updated *model.MedicationLog
+	err := s.inTx(func(tx *Store) error {
+		existing, err := getMedicationLogByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if existing.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if givenAt == "" {
+			givenAt = existing.GivenAt
+		}
+		if dose == nil {
+			dose = existing.Dose
+		}
+		_, err = tx.db.Exec(
+			`UPDATE medication_logs SET given_at=?, dose=?, notes=? WHERE id=?`,
+			givenAt, dose, notes, id,
+		)
+		if err != nil {
+			return fmt.Errorf("update medication log: %w", err)
+		}
+		updated, err = getMedicationLogByID(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "medication_log", id, existing, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteMedicationLog moves a dose to the trash; see RestoreLog.
+func (s *Store) DeleteMedicationLog(id string) error {
+	return s.trashLog("medication_log", id)
+}
+
+// CheckDose reports whether the medication may be given at now: at least its
+// minimum interval after the last dose, and fewer than its maximum doses in
+// the 24 hours before. When it may not, NextAllowedAt is the earliest time
+// both limits are met.
+func (s *Store) CheckDose(medicationID string, now time.Time) (*DoseCheck, error) {
+	med, err := s.GetMedication(medicationID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(
+		`SELECT given_at FROM medication_logs
+		 WHERE medication_id=? AND deleted_at IS NULL AND unixepoch(given_at) <= ?
+		 ORDER BY unixepoch(given_at) DESC`,
+		medicationID, now.Unix(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query doses: %w", err)
+	}
+	defer rows.Close()
+	// given collects the doses within the last 24 hours, newest first.
+	var given []time.Time
+	var last string
+	for rows.Next() {
+		var ts string
+		if err := rows.Scan(&ts); err != nil {
+			return nil, err
+		}
+		if last == "" {
+			last = ts
+		}
+		t, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			continue
+		}
+		if now.Sub(t) >= 24*time.Hour {
+			break
+		}
+		given = append(given, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	check := &DoseCheck{MedicationID: med.ID, CanGive: true, DosesLast24h: len(given), MaxDailyDoses: med.MaxDailyDoses}
+	if last != "" {
+		check.LastGivenAt = &last
+	}
+	next := now
+	if med.MinIntervalMinutes != nil && last != "" {
+		lastAt, _ := time.Parse(time.RFC3339, last)
+		if t := lastAt.Add(time.Duration(*med.MinIntervalMinutes) * time.Minute); t.After(next) {
+			next, check.Reason = t, "min_interval"
+		}
+	}
+	if med.MaxDailyDoses != nil && len(given) >= *med.MaxDailyDoses {
+		// A slot frees up 24 hours after the oldest dose that keeps the
+		// window full.
+		oldest := given[*med.MaxDailyDoses-1]
+		if t := oldest.Add(24 * time.Hour); t.After(next) {
+			next, check.Reason = t, "max_daily_doses"
+		}
+	}
+	if next.After(now) {
+		check.CanGive = false
+		at := next.In(s.Location()).Format(time.RFC3339)
+		check.NextAllowedAt = &at
+	}
+	return check, nil
+}
+
+// lastDose returns the child's most recent dose of any medication.
+func (s *Store) lastDose(childID string) (*GivenDose, error) {
+	log, err := scanMedicationLogRow(s.db.QueryRow(
+		`SELECT `+medicationLogColumns+` FROM medication_logs WHERE child_id=? AND deleted_at IS NULL ORDER BY unixepoch(given_at) DESC LIMIT 1`,
+		childID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	med, err := s.GetMedication(log.MedicationID)
+	if err != nil {
+		return nil, err
+	}
+	return &GivenDose{MedicationLog: log, MedicationName: med.Name, DoseUnit: med.DoseUnit}, nil
+}
+
+func scanMedication(row rowScanner) (*model.Medication, error) {
+	var m model.Medication
+	err := row.Scan(&m.ID, &m.ChildID, &m.Name, &m.DoseUnit, &m.DefaultDose, &m.MinIntervalMinutes, &m.MaxDailyDoses, &m.Notes, &m.CreatedAt, &m.CreatedBy, &m.ArchivedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func getMedicationLogByID(s *Store, id string) (*model.MedicationLog, error) {
+	row := s.db.QueryRow(
+		`SELECT `+medicationLogColumns+` FROM medication_logs WHERE id=?`, id,
+	)
+	return scanMedicationLogRow(row)
+}
+
+func scanMedicationLogRow(row *sql.Row) (*model.MedicationLog, error) {
+	var l model.MedicationLog
+	err := row.Scan(&l.ID, &l.ChildID, &l.MedicationID, &l.GivenAt, &l.Dose, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func scanMedicationLogRows(rows *sql.Rows) ([]*model.MedicationLog, error) {
+	var logs []*model.MedicationLog
+	for rows.Next() {
+		var l model.MedicationLog
+		if err := rows.Scan(&l.ID, &l.ChildID, &l.MedicationID, &l.GivenAt, &l.Dose, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt); err != nil {
+			return nil, err
+		}
+		logs = append(logs, &l)
+	}
+	return logs, rows.Err()
+}
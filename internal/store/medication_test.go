@@ -0,0 +1,95 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"baby-care/internal/store"
+)
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestCheckDose_MinInterval(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	med, err := st.CreateMedication(childID, "Paracetamol", "ml", "", floatPtr(2.5), intPtr(240), intPtr(4))
+	if err != nil {
+		t.Fatalf("CreateMedication: %v", err)
+	}
+
+	dose, err := st.CreateMedicationLog(childID, med.ID, "2024-01-15T08:00:00+07:00", "", nil)
+	if err != nil {
+		t.Fatalf("CreateMedicationLog: %v", err)
+	}
+	if dose.Dose == nil || *dose.Dose != 2.5 {
+		t.Errorf("Dose = %v, want the 2.5 default", dose.Dose)
+	}
+
+	now, _ := time.Parse(time.RFC3339, "2024-01-15T10:00:00+07:00")
+	check, err := st.CheckDose(med.ID, now)
+	if err != nil {
+		t.Fatalf("CheckDose: %v", err)
+	}
+	if check.CanGive || check.Reason != "min_interval" {
+		t.Errorf("check = %+v, want blocked by min_interval", check)
+	}
+	if check.NextAllowedAt == nil || *check.NextAllowedAt != "2024-01-15T12:00:00+07:00" {
+		t.Errorf("NextAllowedAt = %v, want 12:00", check.NextAllowedAt)
+	}
+
+	check, _ = st.CheckDose(med.ID, now.Add(2*time.Hour))
+	if !check.CanGive || check.NextAllowedAt != nil {
+		t.Errorf("check at 12:00 = %+v, want allowed", check)
+	}
+}
+
+func TestCheckDose_MaxDailyDoses(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	med, _ := st.CreateMedication(childID, "Ibuprofen", "ml", "", nil, intPtr(60), intPtr(3))
+	for _, at := range []string{"2024-01-15T06:00:00+07:00", "2024-01-15T12:00:00+07:00", "2024-01-15T18:00:00+07:00"} {
+		if _, err := st.CreateMedicationLog(childID, med.ID, at, "", floatPtr(5)); err != nil {
+			t.Fatalf("CreateMedicationLog: %v", err)
+		}
+	}
+
+	now, _ := time.Parse(time.RFC3339, "2024-01-15T22:00:00+07:00")
+	check, err := st.CheckDose(med.ID, now)
+	if err != nil {
+		t.Fatalf("CheckDose: %v", err)
+	}
+	if check.CanGive || check.Reason != "max_daily_doses" || check.DosesLast24h != 3 {
+		t.Errorf("check = %+v, want blocked by max_daily_doses after 3 doses", check)
+	}
+	// The 06:00 dose leaves the 24 hour window the next morning.
+	if check.NextAllowedAt == nil || *check.NextAllowedAt != "2024-01-16T06:00:00+07:00" {
+		t.Errorf("NextAllowedAt = %v, want 06:00 next day", check.NextAllowedAt)
+	}
+}
+
+func TestMedication_ArchiveAndSummary(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	med, _ := st.CreateMedication(childID, "Vitamin D", "drops", "", floatPtr(1), nil, intPtr(1))
+	st.CreateMedicationLog(childID, med.ID, "2024-01-15T09:00:00+07:00", "", nil)
+
+	summary, err := st.GetDaySummary(childID, "2024-01-16")
+	if err != nil {
+		t.Fatalf("GetDaySummary: %v", err)
+	}
+	if summary.LastDose == nil || summary.LastDose.MedicationName != "Vitamin D" || summary.LastDose.GivenAt != "2024-01-15T09:00:00+07:00" {
+		t.Errorf("LastDose = %+v, want the vitamin D dose", summary.LastDose)
+	}
+
+	if err := st.ArchiveMedication(med.ID); err != nil {
+		t.Fatalf("ArchiveMedication: %v", err)
+	}
+	meds, _ := st.ListMedications(childID)
+	if len(meds) != 0 {
+		t.Errorf("ListMedications = %d, want archived medication hidden", len(meds))
+	}
+	if _, err := st.CreateMedicationLog(childID, med.ID, "", "", nil); !errors.Is(err, store.ErrMedicationArchived) {
+		t.Errorf("dose of archived medication err = %v, want ErrMedicationArchived", err)
+	}
+}
@@ -0,0 +1,588 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSchemaTooNew is returned when the database has migrations applied that
+// this binary does not know about, i.e. it was last opened by a newer release.
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary")
+
+// migration is one numbered schema step. Released migrations must never be
+// edited or reordered; append a new one instead.
+type migration struct {
+	version int
+	name    string
+	stmts   []string
+}
+
+// migrations is the ordered list of schema steps. Version 1 uses
+// IF NOT EXISTS so databases created before schema_migrations existed are
+// adopted without changes.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "initial schema",
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS children (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				date_of_birth TEXT NOT NULL,
+				gender TEXT NOT NULL,
+				photo_url TEXT DEFAULT '',
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL,
+				updated_at TEXT NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS sleep_logs (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				start_time TEXT NOT NULL,
+				end_time TEXT,
+				duration_minutes INTEGER,
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_sleep_child_start ON sleep_logs(child_id, start_time)`,
+			`CREATE TABLE IF NOT EXISTS feeding_logs (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				feed_type TEXT NOT NULL,
+				start_time TEXT NOT NULL,
+				end_time TEXT,
+				duration_minutes INTEGER,
+				quantity_ml INTEGER,
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_feeding_child_start ON feeding_logs(child_id, start_time)`,
+			`CREATE TABLE IF NOT EXISTS diaper_logs (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				diaper_type TEXT NOT NULL,
+				changed_at TEXT NOT NULL,
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_diaper_child_changed ON diaper_logs(child_id, changed_at)`,
+			`CREATE TABLE IF NOT EXISTS growth_logs (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				measured_on TEXT NOT NULL,
+				weight_grams INTEGER,
+				length_mm INTEGER,
+				head_circumference_mm INTEGER,
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_growth_child_measured ON growth_logs(child_id, measured_on)`,
+		},
+	},
+	{
+		version: 2,
+		name:    "household settings",
+		stmts: []string{
+			`CREATE TABLE settings (
+				key TEXT PRIMARY KEY,
+				value TEXT NOT NULL,
+				updated_at TEXT NOT NULL
+			)`,
+		},
+	},
+	{
+		version: 3,
+		name:    "users and sessions",
+		stmts: []string{
+			`CREATE TABLE users (
+				id TEXT PRIMARY KEY,
+				username TEXT NOT NULL UNIQUE,
+				password_hash TEXT NOT NULL,
+				created_at TEXT NOT NULL,
+				updated_at TEXT NOT NULL
+			)`,
+			`CREATE TABLE sessions (
+				token_hash TEXT PRIMARY KEY,
+				user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				created_at TEXT NOT NULL,
+				expires_at TEXT NOT NULL
+			)`,
+			`CREATE INDEX idx_sessions_user ON sessions(user_id)`,
+		},
+	},
+	{
+		version: 4,
+		name:    "caregiver roles, invites and created_by",
+		stmts: []string{
+			// Accounts created before roles existed were all parents.
+			`ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'parent'`,
+			`CREATE TABLE invites (
+				id TEXT PRIMARY KEY,
+				token_hash TEXT NOT NULL UNIQUE,
+				role TEXT NOT NULL,
+				created_by TEXT NOT NULL REFERENCES users(id),
+				created_at TEXT NOT NULL,
+				expires_at TEXT NOT NULL,
+				accepted_by TEXT REFERENCES users(id),
+				accepted_at TEXT
+			)`,
+			`ALTER TABLE sleep_logs ADD COLUMN created_by TEXT REFERENCES users(id)`,
+			`ALTER TABLE feeding_logs ADD COLUMN created_by TEXT REFERENCES users(id)`,
+			`ALTER TABLE diaper_logs ADD COLUMN created_by TEXT REFERENCES users(id)`,
+			`ALTER TABLE growth_logs ADD COLUMN created_by TEXT REFERENCES users(id)`,
+		},
+	},
+	{
+		version: 5,
+		name:    "api tokens",
+		stmts: []string{
+			`CREATE TABLE api_tokens (
+				id TEXT PRIMARY KEY,
+				user_id TEXT NOT NULL REFERENCES users(id),
+				name TEXT NOT NULL,
+				token_hash TEXT NOT NULL UNIQUE,
+				scopes TEXT NOT NULL,             -- space-separated permissions
+				created_at TEXT NOT NULL,
+				last_used_at TEXT
+			)`,
+			`CREATE INDEX idx_api_tokens_user ON api_tokens(user_id)`,
+		},
+	},
+	{
+		version: 6,
+		name:    "audit log",
+		stmts: []string{
+			`CREATE TABLE audit_log (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				actor_id TEXT REFERENCES users(id), -- NULL for the CLI and background jobs
+				action TEXT NOT NULL CHECK(action IN ('create','update','delete')),
+				entity TEXT NOT NULL,
+				entity_id TEXT NOT NULL,
+				before TEXT,                         -- JSON, NULL on create
+				after TEXT,                          -- JSON, NULL on delete
+				created_at TEXT NOT NULL
+			)`,
+			`CREATE INDEX idx_audit_log_entity ON audit_log(entity, entity_id)`,
+		},
+	},
+	{
+		version: 7,
+		name:    "soft delete",
+		stmts: []string{
+			`ALTER TABLE sleep_logs ADD COLUMN deleted_at TEXT`,
+			`ALTER TABLE feeding_logs ADD COLUMN deleted_at TEXT`,
+			`ALTER TABLE diaper_logs ADD COLUMN deleted_at TEXT`,
+			`ALTER TABLE growth_logs ADD COLUMN deleted_at TEXT`,
+			// SQLite cannot alter a CHECK constraint, so rebuild audit_log
+			// to allow the restore and purge actions.
+			`CREATE TABLE audit_log_new (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				actor_id TEXT REFERENCES users(id),
+				action TEXT NOT NULL CHECK(action IN ('create','update','delete','restore','purge')),
+				entity TEXT NOT NULL,
+				entity_id TEXT NOT NULL,
+				before TEXT,
+				after TEXT,
+				created_at TEXT NOT NULL
+			)`,
+			`INSERT INTO audit_log_new SELECT * FROM audit_log`,
+			`DROP TABLE audit_log`,
+			`ALTER TABLE audit_log_new RENAME TO audit_log`,
+			`CREATE INDEX idx_audit_log_entity ON audit_log(entity, entity_id)`,
+		},
+	},
+	{
+		version: 8,
+		name:    "pumping logs",
+		stmts: []string{
+			`CREATE TABLE pumping_logs (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				start_time TEXT NOT NULL,
+				end_time TEXT,                    -- NULL = currently pumping
+				duration_minutes INTEGER,
+				left_ml INTEGER,
+				right_ml INTEGER,
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL,
+				created_by TEXT REFERENCES users(id),
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX idx_pumping_child_start ON pumping_logs(child_id, start_time)`,
+		},
+	},
+	{
+		version: 9,
+		name:    "milk stash",
+		stmts: []string{
+			`ALTER TABLE feeding_logs ADD COLUMN milk_type TEXT`, // bottle only: breast_milk or formula
+			`CREATE TABLE milk_bags (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				pumping_id TEXT REFERENCES pumping_logs(id),
+				volume_ml INTEGER NOT NULL,
+				remaining_ml INTEGER NOT NULL,
+				location TEXT NOT NULL CHECK(location IN ('room','fridge','freezer')),
+				stored_at TEXT NOT NULL,
+				thawed_at TEXT,
+				expires_at TEXT NOT NULL,
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL,
+				created_by TEXT REFERENCES users(id)
+			)`,
+			`CREATE INDEX idx_milk_bags_child_stored ON milk_bags(child_id, stored_at)`,
+		},
+	},
+	{
+		version: 10,
+		name:    "medications",
+		stmts: []string{
+			`CREATE TABLE medications (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				name TEXT NOT NULL,
+				dose_unit TEXT NOT NULL,           -- ml, mg, drops, ...
+				default_dose REAL,
+				min_interval_minutes INTEGER,     -- NULL = no minimum
+				max_daily_doses INTEGER,          -- per rolling 24 hours; NULL = no limit
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL,
+				created_by TEXT REFERENCES users(id),
+				archived_at TEXT
+			)`,
+			`CREATE INDEX idx_medications_child ON medications(child_id)`,
+			`CREATE TABLE medication_logs (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				medication_id TEXT NOT NULL REFERENCES medications(id),
+				given_at TEXT NOT NULL,
+				dose REAL,
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL,
+				created_by TEXT REFERENCES users(id),
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX idx_medication_logs_child_given ON medication_logs(child_id, given_at)`,
+			`CREATE INDEX idx_medication_logs_medication ON medication_logs(medication_id, given_at)`,
+		},
+	},
+	{
+		version: 11,
+		name:    "temperature and symptoms",
+		stmts: []string{
+			`CREATE TABLE temperature_logs (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				taken_at TEXT NOT NULL,
+				celsius REAL NOT NULL,
+				method TEXT NOT NULL CHECK(method IN ('axillary','rectal','ear')),
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL,
+				created_by TEXT REFERENCES users(id),
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX idx_temperature_child_taken ON temperature_logs(child_id, taken_at)`,
+			`CREATE TABLE symptom_logs (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				observed_at TEXT NOT NULL,
+				symptoms TEXT NOT NULL,            -- space-separated tags
+				severity TEXT NOT NULL CHECK(severity IN ('mild','moderate','severe')),
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL,
+				created_by TEXT REFERENCES users(id),
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX idx_symptom_child_observed ON symptom_logs(child_id, observed_at)`,
+		},
+	},
+	{
+		version: 12,
+		name:    "vaccination logs",
+		stmts: []string{
+			`CREATE TABLE vaccination_logs (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				vaccine TEXT NOT NULL,
+				dose_number INTEGER NOT NULL,
+				given_on TEXT NOT NULL,
+				clinic TEXT DEFAULT '',
+				lot_number TEXT DEFAULT '',
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL,
+				created_by TEXT REFERENCES users(id),
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX idx_vaccination_child_given ON vaccination_logs(child_id, given_on)`,
+		},
+	},
+	{
+		version: 13,
+		name:    "milestones",
+		stmts: []string{
+			`CREATE TABLE milestones (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				code TEXT,                         -- catalogue ID, NULL for custom milestones
+				title TEXT NOT NULL,
+				category TEXT NOT NULL CHECK(category IN ('motor','social','language','cognitive','physical')),
+				achieved_on TEXT NOT NULL,
+				photo_url TEXT DEFAULT '',
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL,
+				created_by TEXT REFERENCES users(id),
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX idx_milestones_child_achieved ON milestones(child_id, achieved_on)`,
+		},
+	},
+	{
+		version: 14,
+		name:    "solid foods",
+		stmts: []string{
+			`CREATE TABLE foods (
+				id TEXT PRIMARY KEY,               -- catalogue ID, or a UUID for custom foods
+				name TEXT NOT NULL,
+				category TEXT NOT NULL,
+				allergens TEXT NOT NULL DEFAULT '', -- space-separated allergen codes
+				custom INTEGER NOT NULL DEFAULT 0,
+				created_at TEXT NOT NULL,
+				created_by TEXT REFERENCES users(id)
+			)`,
+			`CREATE TABLE solid_feeding_logs (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				food_id TEXT NOT NULL REFERENCES foods(id),
+				fed_at TEXT NOT NULL,
+				amount_grams INTEGER,
+				reaction TEXT NOT NULL DEFAULT 'none' CHECK(reaction IN ('none','mild','moderate','severe')),
+				reaction_notes TEXT DEFAULT '',
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL,
+				created_by TEXT REFERENCES users(id),
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX idx_solid_feeding_child_fed ON solid_feeding_logs(child_id, fed_at)`,
+		},
+	},
+	{
+		version: 15,
+		name:    "attachments",
+		stmts: []string{
+			`CREATE TABLE attachments (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				log_kind TEXT NOT NULL,            -- a log kind, or 'child' for the child itself
+				log_id TEXT NOT NULL,
+				filename TEXT NOT NULL,
+				content_type TEXT NOT NULL,
+				size_bytes INTEGER NOT NULL,
+				sha256 TEXT NOT NULL,
+				thumbnail_sha256 TEXT,
+				created_at TEXT NOT NULL,
+				created_by TEXT REFERENCES users(id)
+			)`,
+			`CREATE INDEX idx_attachments_log ON attachments(log_kind, log_id)`,
+		},
+	},
+	{
+		version: 16,
+		name:    "feeding segments",
+		stmts: []string{
+			`CREATE TABLE feeding_segments (
+				feeding_id TEXT NOT NULL REFERENCES feeding_logs(id) ON DELETE CASCADE,
+				seq INTEGER NOT NULL,              -- 0 for the side the feed started on
+				side TEXT NOT NULL CHECK(side IN ('left','right')),
+				start_time TEXT NOT NULL,
+				end_time TEXT,
+				PRIMARY KEY (feeding_id, seq)
+			)`,
+			// Existing breast feeds become a single segment on their side.
+			`INSERT INTO feeding_segments (feeding_id, seq, side, start_time, end_time)
+				SELECT id, 0, CASE feed_type WHEN 'breast_left' THEN 'left' ELSE 'right' END, start_time, end_time
+				FROM feeding_logs WHERE feed_type IN ('breast_left','breast_right')`,
+		},
+	},
+	{
+		version: 17,
+		name:    "timer pauses",
+		stmts: []string{
+			`CREATE TABLE timer_pauses (
+				id TEXT PRIMARY KEY,
+				log_kind TEXT NOT NULL CHECK(log_kind IN ('sleep','feeding')),
+				log_id TEXT NOT NULL,
+				paused_at TEXT NOT NULL,
+				resumed_at TEXT                    -- NULL while paused
+			)`,
+			`CREATE INDEX idx_timer_pauses_log ON timer_pauses(log_kind, log_id)`,
+		},
+	},
+	{
+		version: 18,
+		name:    "instant indexes",
+		stmts: []string{
+			// Timestamps keep the offset they were logged with, so ranges
+			// and ordering compare instants rather than the raw strings.
+			`CREATE INDEX idx_sleep_child_start_instant ON sleep_logs(child_id, unixepoch(start_time))`,
+			`CREATE INDEX idx_feeding_child_start_instant ON feeding_logs(child_id, unixepoch(start_time))`,
+			`CREATE INDEX idx_pumping_child_start_instant ON pumping_logs(child_id, unixepoch(start_time))`,
+			`CREATE INDEX idx_diaper_child_changed_instant ON diaper_logs(child_id, unixepoch(changed_at))`,
+		},
+	},
+	{
+		version: 19,
+		name:    "milk bags outlive pumping",
+		stmts: []string{
+			// A bag keeps its milk when the session it came from is purged
+			// from the trash; SQLite can only change a foreign key by
+			// rebuilding the table.
+			`CREATE TABLE milk_bags_new (
+				id TEXT PRIMARY KEY,
+				child_id TEXT NOT NULL REFERENCES children(id),
+				pumping_id TEXT REFERENCES pumping_logs(id) ON DELETE SET NULL,
+				volume_ml INTEGER NOT NULL,
+				remaining_ml INTEGER NOT NULL,
+				location TEXT NOT NULL CHECK(location IN ('room','fridge','freezer')),
+				stored_at TEXT NOT NULL,
+				thawed_at TEXT,
+				expires_at TEXT NOT NULL,
+				notes TEXT DEFAULT '',
+				created_at TEXT NOT NULL,
+				created_by TEXT REFERENCES users(id)
+			)`,
+			`INSERT INTO milk_bags_new SELECT id, child_id, pumping_id, volume_ml, remaining_ml, location, stored_at, thawed_at, expires_at, notes, created_at, created_by FROM milk_bags`,
+			`DROP TABLE milk_bags`,
+			`ALTER TABLE milk_bags_new RENAME TO milk_bags`,
+			`CREATE INDEX idx_milk_bags_child_stored ON milk_bags(child_id, stored_at)`,
+		},
+	},
+	{
+		version: 20,
+		name:    "feeding milk uses",
+		stmts: []string{
+			`CREATE TABLE feeding_milk_uses (
+				feeding_id TEXT NOT NULL REFERENCES feeding_logs(id) ON DELETE CASCADE,
+				bag_id TEXT NOT NULL REFERENCES milk_bags(id) ON DELETE CASCADE,
+				ml INTEGER NOT NULL,
+				PRIMARY KEY (feeding_id, bag_id)
+			)`,
+			`CREATE INDEX idx_feeding_milk_uses_bag ON feeding_milk_uses(bag_id)`,
+		},
+	},
+}
+
+// MigrationStatus describes a known migration and when it was applied.
+// AppliedAt is empty for pending migrations.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt string
+}
+
+// LatestSchemaVersion is the schema version this binary migrates to.
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// SchemaVersion returns the highest migration version applied to the database.
+func (s *Store) SchemaVersion() (int, error) {
+	if err := s.ensureMigrationsTable(); err != nil {
+		return 0, err
+	}
+	var v int
+	if err := s.db.QueryRow(`SELECT COALESCE(MAX(version),0) FROM schema_migrations`).Scan(&v); err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+	return v, nil
+}
+
+// MigrationStatus lists every known migration along with its applied time.
+func (s *Store) MigrationStatus() ([]MigrationStatus, error) {
+	if err := s.ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+	applied := map[int]string{}
+	rows, err := s.db.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v int
+		var at string
+		if err := rows.Scan(&v, &at); err != nil {
+			return nil, err
+		}
+		applied[v] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		out = append(out, MigrationStatus{Version: m.version, Name: m.name, AppliedAt: applied[m.version]})
+	}
+	return out, nil
+}
+
+// Migrate applies all pending migrations in order, each in its own
+// transaction, and returns how many were applied. It refuses to touch a
+// database whose schema is newer than this binary.
+func (s *Store) Migrate() (int, error) {
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return 0, err
+	}
+	if current > LatestSchemaVersion() {
+		return 0, fmt.Errorf("%w: database is at version %d, binary supports %d", ErrSchemaTooNew, current, LatestSchemaVersion())
+	}
+
+	applied := 0
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := s.applyMigration(m); err != nil {
+			return applied, err
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+func (s *Store) ensureMigrationsTable() error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) applyMigration(m migration) error {
+	tx, err := s.pool.Begin()
+	if err != nil {
+		return fmt.Errorf("begin migration %d: %w", m.version, err)
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range m.stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?,?,?)`,
+		m.version, m.name, s.nowLocal(),
+	); err != nil {
+		return fmt.Errorf("record migration %d: %w", m.version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration %d: %w", m.version, err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package store_test
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"baby-care/internal/store"
+
+	_ "modernc.org/sqlite"
+)
+
+// legacySchema is the schema created by releases that predate
+// schema_migrations, when Store.migrate ran bare CREATE TABLE statements.
+var legacySchema = []string{
+	`CREATE TABLE children (id TEXT PRIMARY KEY, name TEXT NOT NULL, date_of_birth TEXT NOT NULL, gender TEXT NOT NULL, photo_url TEXT DEFAULT '', notes TEXT DEFAULT '', created_at TEXT NOT NULL, updated_at TEXT NOT NULL)`,
+	`CREATE TABLE sleep_logs (id TEXT PRIMARY KEY, child_id TEXT NOT NULL REFERENCES children(id), start_time TEXT NOT NULL, end_time TEXT, duration_minutes INTEGER, notes TEXT DEFAULT '', created_at TEXT NOT NULL)`,
+	`CREATE INDEX idx_sleep_child_start ON sleep_logs(child_id, start_time)`,
+	`CREATE TABLE feeding_logs (id TEXT PRIMARY KEY, child_id TEXT NOT NULL REFERENCES children(id), feed_type TEXT NOT NULL, start_time TEXT NOT NULL, end_time TEXT, duration_minutes INTEGER, quantity_ml INTEGER, notes TEXT DEFAULT '', created_at TEXT NOT NULL)`,
+	`CREATE INDEX idx_feeding_child_start ON feeding_logs(child_id, start_time)`,
+	`CREATE TABLE diaper_logs (id TEXT PRIMARY KEY, child_id TEXT NOT NULL REFERENCES children(id), diaper_type TEXT NOT NULL, changed_at TEXT NOT NULL, notes TEXT DEFAULT '', created_at TEXT NOT NULL)`,
+	`CREATE INDEX idx_diaper_child_changed ON diaper_logs(child_id, changed_at)`,
+	`CREATE TABLE growth_logs (id TEXT PRIMARY KEY, child_id TEXT NOT NULL REFERENCES children(id), measured_on TEXT NOT NULL, weight_grams INTEGER, length_mm INTEGER, head_circumference_mm INTEGER, notes TEXT DEFAULT '', created_at TEXT NOT NULL)`,
+	`CREATE INDEX idx_growth_child_measured ON growth_logs(child_id, measured_on)`,
+}
+
+// createLegacyDB writes a database the way pre-migration releases did and
+// seeds it with one child and one finished sleep.
+func createLegacyDB(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open legacy db: %v", err)
+	}
+	defer db.Close()
+
+	stmts := append([]string{}, legacySchema...)
+	stmts = append(stmts,
+		`INSERT INTO children VALUES ('c1','Lan','2024-01-01','female','','','2024-01-01T00:00:00+07:00','2024-01-01T00:00:00+07:00')`,
+		`INSERT INTO sleep_logs VALUES ('s1','c1','2024-01-15T08:00:00+07:00','2024-01-15T09:00:00+07:00',60,'','2024-01-15T08:00:00+07:00')`,
+	)
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("exec %q: %v", stmt, err)
+		}
+	}
+	return path
+}
+
+func TestMigrate_UpgradesLegacyDatabase(t *testing.T) {
+	path := createLegacyDB(t)
+
+	st, err := store.Connect(path)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer st.Close()
+
+	if v, err := st.SchemaVersion(); err != nil || v != 0 {
+		t.Fatalf("SchemaVersion before migrate = %d, %v; want 0", v, err)
+	}
+
+	n, err := st.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if n != store.LatestSchemaVersion() {
+		t.Errorf("applied %d migrations, want %d", n, store.LatestSchemaVersion())
+	}
+
+	statuses, err := st.MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus: %v", err)
+	}
+	for _, m := range statuses {
+		if m.AppliedAt == "" {
+			t.Errorf("migration %d (%s) still pending", m.Version, m.Name)
+		}
+	}
+
+	child, err := st.GetChild()
+	if err != nil {
+		t.Fatalf("GetChild after upgrade: %v", err)
+	}
+	logs, err := st.GetSleepLogs(child.ID, "")
+	if err != nil {
+		t.Fatalf("GetSleepLogs after upgrade: %v", err)
+	}
+	if len(logs) != 1 || logs[0].ID != "s1" {
+		t.Errorf("legacy sleep log not preserved: %+v", logs)
+	}
+}
+
+func TestMigrate_Idempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	st, err := store.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	st.Close()
+
+	st, err = store.Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer st.Close()
+
+	n, err := st.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if n != 0 {
+		t.Errorf("applied %d migrations on an up-to-date database, want 0", n)
+	}
+}
+
+func TestOpen_RefusesNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	st, err := store.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	st.Close()
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open raw db: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, 'from the future', '')`, store.LatestSchemaVersion()+1); err != nil {
+		t.Fatalf("insert future migration: %v", err)
+	}
+	db.Close()
+
+	if _, err := store.Open(path); !errors.Is(err, store.ErrSchemaTooNew) {
+		t.Fatalf("Open error = %v, want ErrSchemaTooNew", err)
+	}
+}
@@ -0,0 +1,228 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"baby-care/internal/catalog"
+	"baby-care/internal/model"
+	"github.com/google/uuid"
+)
+
+// ErrMilestoneRecorded is returned when a catalogued milestone is recorded a
+// second time for the same child.
+var ErrMilestoneRecorded = errors.New("milestone already recorded")
+
+const milestoneColumns = `id, child_id, COALESCE(code,''), title, category, achieved_on, photo_url, notes, created_at, COALESCE(created_by,''), deleted_at`
+
+// CreateMilestone records a milestone. With a catalogue code, an empty title
+// or category is taken from the catalogue.
+func (s *Store) CreateMilestone(childID, code, title, category, achievedOn, photoURL, notes string) (*model.Milestone, error) {
+	if code != "" {
+		if m, ok := catalog.MilestoneByID(code); ok {
+			if title == "" {
+				title = m.Name
+			}
+			if category == "" {
+				category = m.Category
+			}
+		}
+	}
+
+	now := s.nowLocal()
+	if achievedOn == "" {
+		achievedOn = s.todayLocal()
+	}
+	m := &model.Milestone{
+		ID:         uuid.NewString(),
+		ChildID:    childID,
+		Code:       code,
+		Title:      title,
+		Category:   category,
+		AchievedOn: achievedOn,
+		PhotoURL:   photoURL,
+		Notes:      notes,
+		CreatedAt:  now,
+		CreatedBy:  s.actor,
+	}
+	err := s.inTx(func(tx *Store) error {
+		if err := checkMilestoneUnrecorded(tx.db, childID, code, ""); err != nil {
+			return err
+		}
+		if _, err := tx.db.Exec(
+			`INSERT INTO milestones (id, child_id, code, title, category, achieved_on, photo_url, notes, created_at, created_by) VALUES (?,?,NULLIF(?,''),?,?,?,?,?,?,?)`,
+			m.ID, m.ChildID, m.Code, m.Title, m.Category, m.AchievedOn, m.PhotoURL, m.Notes, m.CreatedAt, tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert milestone: %w", err)
+		}
+		return tx.audit(AuditCreate, "milestone", m.ID, nil, m)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// checkMilestoneUnrecorded returns ErrMilestoneRecorded when the child has a
+// live milestone with the catalogue code other than selfID. Custom
+// milestones, without a code, may repeat. Call it in the transaction that
+// records the milestone.
+func checkMilestoneUnrecorded(db querier, childID, code, selfID string) error {
+	if code == "" {
+		return nil
+	}
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM milestones WHERE child_id=? AND code=? AND id != ? AND deleted_at IS NULL`, childID, code, selfID).Scan(&n)
+	if err != nil {
+		return fmt.Errorf("check milestone: %w", err)
+	}
+	if n > 0 {
+		return ErrMilestoneRecorded
+	}
+	return nil
+}
+
+// GetMilestones returns the child's milestones, oldest first.
+func (s *Store) GetMilestones(childID string) ([]*model.Milestone, error) {
+	rows, err := s.db.Query(
+		`SELECT `+milestoneColumns+` FROM milestones WHERE child_id=? AND deleted_at IS NULL ORDER BY achieved_on ASC, created_at ASC`,
+		childID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query milestones: %w", err)
+	}
+	defer rows.Close()
+	return scanMilestoneRows(rows)
+}
+
+// UpdateMilestone edits a milestone; an empty title, category or date keeps
+// the stored value. The catalogue code cannot change.
+func (s *Store) UpdateMilestone(id, title, category, achievedOn, photoURL, notes string) (*model.Milestone, error) {
+	var updated *model.Milestone
+	err := s.inTx(func(tx *Store) error {
+		existing, err := getMilestoneByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if existing.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if title == "" {
+			title = existing.Title
+		}
+		if category == "" {
+			category = existing.Category
+		}
+		if achievedOn == "" {
+			achievedOn = existing.AchievedOn
+		}
+		_, err = tx.db.Exec(
+			`UPDATE milestones SET title=?, category=?, achieved_on=?, photo_url=?, notes=? WHERE id=?`,
+			title, category, achievedOn, photoURL, notes, id,
+		)
+		if err != nil {
+			return fmt.Errorf("update milestone: %w", err)
+		}
+		updated, err = getMilestoneByID(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "milestone", id, existing, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteMilestone moves a milestone to the trash; see RestoreLog.
+func (s *Store) DeleteMilestone(id string) error {
+	return s.trashLog("milestone", id)
+}
+
+// ExpectedMilestone is a catalogued milestone the child is of age for but
+// has not been recorded reaching.
+type ExpectedMilestone struct {
+	*catalog.Milestone
+	TypicalFrom  string `json:"typical_from"` // e.g. "3 months"
+	TypicalTo    string `json:"typical_to"`
+	ExpectedFrom string `json:"expected_from"` // dates for this child
+	ExpectedBy   string `json:"expected_by"`
+	Late         bool   `json:"late"` // past the end of the typical window
+}
+
+// GetExpectedMilestones returns the catalogued milestones whose typical
+// window has begun by now and that the child has not reached yet, in
+// catalogue order.
+func (s *Store) GetExpectedMilestones(childID string, now time.Time) ([]*ExpectedMilestone, error) {
+	child, err := s.GetChildByID(childID)
+	if err != nil {
+		return nil, err
+	}
+	dob, err := time.Parse("2006-01-02", child.DateOfBirth)
+	if err != nil {
+		return nil, fmt.Errorf("child date of birth: %w", err)
+	}
+	recorded, err := s.GetMilestones(childID)
+	if err != nil {
+		return nil, err
+	}
+	reached := map[string]bool{}
+	for _, m := range recorded {
+		if m.Code != "" {
+			reached[m.Code] = true
+		}
+	}
+
+	today := now.In(s.Location()).Format("2006-01-02")
+	expected := []*ExpectedMilestone{}
+	for _, m := range catalog.Milestones() {
+		from := m.From.From(dob).Format("2006-01-02")
+		if reached[m.ID] || from > today {
+			continue
+		}
+		by := m.To.From(dob).Format("2006-01-02")
+		expected = append(expected, &ExpectedMilestone{
+			Milestone:    m,
+			TypicalFrom:  m.From.String(),
+			TypicalTo:    m.To.String(),
+			ExpectedFrom: from,
+			ExpectedBy:   by,
+			Late:         by < today,
+		})
+	}
+	return expected, nil
+}
+
+func getMilestoneByID(s *Store, id string) (*model.Milestone, error) {
+	row := s.db.QueryRow(
+		`SELECT `+milestoneColumns+` FROM milestones WHERE id=?`, id,
+	)
+	m, err := scanMilestone(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return m, err
+}
+
+func scanMilestone(row rowScanner) (*model.Milestone, error) {
+	var m model.Milestone
+	if err := row.Scan(&m.ID, &m.ChildID, &m.Code, &m.Title, &m.Category, &m.AchievedOn, &m.PhotoURL, &m.Notes, &m.CreatedAt, &m.CreatedBy, &m.DeletedAt); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func scanMilestoneRows(rows *sql.Rows) ([]*model.Milestone, error) {
+	var list []*model.Milestone
+	for rows.Next() {
+		m, err := scanMilestone(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,92 @@
+package store_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"baby-care/internal/store"
+)
+
+func TestCreateMilestone_FromCatalog(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	m, err := st.CreateMilestone(childID, "social-smile", "", "", "2024-02-10", "", "At grandma's")
+	if err != nil {
+		t.Fatalf("CreateMilestone: %v", err)
+	}
+	if m.Title != "First social smile" || m.Category != "social" {
+		t.Errorf("milestone = %+v, want the catalogue title and category", m)
+	}
+	if _, err := st.CreateMilestone(childID, "social-smile", "", "", "2024-02-11", "", ""); !errors.Is(err, store.ErrMilestoneRecorded) {
+		t.Errorf("second social-smile error = %v, want ErrMilestoneRecorded", err)
+	}
+
+	custom, err := st.CreateMilestone(childID, "", "First trip to the beach", "social", "2024-03-01", "", "")
+	if err != nil || custom.Code != "" {
+		t.Fatalf("custom milestone = %+v, %v", custom, err)
+	}
+}
+
+func TestCreateMilestone_RecordedOnce(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	// Two devices record the same milestone at once; only one gets it.
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = st.CreateMilestone(childID, "social-smile", "", "", "2024-04-01", "", "")
+		}()
+	}
+	wg.Wait()
+	if (errs[0] == nil) == (errs[1] == nil) || !errors.Is(errors.Join(errs...), store.ErrMilestoneRecorded) {
+		t.Errorf("errors = %v, want one milestone and one ErrMilestoneRecorded", errs)
+	}
+
+	// A trashed copy cannot come back next to the one recorded since.
+	milestones, _ := st.GetMilestones(childID)
+	if err := st.DeleteMilestone(milestones[0].ID); err != nil {
+		t.Fatalf("DeleteMilestone: %v", err)
+	}
+	if _, err := st.CreateMilestone(childID, "social-smile", "", "", "2024-04-02", "", ""); err != nil {
+		t.Fatalf("CreateMilestone again: %v", err)
+	}
+	if _, err := st.RestoreLog("milestone", milestones[0].ID); !errors.Is(err, store.ErrMilestoneRecorded) {
+		t.Errorf("RestoreLog error = %v, want ErrMilestoneRecorded", err)
+	}
+}
+
+func TestGetExpectedMilestones(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st) // born 2024-01-01
+	st.CreateMilestone(childID, "holds-head", "", "", "2024-03-05", "", "")
+
+	now, _ := time.Parse(time.RFC3339, "2024-04-15T10:00:00+07:00")
+	expected, err := st.GetExpectedMilestones(childID, now)
+	if err != nil {
+		t.Fatalf("GetExpectedMilestones: %v", err)
+	}
+	byID := map[string]*store.ExpectedMilestone{}
+	for _, e := range expected {
+		byID[e.ID] = e
+	}
+	if byID["holds-head"] != nil {
+		t.Error("holds-head was recorded but is still expected")
+	}
+	smile := byID["social-smile"]
+	if smile == nil || !smile.Late || smile.ExpectedBy != "2024-04-01" {
+		t.Errorf("social-smile = %+v, want late since 2024-04-01", smile)
+	}
+	if laughs := byID["laughs"]; laughs == nil || laughs.Late || laughs.TypicalFrom != "3 months" {
+		t.Errorf("laughs = %+v, want expected from 3 months and not late", laughs)
+	}
+	if byID["walks-alone"] != nil {
+		t.Error("walks-alone is not expected at three months")
+	}
+}
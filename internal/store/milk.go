@@ -0,0 +1,395 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"baby-care/internal/model"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrExceedsPumped is returned when bags from a pumping session would
+	// hold more than the session yielded.
+	ErrExceedsPumped = errors.New("bags exceed the volume pumped in that session")
+	// ErrRefreeze is returned when thawed milk is moved back to the freezer.
+	ErrRefreeze = errors.New("thawed milk cannot be refrozen")
+)
+
+// Storage locations for expressed milk.
+const (
+	MilkRoom    = "room"
+	MilkFridge  = "fridge"
+	MilkFreezer = "freezer"
+)
+
+// milkShelfLife is how long freshly expressed milk keeps in each location,
+// following CDC storage guidance.
+var milkShelfLife = map[string]time.Duration{
+	MilkRoom:    4 * time.Hour,
+	MilkFridge:  4 * 24 * time.Hour,
+	MilkFreezer: 180 * 24 * time.Hour,
+}
+
+// thawedShelfLife is how long milk keeps once taken out of the freezer,
+// counted from thawing.
+var thawedShelfLife = map[string]time.Duration{
+	MilkRoom:   2 * time.Hour,
+	MilkFridge: 24 * time.Hour,
+}
+
+const milkBagColumns = `id, child_id, pumping_id, volume_ml, remaining_ml, location, stored_at, thawed_at, expires_at, notes, created_at, COALESCE(created_by,'')`
+
+// MilkStash is the child's milk inventory. Bags lists usable bags in the order
+// they will be consumed; ExpiringSoon is the subset expiring within the
+// requested window.
+type MilkStash struct {
+	TotalML      int              `json:"total_ml"`
+	RoomML       int              `json:"room_ml"`
+	FridgeML     int              `json:"fridge_ml"`
+	FreezerML    int              `json:"freezer_ml"`
+	ExpiredML    int              `json:"expired_ml"`
+	Bags         []*model.MilkBag `json:"bags"`
+	ExpiringSoon []*model.MilkBag `json:"expiring_soon"`
+	Expired      []*model.MilkBag `json:"expired"`
+}
+
+// milkExpiry returns when milk stored in location at storedAt, or thawed at
+// thawedAt when set, must be used by.
+func (s *Store) milkExpiry(location, storedAt string, thawedAt *string) string {
+	from, life := storedAt, milkShelfLife[location]
+	if thawedAt != nil {
+		from, life = *thawedAt, thawedShelfLife[location]
+	}
+	t, _ := time.Parse(time.RFC3339, from)
+	return t.Add(life).In(s.Location()).Format(time.RFC3339)
+}
+
+// CreateMilkBag stores a bag of milk. With pumpingID the bag is filled from
+// that session: volumeML defaults to what is left unbagged of it and
+// storedAt to when it ended.
+func (s *Store) CreateMilkBag(childID, pumpingID, location, storedAt, notes string, volumeML *int) (*model.MilkBag, error) {
+	var bag *model.MilkBag
+	err := s.inTx(func(tx *Store) error {
+		var volume int
+		if volumeML != nil {
+			volume = *volumeML
+		}
+		if pumpingID != "" {
+			var pumped int
+			var endTime sql.NullString
+			err := tx.db.QueryRow(
+				`SELECT COALESCE(left_ml,0) + COALESCE(right_ml,0), end_time FROM pumping_logs WHERE id=? AND child_id=? AND deleted_at IS NULL`,
+				pumpingID, childID,
+			).Scan(&pumped, &endTime)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			if err != nil {
+				return fmt.Errorf("lookup pumping: %w", err)
+			}
+			bagged, err := baggedML(tx.db, pumpingID)
+			if err != nil {
+				return err
+			}
+			if volumeML == nil {
+				volume = pumped - bagged
+			}
+			if volume <= 0 || bagged+volume > pumped {
+				return ErrExceedsPumped
+			}
+			if storedAt == "" && endTime.Valid {
+				storedAt = endTime.String
+			}
+		}
+
+		now := tx.nowLocal()
+		if storedAt == "" {
+			storedAt = now
+		}
+		bag = &model.MilkBag{
+			ID:          uuid.NewString(),
+			ChildID:     childID,
+			VolumeML:    volume,
+			RemainingML: volume,
+			Location:    location,
+			StoredAt:    storedAt,
+			ExpiresAt:   tx.milkExpiry(location, storedAt, nil),
+			Notes:       notes,
+			CreatedAt:   now,
+			CreatedBy:   tx.actor,
+		}
+		if pumpingID != "" {
+			bag.PumpingID = &pumpingID
+		}
+		if _, err := tx.db.Exec(
+			`INSERT INTO milk_bags (id, child_id, pumping_id, volume_ml, remaining_ml, location, stored_at, expires_at, notes, created_at, created_by) VALUES (?,?,?,?,?,?,?,?,?,?,?)`,
+			bag.ID, bag.ChildID, bag.PumpingID, bag.VolumeML, bag.RemainingML, bag.Location, bag.StoredAt, bag.ExpiresAt, bag.Notes, bag.CreatedAt, tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert milk bag: %w", err)
+		}
+		return tx.audit(AuditCreate, "milk_bag", bag.ID, nil, bag)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bag, nil
+}
+
+// baggedML is how much milk has been bagged from a pumping session.
+func baggedML(db querier, pumpingID string) (int, error) {
+	var bagged int
+	if err := db.QueryRow(`SELECT COALESCE(SUM(volume_ml),0) FROM milk_bags WHERE pumping_id=?`, pumpingID).Scan(&bagged); err != nil {
+		return 0, fmt.Errorf("sum bagged milk: %w", err)
+	}
+	return bagged, nil
+}
+
+func (s *Store) GetMilkBag(id string) (*model.MilkBag, error) {
+	return getMilkBag(s.db, id)
+}
+
+// UpdateMilkBag moves a bag and edits its notes; an empty location keeps it
+// where it is. Taking a bag out of the freezer thaws it, which restarts its
+// shelf life under the shorter thawed rules.
+func (s *Store) UpdateMilkBag(id, location, notes string) (*model.MilkBag, error) {
+	var updated *model.MilkBag
+	err := s.inTx(func(tx *Store) error {
+		existing, err := getMilkBag(tx.db, id)
+		if err != nil {
+			return err
+		}
+		if location == "" {
+			location = existing.Location
+		}
+		thawedAt := existing.ThawedAt
+		switch {
+		case location == MilkFreezer && thawedAt != nil:
+			return ErrRefreeze
+		case existing.Location == MilkFreezer && location != MilkFreezer:
+			now := tx.nowLocal()
+			thawedAt = &now
+		}
+		expiresAt := tx.milkExpiry(location, existing.StoredAt, thawedAt)
+
+		_, err = tx.db.Exec(
+			`UPDATE milk_bags SET location=?, thawed_at=?, expires_at=?, notes=? WHERE id=?`,
+			location, thawedAt, expiresAt, notes, id,
+		)
+		if err != nil {
+			return fmt.Errorf("update milk bag: %w", err)
+		}
+		updated, err = getMilkBag(tx.db, id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "milk_bag", id, existing, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteMilkBag discards a bag.
+func (s *Store) DeleteMilkBag(id string) error {
+	return s.inTx(func(tx *Store) error {
+		existing, err := getMilkBag(tx.db, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.db.Exec(`DELETE FROM milk_bags WHERE id=?`, id); err != nil {
+			return fmt.Errorf("delete milk bag: %w", err)
+		}
+		return tx.audit(AuditDelete, "milk_bag", id, existing, nil)
+	})
+}
+
+// breastMilkML is how much a feed draws from the milk stash: the quantity
+// of a breast milk bottle, and nothing for any other feed.
+func breastMilkML(feedType, milkType string, quantityML *int) int {
+	if feedType != "bottle" || milkType != "breast_milk" || quantityML == nil {
+		return 0
+	}
+	return *quantityML
+}
+
+// drawMilk draws ml for a feed from the child's unexpired bags, oldest first,
+// and records what was taken from each. Taking less than ml when the stash
+// runs out is not an error. Call it in the feed's transaction.
+func (s *Store) drawMilk(feedingID, childID string, ml int) error {
+	rows, err := s.db.Query(
+		`SELECT `+milkBagColumns+` FROM milk_bags
+		 WHERE child_id=? AND remaining_ml > 0 AND unixepoch(expires_at) > unixepoch('now')
+		 ORDER BY unixepoch(stored_at), created_at`,
+		childID,
+	)
+	if err != nil {
+		return fmt.Errorf("query milk bags: %w", err)
+	}
+	bags, err := scanMilkBagRows(rows)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	for _, bag := range bags {
+		if ml <= 0 {
+			break
+		}
+		take := min(ml, bag.RemainingML)
+		if _, err := s.db.Exec(`UPDATE milk_bags SET remaining_ml=remaining_ml-? WHERE id=?`, take, bag.ID); err != nil {
+			return fmt.Errorf("consume milk bag: %w", err)
+		}
+		if _, err := s.db.Exec(`INSERT INTO feeding_milk_uses (feeding_id, bag_id, ml) VALUES (?,?,?)`, feedingID, bag.ID, take); err != nil {
+			return fmt.Errorf("insert milk use: %w", err)
+		}
+		after, err := getMilkBag(s.db, bag.ID)
+		if err != nil {
+			return err
+		}
+		if err := s.audit(AuditUpdate, "milk_bag", bag.ID, bag, after); err != nil {
+			return err
+		}
+		ml -= take
+	}
+	return nil
+}
+
+// returnMilk puts the milk a feed drew back into its bags. Bags discarded
+// since are gone with their share. Call it in the feed's transaction.
+func (s *Store) returnMilk(feedingID string) error {
+	uses, err := s.loadMilkUses(feedingID)
+	if err != nil {
+		return err
+	}
+	for _, use := range uses[feedingID] {
+		before, err := getMilkBag(s.db, use.BagID)
+		if err != nil {
+			return err
+		}
+		if _, err := s.db.Exec(`UPDATE milk_bags SET remaining_ml=remaining_ml+? WHERE id=?`, use.ML, use.BagID); err != nil {
+			return fmt.Errorf("return milk to bag: %w", err)
+		}
+		after, err := getMilkBag(s.db, use.BagID)
+		if err != nil {
+			return err
+		}
+		if err := s.audit(AuditUpdate, "milk_bag", use.BagID, before, after); err != nil {
+			return err
+		}
+	}
+	if _, err := s.db.Exec(`DELETE FROM feeding_milk_uses WHERE feeding_id=?`, feedingID); err != nil {
+		return fmt.Errorf("delete milk uses: %w", err)
+	}
+	return nil
+}
+
+// redrawMilk puts back what a feed drew and draws ml afresh, as when its
+// quantity or milk type changed or it came back from the trash.
+func (s *Store) redrawMilk(feedingID, childID string, ml int) error {
+	if err := s.returnMilk(feedingID); err != nil {
+		return err
+	}
+	return s.drawMilk(feedingID, childID, ml)
+}
+
+// loadMilkUses returns the milk drawn by each of the given feeds, keyed by
+// feed ID, in the order it was drawn.
+func (s *Store) loadMilkUses(feedingIDs ...string) (map[string][]model.MilkUse, error) {
+	uses := map[string][]model.MilkUse{}
+	if len(feedingIDs) == 0 {
+		return uses, nil
+	}
+	args := make([]any, len(feedingIDs))
+	for i, id := range feedingIDs {
+		args[i] = id
+	}
+	rows, err := s.db.Query(
+		`SELECT feeding_id, bag_id, ml FROM feeding_milk_uses WHERE feeding_id IN (?`+strings.Repeat(",?", len(args)-1)+`) ORDER BY feeding_id, rowid`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query milk uses: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var use model.MilkUse
+		if err := rows.Scan(&id, &use.BagID, &use.ML); err != nil {
+			return nil, err
+		}
+		uses[id] = append(uses[id], use)
+	}
+	return uses, rows.Err()
+}
+
+// GetMilkStash reports the child's bags that still hold milk, flagging those
+// that expire within soon.
+func (s *Store) GetMilkStash(childID string, soon time.Duration) (*MilkStash, error) {
+	rows, err := s.db.Query(
+		`SELECT `+milkBagColumns+` FROM milk_bags WHERE child_id=? AND remaining_ml > 0 ORDER BY unixepoch(stored_at), created_at`,
+		childID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query milk stash: %w", err)
+	}
+	defer rows.Close()
+	bags, err := scanMilkBagRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	stash := &MilkStash{Bags: []*model.MilkBag{}, ExpiringSoon: []*model.MilkBag{}, Expired: []*model.MilkBag{}}
+	now := time.Now()
+	for _, bag := range bags {
+		expires, _ := time.Parse(time.RFC3339, bag.ExpiresAt)
+		if !expires.After(now) {
+			stash.Expired = append(stash.Expired, bag)
+			stash.ExpiredML += bag.RemainingML
+			continue
+		}
+		stash.Bags = append(stash.Bags, bag)
+		stash.TotalML += bag.RemainingML
+		switch bag.Location {
+		case MilkRoom:
+			stash.RoomML += bag.RemainingML
+		case MilkFridge:
+			stash.FridgeML += bag.RemainingML
+		case MilkFreezer:
+			stash.FreezerML += bag.RemainingML
+		}
+		if expires.Sub(now) <= soon {
+			stash.ExpiringSoon = append(stash.ExpiringSoon, bag)
+		}
+	}
+	return stash, nil
+}
+
+func getMilkBag(db querier, id string) (*model.MilkBag, error) {
+	var b model.MilkBag
+	err := db.QueryRow(`SELECT `+milkBagColumns+` FROM milk_bags WHERE id=?`, id).
+		Scan(&b.ID, &b.ChildID, &b.PumpingID, &b.VolumeML, &b.RemainingML, &b.Location, &b.StoredAt, &b.ThawedAt, &b.ExpiresAt, &b.Notes, &b.CreatedAt, &b.CreatedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan milk bag: %w", err)
+	}
+	return &b, nil
+}
+
+func scanMilkBagRows(rows *sql.Rows) ([]*model.MilkBag, error) {
+	var bags []*model.MilkBag
+	for rows.Next() {
+		var b model.MilkBag
+		if err := rows.Scan(&b.ID, &b.ChildID, &b.PumpingID, &b.VolumeML, &b.RemainingML, &b.Location, &b.StoredAt, &b.ThawedAt, &b.ExpiresAt, &b.Notes, &b.CreatedAt, &b.CreatedBy); err != nil {
+			return nil, err
+		}
+		bags = append(bags, &b)
+	}
+	return bags, rows.Err()
+}
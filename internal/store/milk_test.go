@@ -0,0 +1,180 @@
+package store_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"baby-care/internal/model"
+	"baby-care/internal/store"
+)
+
+// hoursAgo returns an RFC3339 timestamp h hours before now.
+func hoursAgo(h int) string {
+	return time.Now().Add(-time.Duration(h) * time.Hour).Format(time.RFC3339)
+}
+
+func TestMilkBag_FromPumping(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	p, _ := st.CreatePumping(childID, hoursAgo(2), hoursAgo(1), "", intPtr(90), intPtr(70))
+
+	first, err := st.CreateMilkBag(childID, p.ID, store.MilkFridge, "", "", intPtr(100))
+	if err != nil {
+		t.Fatalf("CreateMilkBag: %v", err)
+	}
+	if first.StoredAt != *p.EndTime {
+		t.Errorf("StoredAt = %s, want the session end %s", first.StoredAt, *p.EndTime)
+	}
+	stored, _ := time.Parse(time.RFC3339, first.StoredAt)
+	expires, _ := time.Parse(time.RFC3339, first.ExpiresAt)
+	if got := expires.Sub(stored); got != 4*24*time.Hour {
+		t.Errorf("fridge shelf life = %v, want 4 days", got)
+	}
+
+	// The rest of the session goes into a second bag by default.
+	rest, err := st.CreateMilkBag(childID, p.ID, store.MilkFreezer, "", "", nil)
+	if err != nil {
+		t.Fatalf("CreateMilkBag rest: %v", err)
+	}
+	if rest.VolumeML != 60 {
+		t.Errorf("VolumeML = %d, want the 60 ml left", rest.VolumeML)
+	}
+	if _, err := st.CreateMilkBag(childID, p.ID, store.MilkFridge, "", "", intPtr(10)); !errors.Is(err, store.ErrExceedsPumped) {
+		t.Errorf("overdrawn session err = %v, want ErrExceedsPumped", err)
+	}
+
+	// Nor can the session shrink below what was bagged from it.
+	if _, err := st.UpdatePumping(p.ID, "", "", "", intPtr(50), nil); !errors.Is(err, store.ErrExceedsPumped) {
+		t.Errorf("shrinking the session err = %v, want ErrExceedsPumped", err)
+	}
+	if _, err := st.UpdatePumping(p.ID, "", "", "", intPtr(100), intPtr(60)); err != nil {
+		t.Errorf("moving volume between sides: %v", err)
+	}
+}
+
+func TestMilkBag_ConcurrentRestOfSession(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	p, _ := st.CreatePumping(childID, hoursAgo(2), hoursAgo(1), "", intPtr(90), intPtr(70))
+
+	// Two devices bag the rest of the session at once; only one gets it.
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = st.CreateMilkBag(childID, p.ID, store.MilkFridge, "", "", nil)
+		}()
+	}
+	wg.Wait()
+	if (errs[0] == nil) == (errs[1] == nil) || !errors.Is(errors.Join(errs...), store.ErrExceedsPumped) {
+		t.Errorf("errors = %v, want one bag and one ErrExceedsPumped", errs)
+	}
+}
+
+func TestBottle_DrawsMilkFIFO(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	// Room-temperature milk from a day ago has expired and is skipped.
+	expired, _ := st.CreateMilkBag(childID, "", store.MilkRoom, hoursAgo(24), "", intPtr(50))
+	older, _ := st.CreateMilkBag(childID, "", store.MilkFridge, hoursAgo(10), "", intPtr(60))
+	newer, _ := st.CreateMilkBag(childID, "", store.MilkFridge, hoursAgo(2), "", intPtr(100))
+
+	feed, _, err := st.CreateFeedingSpan(childID, "bottle", "breast_milk", "", "", "", intPtr(80))
+	if err != nil {
+		t.Fatalf("CreateFeedingSpan: %v", err)
+	}
+	uses := feed.MilkUsed
+	if len(uses) != 2 || uses[0].BagID != older.ID || uses[0].ML != 60 || uses[1].BagID != newer.ID || uses[1].ML != 20 {
+		t.Errorf("uses = %+v, want 60 ml from the older bag then 20 from the newer", uses)
+	}
+
+	stash, err := st.GetMilkStash(childID, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("GetMilkStash: %v", err)
+	}
+	if stash.TotalML != 80 || stash.FridgeML != 80 || len(stash.Bags) != 1 {
+		t.Errorf("stash = %d ml in %d bags, want 80 in 1", stash.TotalML, len(stash.Bags))
+	}
+	if stash.ExpiredML != 50 || len(stash.Expired) != 1 || stash.Expired[0].ID != expired.ID {
+		t.Errorf("expired = %d ml, %+v; want the room bag", stash.ExpiredML, stash.Expired)
+	}
+}
+
+func TestBottle_MilkFollowsFeed(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	bag, _ := st.CreateMilkBag(childID, "", store.MilkFridge, hoursAgo(2), "", intPtr(100))
+	remaining := func() int {
+		t.Helper()
+		b, err := st.GetMilkBag(bag.ID)
+		if err != nil {
+			t.Fatalf("GetMilkBag: %v", err)
+		}
+		return b.RemainingML
+	}
+
+	feed, _, err := st.CreateFeedingSpan(childID, "bottle", "breast_milk", "", "", "", intPtr(60))
+	if err != nil {
+		t.Fatalf("CreateFeedingSpan: %v", err)
+	}
+	if got := remaining(); got != 40 {
+		t.Errorf("after feed: %d ml left, want 40", got)
+	}
+
+	feed, err = st.UpdateFeeding(feed.ID, "", "", "", "", "", intPtr(90))
+	if err != nil {
+		t.Fatalf("UpdateFeeding: %v", err)
+	}
+	if got := remaining(); got != 10 || len(feed.MilkUsed) != 1 || feed.MilkUsed[0].ML != 90 {
+		t.Errorf("after raising to 90 ml: %d ml left, uses %+v; want 10 left, 90 used", got, feed.MilkUsed)
+	}
+	if _, err := st.UpdateFeeding(feed.ID, "", "formula", "", "", "", intPtr(90)); err != nil {
+		t.Fatalf("UpdateFeeding to formula: %v", err)
+	}
+	if got := remaining(); got != 100 {
+		t.Errorf("after switching to formula: %d ml left, want 100", got)
+	}
+	if _, err := st.UpdateFeeding(feed.ID, "", "breast_milk", "", "", "", intPtr(90)); err != nil {
+		t.Fatalf("UpdateFeeding to breast milk: %v", err)
+	}
+
+	if err := st.DeleteFeeding(feed.ID); err != nil {
+		t.Fatalf("DeleteFeeding: %v", err)
+	}
+	if got := remaining(); got != 100 {
+		t.Errorf("after delete: %d ml left, want 100", got)
+	}
+	restored, err := st.RestoreLog("feeding", feed.ID)
+	if err != nil {
+		t.Fatalf("RestoreLog: %v", err)
+	}
+	if got := remaining(); got != 10 || len(restored.(*model.FeedingLog).MilkUsed) != 1 {
+		t.Errorf("after restore: %d ml left, %+v; want 10 left and the bag used", got, restored)
+	}
+}
+
+func TestMilkBag_Thaw(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	bag, _ := st.CreateMilkBag(childID, "", store.MilkFreezer, hoursAgo(48), "", intPtr(120))
+
+	bag, err := st.UpdateMilkBag(bag.ID, store.MilkFridge, "thawing")
+	if err != nil {
+		t.Fatalf("UpdateMilkBag: %v", err)
+	}
+	if bag.ThawedAt == nil {
+		t.Fatal("expected ThawedAt once out of the freezer")
+	}
+	expires, _ := time.Parse(time.RFC3339, bag.ExpiresAt)
+	if left := time.Until(expires); left > 24*time.Hour || left < 23*time.Hour {
+		t.Errorf("thawed milk expires in %v, want about 24h", left)
+	}
+	if _, err := st.UpdateMilkBag(bag.ID, store.MilkFreezer, ""); !errors.Is(err, store.ErrRefreeze) {
+		t.Errorf("refreeze err = %v, want ErrRefreeze", err)
+	}
+}
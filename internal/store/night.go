@@ -0,0 +1,154 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"baby-care/internal/model"
+)
+
+// The night window used until the household sets one.
+const (
+	DefaultNightStart = "19:00"
+	DefaultNightEnd   = "07:00"
+)
+
+// ErrInvalidNightWindow is returned for night bounds that are not HH:MM
+// times or that start and end at the same time.
+var ErrInvalidNightWindow = errors.New("invalid night window")
+
+// nightWindow is the part of the day in which sleep counts as night sleep,
+// in minutes after local midnight. It wraps past midnight when start is
+// after end.
+type nightWindow struct {
+	start, end int
+}
+
+// parseClock parses an HH:MM time of day into minutes after midnight.
+func parseClock(v string) (int, error) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is not HH:MM", ErrInvalidNightWindow, v)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func formatClock(m int) string {
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+func (w nightWindow) startClock() string { return formatClock(w.start) }
+func (w nightWindow) endClock() string   { return formatClock(w.end) }
+
+// nightWindow returns the household night window.
+func (s *Store) nightWindow() (nightWindow, error) {
+	var w nightWindow
+	for _, b := range []struct {
+		key, def string
+		into     *int
+	}{
+		{settingNightStart, DefaultNightStart, &w.start},
+		{settingNightEnd, DefaultNightEnd, &w.end},
+	} {
+		v, err := s.getSetting(b.key)
+		if errors.Is(err, ErrNotFound) {
+			v = b.def
+		} else if err != nil {
+			return w, err
+		}
+		if *b.into, err = parseClock(v); err != nil {
+			return w, err
+		}
+	}
+	return w, nil
+}
+
+// SetNightWindow sets the start and end of the night as HH:MM local times.
+// An empty bound keeps its current value.
+func (s *Store) SetNightWindow(start, end string) error {
+	w, err := s.nightWindow()
+	if err != nil {
+		return err
+	}
+	if start != "" {
+		if w.start, err = parseClock(start); err != nil {
+			return err
+		}
+	}
+	if end != "" {
+		if w.end, err = parseClock(end); err != nil {
+			return err
+		}
+	}
+	if w.start == w.end {
+		return fmt.Errorf("%w: the night must not start and end at the same time", ErrInvalidNightWindow)
+	}
+	return s.inTx(func(tx *Store) error {
+		if start != "" {
+			if err := tx.setSetting(settingNightStart, w.startClock()); err != nil {
+				return err
+			}
+		}
+		if end != "" {
+			return tx.setSetting(settingNightEnd, w.endClock())
+		}
+		return nil
+	})
+}
+
+// sleepDay returns the date a sleep starting at t (local time) counts
+// towards and whether it is night sleep. Night sleep belongs to the evening
+// the night began, so a sleep at 02:00 counts towards the day before; other
+// sleep belongs to the date it starts.
+func (w nightWindow) sleepDay(t time.Time) (string, bool) {
+	clock := t.Hour()*60 + t.Minute()
+	date := t.Format("2006-01-02")
+	if w.start < w.end {
+		return date, clock >= w.start && clock < w.end
+	}
+	switch {
+	case clock >= w.start:
+		return date, true
+	case clock < w.end:
+		return t.AddDate(0, 0, -1).Format("2006-01-02"), true
+	}
+	return date, false
+}
+
+// NightStats describes one night's sleep: the sleeps starting within the
+// night window, from the evening the night began.
+type NightStats struct {
+	Bedtime               string `json:"bedtime"` // start of the first night sleep
+	WakeUp                string `json:"wake_up"` // end of the last one
+	LongestStretchMinutes int    `json:"longest_stretch_minutes"`
+	Wakings               int    `json:"wakings"` // between night sleeps, and pauses within them
+}
+
+// addNightSleep adds a finished night sleep from start to end, interrupted by
+// pauses, to the night. Sleeps must be added in start order.
+func (n *NightStats) addNightSleep(start, end time.Time, pauses []model.TimerPause) {
+	if n.Bedtime == "" {
+		n.Bedtime = start.Format(time.RFC3339)
+	} else {
+		n.Wakings++
+	}
+	if wake, err := time.Parse(time.RFC3339, n.WakeUp); err != nil || end.After(wake) {
+		n.WakeUp = end.Format(time.RFC3339)
+	}
+	from := start
+	for _, p := range pauses {
+		paused, err := time.Parse(time.RFC3339, p.PausedAt)
+		if err != nil || p.ResumedAt == nil {
+			continue
+		}
+		resumed, err := time.Parse(time.RFC3339, *p.ResumedAt)
+		if err != nil {
+			continue
+		}
+		n.Wakings++
+		n.LongestStretchMinutes = max(n.LongestStretchMinutes, int(paused.Sub(from).Minutes()))
+		from = resumed
+	}
+	n.LongestStretchMinutes = max(n.LongestStretchMinutes, int(end.Sub(from).Minutes()))
+}
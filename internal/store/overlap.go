@@ -0,0 +1,275 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"baby-care/internal/model"
+)
+
+// Ways to resolve a timed entry overlapping others of the same kind; see
+// WithResolve.
+const (
+	// ResolveTruncate ends the earlier of each overlapping pair where the
+	// later one begins.
+	ResolveTruncate = "truncate"
+	// ResolveMerge combines the overlapping entries into one spanning them
+	// all. The others are moved to the trash.
+	ResolveMerge = "merge"
+)
+
+// ConflictError is returned when a sleep, breast feed or pumping session would
+// overlap other entries of the same kind for the same child.
+type ConflictError struct {
+	Kind string
+	IDs  []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("overlaps %d other %s log(s)", len(e.IDs), e.Kind)
+}
+
+// WithResolve returns a view of the store that resolves overlaps with mode
+// instead of failing with *ConflictError. An empty mode fails.
+func (s *Store) WithResolve(mode string) *Store {
+	c := *s
+	c.resolve = mode
+	return &c
+}
+
+// span is the interval of a timed entry being created or updated. end is
+// empty while the entry is ongoing, which overlaps everything after start.
+type span struct {
+	start, end string
+	notes      string
+	// merged holds the segments of the breast feeds a merge absorbed into
+	// the entry; see mergeSegments.
+	merged []model.FeedingSegment
+}
+
+func (sp span) times() (start, end time.Time, ok bool) {
+	start, err := time.Parse(time.RFC3339, sp.start)
+	if err != nil {
+		return start, end, false
+	}
+	if sp.end == "" {
+		return start, end, true
+	}
+	end, err = time.Parse(time.RFC3339, sp.end)
+	return start, end, err == nil
+}
+
+// check returns ErrEndBeforeStart for an inverted interval.
+func (sp span) check() error {
+	if start, end, ok := sp.times(); ok && sp.end != "" && end.Before(start) {
+		return ErrEndBeforeStart
+	}
+	return nil
+}
+
+func (sp span) durationMinutes() *int {
+	start, end, ok := sp.times()
+	if !ok || sp.end == "" {
+		return nil
+	}
+	d := int(end.Sub(start).Minutes())
+	return &d
+}
+
+// endValue returns end for an SQL parameter, NULL while ongoing.
+func (sp span) endValue() any {
+	if sp.end == "" {
+		return nil
+	}
+	return sp.end
+}
+
+// timedSpan returns the span of a sleep, breast feed or pumping session and
+// the child it belongs to; ok is false for entries that never overlap.
+func timedSpan(entry any) (sp span, childID string, ok bool) {
+	var end *string
+	switch l := entry.(type) {
+	case *model.SleepLog:
+		sp, childID, end = span{start: l.StartTime}, l.ChildID, l.EndTime
+	case *model.FeedingLog:
+		if l.FeedType == "bottle" {
+			return sp, "", false
+		}
+		sp, childID, end = span{start: l.StartTime}, l.ChildID, l.EndTime
+	case *model.PumpingLog:
+		sp, childID, end = span{start: l.StartTime}, l.ChildID, l.EndTime
+	default:
+		return sp, "", false
+	}
+	if end != nil {
+		sp.end = *end
+	}
+	return sp, childID, true
+}
+
+// overlapping returns the live entries of kind for the child, other than
+// selfID, whose intervals intersect sp. Bottle feeds have no duration and
+// never overlap.
+func (s *Store) overlapping(kind, childID, selfID string, sp span) ([]span, []string, error) {
+	start, end, ok := sp.times()
+	if !ok {
+		return nil, nil, nil
+	}
+	query := `SELECT id, start_time, COALESCE(end_time,''), COALESCE(notes,'') FROM ` + logTables[kind] + `
+		WHERE child_id=? AND id != ? AND deleted_at IS NULL
+		  AND (end_time IS NULL OR unixepoch(end_time) > ?)`
+	args := []any{childID, selfID, start.Unix()}
+	if sp.end != "" {
+		query += ` AND unixepoch(start_time) < ?`
+		args = append(args, end.Unix())
+	}
+	if kind == "feeding" {
+		query += ` AND feed_type != 'bottle'`
+	}
+	query += ` ORDER BY unixepoch(start_time)`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query %s overlaps: %w", kind, err)
+	}
+	defer rows.Close()
+	var spans []span
+	var ids []string
+	for rows.Next() {
+		var id string
+		var o span
+		if err := rows.Scan(&id, &o.start, &o.end, &o.notes); err != nil {
+			return nil, nil, err
+		}
+		spans = append(spans, o)
+		ids = append(ids, id)
+	}
+	return spans, ids, rows.Err()
+}
+
+// resolveOverlaps checks sp against the child's other entries of kind and
+// applies the store's resolve mode, adjusting sp and the other entries. Call
+// it in the transaction that writes sp, so that no entry can slip in between
+// and the other entries are left alone when the write fails.
+func (s *Store) resolveOverlaps(kind, childID, selfID string, sp *span) error {
+	others, ids, err := s.overlapping(kind, childID, selfID, *sp)
+	if err != nil || len(others) == 0 {
+		return err
+	}
+	switch s.resolve {
+	case ResolveTruncate:
+		return s.truncateOverlaps(kind, sp, others, ids)
+	case ResolveMerge:
+		return s.mergeOverlaps(kind, sp, others, ids)
+	}
+	return &ConflictError{Kind: kind, IDs: ids}
+}
+
+func (s *Store) truncateOverlaps(kind string, sp *span, others []span, ids []string) error {
+	start, _, _ := sp.times()
+	// Entries starting at the same instant cannot be truncated; check before
+	// changing anything.
+	for _, o := range others {
+		if oStart, _, _ := o.times(); oStart.Equal(start) {
+			return &ConflictError{Kind: kind, IDs: ids}
+		}
+	}
+	for i, o := range others {
+		oStart, _, _ := o.times()
+		if oStart.Before(start) {
+			if err := s.setLogEnd(kind, ids[i], sp.start); err != nil {
+				return err
+			}
+			continue
+		}
+		// Others are ordered by start, so the first later one sets the end.
+		if _, end, _ := sp.times(); sp.end == "" || oStart.Before(end) {
+			sp.end = o.start
+		}
+	}
+	return nil
+}
+
+func (s *Store) mergeOverlaps(kind string, sp *span, others []span, ids []string) error {
+	notes := []string{}
+	if sp.notes != "" {
+		notes = append(notes, sp.notes)
+	}
+	for _, o := range others {
+		start, end, _ := sp.times()
+		oStart, oEnd, _ := o.times()
+		if oStart.Before(start) {
+			sp.start = o.start
+		}
+		if sp.end != "" && (o.end == "" || oEnd.After(end)) {
+			sp.end = o.end
+		}
+		if o.notes != "" && !slices.Contains(notes, o.notes) {
+			notes = append(notes, o.notes)
+		}
+	}
+	sp.notes = strings.Join(notes, "; ")
+	for _, id := range ids {
+		if kind == "feeding" {
+			feed, err := getFeedingByID(s, id)
+			if err != nil {
+				return err
+			}
+			sp.merged = append(sp.merged, feed.Segments...)
+		}
+		if err := s.trashLog(kind, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setLogEnd ends a timed entry at end, closing a pause still open, and
+// recomputes its duration less the time paused. A breast feed's segments are
+// cut at end too.
+func (s *Store) setLogEnd(kind, id, end string) error {
+	return s.inTx(func(tx *Store) error {
+		before, err := tx.getLog(kind, id)
+		if err != nil {
+			return err
+		}
+		var start string
+		if err := tx.db.QueryRow(`SELECT start_time FROM `+logTables[kind]+` WHERE id=?`, id).Scan(&start); err != nil {
+			return fmt.Errorf("lookup %s start: %w", kind, err)
+		}
+		sp := span{start: start, end: end}
+		if err := closePauses(tx.db, kind, id, sp.end); err != nil {
+			return err
+		}
+		pauses, err := tx.loadPauses(kind, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.db.Exec(
+			`UPDATE `+logTables[kind]+` SET end_time=?, duration_minutes=? WHERE id=?`,
+			sp.end, activeMinutes(sp, pauses[id]), id,
+		); err != nil {
+			return fmt.Errorf("truncate %s: %w", kind, err)
+		}
+		// A breast feed loses the sides it switched to after end, and its
+		// last remaining side ends there.
+		if feed, ok := before.(*model.FeedingLog); ok && len(feed.Segments) > 0 {
+			segs := slices.DeleteFunc(slices.Clone(feed.Segments), func(seg model.FeedingSegment) bool {
+				return compareTimestamps(seg.StartTime, end) >= 0
+			})
+			if segs, err = shapeSegments(segs, feed.FeedType, sp); err != nil {
+				return err
+			}
+			if err := saveSegments(tx.db, id, segs); err != nil {
+				return err
+			}
+		}
+		after, err := tx.getLog(kind, id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, kind, id, before, after)
+	})
+}
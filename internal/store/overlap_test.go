@@ -0,0 +1,192 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+
+	"baby-care/internal/model"
+	"baby-care/internal/store"
+)
+
+// mustCreateSleep creates a finished sleep from start to end.
+func mustCreateSleep(t *testing.T, st *store.Store, childID, start, end, notes string) *model.SleepLog {
+	t.Helper()
+	sl, _, err := st.CreateSleepSpan(childID, start, end, notes)
+	if err != nil {
+		t.Fatalf("CreateSleepSpan: %v", err)
+	}
+	return sl
+}
+
+func TestOverlap_Conflict(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	nap := mustCreateSleep(t, st, childID, "2024-01-15T13:00:00+07:00", "2024-01-15T14:00:00+07:00", "")
+
+	_, _, err := st.CreateSleep(childID, "2024-01-15T13:30:00+07:00", "")
+	var conflict *store.ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("CreateSleep err = %v, want ConflictError", err)
+	}
+	if len(conflict.IDs) != 1 || conflict.IDs[0] != nap.ID {
+		t.Errorf("conflict IDs = %v, want [%s]", conflict.IDs, nap.ID)
+	}
+
+	// Entries that only touch do not overlap.
+	if _, _, err := st.CreateSleepSpan(childID, "2024-01-15T14:00:00+07:00", "2024-01-15T14:30:00+07:00", ""); err != nil {
+		t.Errorf("touching sleep: %v", err)
+	}
+
+	// Moving an entry onto another is caught on update too.
+	other := mustCreateSleep(t, st, childID, "2024-01-15T09:00:00+07:00", "2024-01-15T10:00:00+07:00", "")
+	if _, err := st.UpdateSleep(other.ID, "", "2024-01-15T13:15:00+07:00", ""); !errors.As(err, &conflict) {
+		t.Errorf("UpdateSleep err = %v, want ConflictError", err)
+	}
+}
+
+func TestOverlap_BottleFeedsIgnored(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	if _, _, err := st.CreateFeeding(childID, "breast_left", "2024-01-15T13:00:00+07:00", "", nil); err != nil {
+		t.Fatalf("CreateFeeding: %v", err)
+	}
+	if _, _, err := st.CreateFeeding(childID, "bottle", "2024-01-15T13:10:00+07:00", "", intPtr(60)); err != nil {
+		t.Errorf("bottle during breast feed: %v", err)
+	}
+	if _, _, err := st.CreateFeeding(childID, "breast_right", "2024-01-15T13:20:00+07:00", "", nil); err == nil {
+		t.Error("expected second breast feed to conflict with the active one")
+	}
+}
+
+func TestOverlap_Truncate(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	early := mustCreateSleep(t, st, childID, "2024-01-15T13:00:00+07:00", "2024-01-15T14:00:00+07:00", "")
+	mustCreateSleep(t, st, childID, "2024-01-15T15:00:00+07:00", "2024-01-15T16:00:00+07:00", "")
+
+	sl, _, err := st.WithResolve(store.ResolveTruncate).CreateSleep(childID, "2024-01-15T13:30:00+07:00", "")
+	if err != nil {
+		t.Fatalf("CreateSleep: %v", err)
+	}
+	if sl.EndTime == nil || *sl.EndTime != "2024-01-15T15:00:00+07:00" {
+		t.Errorf("new EndTime = %v, want the later sleep's start", sl.EndTime)
+	}
+	if sl.DurationMinutes == nil || *sl.DurationMinutes != 90 {
+		t.Errorf("new DurationMinutes = %v, want 90", sl.DurationMinutes)
+	}
+	logs, _ := st.GetSleepLogs(childID, "")
+	for _, l := range logs {
+		if l.ID == early.ID && (l.DurationMinutes == nil || *l.DurationMinutes != 30) {
+			t.Errorf("earlier DurationMinutes = %v, want 30", l.DurationMinutes)
+		}
+	}
+}
+
+func TestOverlap_TruncateExcludesPauses(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	sl, _, _ := st.CreateSleep(childID, "2024-01-15T10:00:00+07:00", "")
+	st.PauseTimer("sleep", sl.ID, "2024-01-15T10:30:00+07:00")
+	st.ResumeTimer("sleep", sl.ID, "2024-01-15T11:00:00+07:00")
+	if _, err := st.PauseTimer("sleep", sl.ID, "2024-01-15T11:40:00+07:00"); err != nil {
+		t.Fatalf("PauseTimer: %v", err)
+	}
+
+	// The ongoing sleep ends at 11:50, still paused since 11:40.
+	if _, _, err := st.WithResolve(store.ResolveTruncate).CreateSleepSpan(childID, "2024-01-15T11:50:00+07:00", "2024-01-15T12:30:00+07:00", ""); err != nil {
+		t.Fatalf("CreateSleepSpan: %v", err)
+	}
+	logs, _ := st.GetSleepLogs(childID, "")
+	for _, l := range logs {
+		if l.ID != sl.ID {
+			continue
+		}
+		if l.DurationMinutes == nil || *l.DurationMinutes != 70 {
+			t.Errorf("truncated DurationMinutes = %v, want 110 less 40 paused", l.DurationMinutes)
+		}
+		if l.PausedAt != nil {
+			t.Errorf("PausedAt = %v, want the open pause closed at the new end", *l.PausedAt)
+		}
+	}
+}
+
+func TestOverlap_Merge(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	nap := mustCreateSleep(t, st, childID, "2024-01-15T13:00:00+07:00", "2024-01-15T14:00:00+07:00", "crib")
+
+	sl, _, err := st.WithResolve(store.ResolveMerge).CreateSleepSpan(childID, "2024-01-15T12:30:00+07:00", "2024-01-15T13:30:00+07:00", "stroller")
+	if err != nil {
+		t.Fatalf("CreateSleep: %v", err)
+	}
+	if sl.StartTime != "2024-01-15T12:30:00+07:00" || sl.EndTime == nil || *sl.EndTime != "2024-01-15T14:00:00+07:00" {
+		t.Errorf("merged = %s..%v, want 12:30..14:00", sl.StartTime, sl.EndTime)
+	}
+	if sl.Notes != "stroller; crib" {
+		t.Errorf("Notes = %q, want joined notes", sl.Notes)
+	}
+	logs, _ := st.GetSleepLogs(childID, "")
+	if len(logs) != 1 || logs[0].ID != sl.ID {
+		t.Errorf("logs = %d, want only the merged sleep", len(logs))
+	}
+	trash, _ := st.ListTrash(childID)
+	if len(trash) != 1 || trash[0].ID != nap.ID {
+		t.Errorf("trash = %+v, want the absorbed sleep", trash)
+	}
+
+	// Restoring the absorbed sleep would overlap the merged one again.
+	var conflict *store.ConflictError
+	if _, err := st.WithResolve(store.ResolveMerge).RestoreLog("sleep", nap.ID); !errors.As(err, &conflict) || conflict.IDs[0] != sl.ID {
+		t.Errorf("RestoreLog err = %v, want ConflictError with the merged sleep", err)
+	}
+	if trash, _ := st.ListTrash(childID); len(trash) != 1 {
+		t.Errorf("trash after failed restore = %d items, want the absorbed sleep kept", len(trash))
+	}
+}
+
+func TestOverlap_TruncateFeedSegments(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	f, _, _ := st.CreateFeeding(childID, "breast_left", "2024-01-15T13:00:00+07:00", "", nil)
+	st.SwitchFeedingSide(f.ID, "2024-01-15T13:10:00+07:00")
+	st.SwitchFeedingSide(f.ID, "2024-01-15T13:30:00+07:00")
+
+	if _, _, err := st.WithResolve(store.ResolveTruncate).CreateFeeding(childID, "breast_right", "2024-01-15T13:20:00+07:00", "", nil); err != nil {
+		t.Fatalf("CreateFeeding: %v", err)
+	}
+	logs, _ := st.GetFeedingLogs(childID, "")
+	for _, l := range logs {
+		if l.ID != f.ID {
+			continue
+		}
+		if len(l.Segments) != 2 || l.Segments[1].Side != "right" || l.Segments[1].EndTime == nil || *l.Segments[1].EndTime != "2024-01-15T13:20:00+07:00" {
+			t.Errorf("segments = %+v, want left then right ending at 13:20", l.Segments)
+		}
+		if *l.LeftMinutes != 10 || *l.RightMinutes != 10 {
+			t.Errorf("minutes = left %d, right %d; want 10, 10", *l.LeftMinutes, *l.RightMinutes)
+		}
+	}
+	if next, err := st.GetNextBreastSide(childID); err != nil || next.LastFeedingID != f.ID || next.LastSide != "right" {
+		t.Errorf("next side = %+v, %v; want from the truncated feed ending on the right", next, err)
+	}
+}
+
+func TestOverlap_MergeFeedSegments(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	st.CreateFeedingSpan(childID, "breast_left", "", "2024-01-15T13:00:00+07:00", "2024-01-15T13:20:00+07:00", "", nil)
+
+	f, _, err := st.WithResolve(store.ResolveMerge).CreateFeedingSpan(childID, "breast_right", "", "2024-01-15T13:10:00+07:00", "2024-01-15T13:30:00+07:00", "", nil)
+	if err != nil {
+		t.Fatalf("CreateFeedingSpan: %v", err)
+	}
+	if f.FeedType != "breast_left" || f.StartTime != "2024-01-15T13:00:00+07:00" {
+		t.Errorf("merged = %s from %s, want breast_left from 13:00", f.FeedType, f.StartTime)
+	}
+	if len(f.Segments) != 2 || f.Segments[0].Side != "left" || f.Segments[1].Side != "right" {
+		t.Fatalf("segments = %+v, want left then right", f.Segments)
+	}
+	if *f.LeftMinutes != 10 || *f.RightMinutes != 20 {
+		t.Errorf("minutes = left %d, right %d; want 10, 20", *f.LeftMinutes, *f.RightMinutes)
+	}
+}
@@ -0,0 +1,244 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"baby-care/internal/model"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTimerNotRunning is returned when pausing or resuming a sleep or feed
+	// that has ended, or a bottle.
+	ErrTimerNotRunning = errors.New("timer is not running")
+	ErrTimerPaused     = errors.New("timer is already paused")
+	ErrTimerNotPaused  = errors.New("timer is not paused")
+)
+
+// PauseTimer pauses the running sleep or breast feed id at at (now when
+// empty) and returns the updated entry.
+func (s *Store) PauseTimer(kind, id, at string) (any, error) {
+	var entry any
+	err := s.inTx(func(tx *Store) error {
+		start, err := tx.runningTimer(kind, id)
+		if err != nil {
+			return err
+		}
+		pauses, err := tx.loadPauses(kind, id)
+		if err != nil {
+			return err
+		}
+		// A new pause starts after the timer started and the last pause ended.
+		after := start
+		if n := len(pauses[id]); n > 0 {
+			last := pauses[id][n-1]
+			if last.ResumedAt == nil {
+				return ErrTimerPaused
+			}
+			after = *last.ResumedAt
+		}
+		if at == "" {
+			at = tx.nowLocal()
+		}
+		if err := (span{start: after, end: at}).check(); err != nil {
+			return err
+		}
+
+		before, err := tx.getLog(kind, id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.db.Exec(
+			`INSERT INTO timer_pauses (id, log_kind, log_id, paused_at) VALUES (?,?,?,?)`,
+			uuid.NewString(), kind, id, at,
+		)
+		if err != nil {
+			return fmt.Errorf("insert timer pause: %w", err)
+		}
+		entry, err = tx.timerChanged(kind, id, before)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// ResumeTimer ends the pause of the sleep or breast feed id at at (now when
+// empty) and returns the updated entry.
+func (s *Store) ResumeTimer(kind, id, at string) (any, error) {
+	var entry any
+	err := s.inTx(func(tx *Store) error {
+		if _, err := tx.runningTimer(kind, id); err != nil {
+			return err
+		}
+		pauses, err := tx.loadPauses(kind, id)
+		if err != nil {
+			return err
+		}
+		n := len(pauses[id])
+		if n == 0 || pauses[id][n-1].ResumedAt != nil {
+			return ErrTimerNotPaused
+		}
+		if at == "" {
+			at = tx.nowLocal()
+		}
+		if err := (span{start: pauses[id][n-1].PausedAt, end: at}).check(); err != nil {
+			return err
+		}
+
+		before, err := tx.getLog(kind, id)
+		if err != nil {
+			return err
+		}
+		if err := closePauses(tx.db, kind, id, at); err != nil {
+			return err
+		}
+		entry, err = tx.timerChanged(kind, id, before)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// timerChanged audits a pause or resume and returns the entry after it.
+func (s *Store) timerChanged(kind, id string, before any) (any, error) {
+	after, err := s.getLog(kind, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.audit(AuditUpdate, kind, id, before, after); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// runningTimer returns the start of the live, unfinished sleep or breast feed
+// id.
+func (s *Store) runningTimer(kind, id string) (string, error) {
+	cond := `end_time IS NULL`
+	switch kind {
+	case "sleep":
+	case "feeding":
+		cond += ` AND feed_type != 'bottle'`
+	default:
+		return "", fmt.Errorf("log kind %q has no timer", kind)
+	}
+	var start string
+	var running bool
+	err := s.db.QueryRow(`SELECT start_time, `+cond+` FROM `+logTables[kind]+` WHERE id=? AND deleted_at IS NULL`, id).Scan(&start, &running)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("lookup %s timer: %w", kind, err)
+	}
+	if !running {
+		return "", ErrTimerNotRunning
+	}
+	return start, nil
+}
+
+// loadPauses returns the pauses of the given entries of kind by entry ID,
+// oldest first.
+func (s *Store) loadPauses(kind string, ids ...string) (map[string][]model.TimerPause, error) {
+	pauses := map[string][]model.TimerPause{}
+	if len(ids) == 0 {
+		return pauses, nil
+	}
+	args := []any{kind}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	rows, err := s.db.Query(
+		`SELECT log_id, paused_at, resumed_at FROM timer_pauses WHERE log_kind=? AND log_id IN (?`+strings.Repeat(",?", len(ids)-1)+`) ORDER BY unixepoch(paused_at)`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query timer pauses: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var p model.TimerPause
+		if err := rows.Scan(&id, &p.PausedAt, &p.ResumedAt); err != nil {
+			return nil, err
+		}
+		pauses[id] = append(pauses[id], p)
+	}
+	return pauses, rows.Err()
+}
+
+// pausedAt returns when the timer was paused if it still is.
+func pausedAt(pauses []model.TimerPause) *string {
+	if n := len(pauses); n > 0 && pauses[n-1].ResumedAt == nil {
+		return &pauses[n-1].PausedAt
+	}
+	return nil
+}
+
+// closePauses ends an open pause of a timer at end, or where the pause began
+// if end is earlier.
+func closePauses(db execer, kind, id, end string) error {
+	_, err := db.Exec(
+		`UPDATE timer_pauses SET resumed_at = CASE WHEN unixepoch(?) > unixepoch(paused_at) THEN ? ELSE paused_at END WHERE log_kind=? AND log_id=? AND resumed_at IS NULL`,
+		end, end, kind, id,
+	)
+	if err != nil {
+		return fmt.Errorf("close timer pauses: %w", err)
+	}
+	return nil
+}
+
+// deleteTimerPauses removes the pauses of a purged entry.
+func deleteTimerPauses(db execer, kind, id string) error {
+	if _, err := db.Exec(`DELETE FROM timer_pauses WHERE log_kind=? AND log_id=?`, kind, id); err != nil {
+		return fmt.Errorf("delete timer pauses: %w", err)
+	}
+	return nil
+}
+
+// activeMinutes is the length of a finished span less the time paused within
+// it, or nil while the span is ongoing.
+func activeMinutes(sp span, pauses []model.TimerPause) *int {
+	start, end, ok := sp.times()
+	if !ok || sp.end == "" {
+		return nil
+	}
+	d := int((end.Sub(start) - pausedWithin(pauses, start, end)).Minutes())
+	return &d
+}
+
+// pausedWithin sums the parts of the pauses that fall between start and end.
+// A pause that is still open runs to end.
+func pausedWithin(pauses []model.TimerPause, start, end time.Time) time.Duration {
+	var total time.Duration
+	for _, p := range pauses {
+		from, err := time.Parse(time.RFC3339, p.PausedAt)
+		if err != nil {
+			continue
+		}
+		to := end
+		if p.ResumedAt != nil {
+			if to, err = time.Parse(time.RFC3339, *p.ResumedAt); err != nil {
+				continue
+			}
+		}
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		if to.After(from) {
+			total += to.Sub(from)
+		}
+	}
+	return total
+}
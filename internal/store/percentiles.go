@@ -0,0 +1,108 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"baby-care/internal/catalog"
+	"baby-care/internal/model"
+)
+
+// GrowthScore places a measurement on a WHO growth standard.
+type GrowthScore struct {
+	Z          float64 `json:"z"`          // rounded to hundredths
+	Percentile float64 `json:"percentile"` // rounded to tenths
+}
+
+// AssessedGrowth is a growth log scored against the WHO growth standards. A
+// score is left out when the measurement is missing, the child's gender has
+// no standard, or the age or length falls outside the tables.
+type AssessedGrowth struct {
+	*model.GrowthLog
+	AgeDays                 int             `json:"age_days"`
+	WeightForAge            *GrowthScore    `json:"weight_for_age,omitempty"`
+	LengthForAge            *GrowthScore    `json:"length_for_age,omitempty"`
+	HeadCircumferenceForAge *GrowthScore    `json:"head_circumference_for_age,omitempty"`
+	WeightForLength         *GrowthScore    `json:"weight_for_length,omitempty"`
+	WeightVelocity          *GrowthVelocity `json:"weight_velocity,omitempty"`
+	LengthVelocity          *GrowthVelocity `json:"length_velocity,omitempty"`
+	Warnings                []GrowthWarning `json:"warnings,omitempty"`
+}
+
+// GetAssessedGrowth returns the growth logs of a child, oldest first, with
+// the z-score and percentile of each measurement for the child's age and
+// gender, the growth velocity since the previous measurement and warnings
+// about worrying trends.
+func (s *Store) GetAssessedGrowth(childID string) ([]*AssessedGrowth, error) {
+	child, err := s.GetChildByID(childID)
+	if err != nil {
+		return nil, err
+	}
+	dob, err := time.Parse("2006-01-02", child.DateOfBirth)
+	if err != nil {
+		return nil, fmt.Errorf("child date of birth: %w", err)
+	}
+	logs, err := s.GetGrowthLogs(childID)
+	if err != nil {
+		return nil, err
+	}
+	assessed := []*AssessedGrowth{}
+	for _, l := range logs {
+		a := &AssessedGrowth{GrowthLog: l}
+		if on, err := time.Parse("2006-01-02", l.MeasuredOn); err == nil {
+			a.AgeDays = int(on.Sub(dob).Hours() / 24)
+		}
+		months := float64(a.AgeDays) / catalog.DaysPerMonth
+		if a.AgeDays >= 0 {
+			a.WeightForAge = growthScore(catalog.WeightForAge, child.Gender, months, kilograms(l.WeightGrams))
+			a.LengthForAge = growthScore(catalog.LengthForAge, child.Gender, months, centimetres(l.LengthMM))
+			a.HeadCircumferenceForAge = growthScore(catalog.HeadCircumferenceForAge, child.Gender, months, centimetres(l.HeadCircumferenceMM))
+		}
+		if length := centimetres(l.LengthMM); length != nil {
+			a.WeightForLength = growthScore(catalog.WeightForLength, child.Gender, *length, kilograms(l.WeightGrams))
+		}
+		assessed = append(assessed, a)
+	}
+	assessTrends(assessed, child.Gender)
+	return assessed, nil
+}
+
+// growthScore scores y (kg or cm) at x on the standard of an indicator, or
+// returns nil when it cannot be scored.
+func growthScore(indicator, sex string, x float64, y *float64) *GrowthScore {
+	g, ok := catalog.GrowthStandardFor(indicator)
+	if !ok || y == nil {
+		return nil
+	}
+	z, ok := g.ZScore(sex, x, *y)
+	if !ok {
+		return nil
+	}
+	z = math.Round(z*100) / 100
+	if z == 0 {
+		z = 0 // not -0
+	}
+	return &GrowthScore{
+		Z:          z,
+		Percentile: roundTenth(catalog.Percentile(z)),
+	}
+}
+
+// kilograms converts a stored weight to the kilograms of the standards.
+func kilograms(g *int) *float64 {
+	if g == nil {
+		return nil
+	}
+	kg := float64(*g) / 1000
+	return &kg
+}
+
+// centimetres converts a stored length to the centimetres of the standards.
+func centimetres(mm *int) *float64 {
+	if mm == nil {
+		return nil
+	}
+	cm := float64(*mm) / 10
+	return &cm
+}
@@ -0,0 +1,240 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"baby-care/internal/catalog"
+)
+
+const (
+	// predictionDays is how far back predictions learn from.
+	predictionDays = 7
+	// sameTimeOfDay is how close to the current time of day a past interval
+	// must have begun to count as a sample for it.
+	sameTimeOfDay = 2 * time.Hour
+	// minSamples is the number of same-time-of-day intervals needed before
+	// the rest of the day is ignored.
+	minSamples = 3
+	// clusterFeedGap is the gap under which a feed counts as part of the one
+	// before, e.g. a top-up or a cluster feed.
+	clusterFeedGap = 20 * time.Minute
+	// maxLogGap is the gap over which an interval is taken to be a
+	// hole in the log rather than a real one.
+	maxLogGap = 8 * time.Hour
+	// napWindowSlack widens the child's typical wake time into a window.
+	napWindowSlack = 15 * time.Minute
+)
+
+// Predictions estimates when the child will next be hungry and tired. An
+// estimate is left out when there is not enough history to make it.
+type Predictions struct {
+	NextFeed *FeedPrediction `json:"next_feed,omitempty"`
+	NextNap  *NapPrediction  `json:"next_nap,omitempty"`
+}
+
+// FeedPrediction is when the next feed is likely, from the typical time
+// between the starts of recent feeds around this time of day.
+type FeedPrediction struct {
+	At              string `json:"at"`
+	LastFeedAt      string `json:"last_feed_at"`
+	IntervalMinutes int    `json:"interval_minutes"`
+	Samples         int    `json:"samples"` // intervals the estimate is based on
+	Overdue         bool   `json:"overdue"` // At has passed
+}
+
+// NapPrediction is the window in which the child is likely to get tired
+// again, from the recommended wake window for their age and their own
+// typical time awake around this time of day. It is left out while the
+// child sleeps.
+type NapPrediction struct {
+	From                   string  `json:"from"`
+	To                     string  `json:"to"`
+	AwakeSince             string  `json:"awake_since"`
+	WakeMinutes            *int    `json:"wake_minutes,omitempty"`             // the child's typical time awake
+	RecommendedWakeMinutes *[2]int `json:"recommended_wake_minutes,omitempty"` // for their age
+	Samples                int     `json:"samples"`
+}
+
+// interval is a stretch between two logged events.
+type interval struct {
+	from   time.Time
+	length time.Duration
+}
+
+// GetPredictions predicts the next feed and nap as of now from the feeds and
+// sleeps of the past week.
+func (s *Store) GetPredictions(childID string, now time.Time) (*Predictions, error) {
+	child, err := s.GetChildByID(childID)
+	if err != nil {
+		return nil, err
+	}
+	dob, err := time.Parse("2006-01-02", child.DateOfBirth)
+	if err != nil {
+		return nil, fmt.Errorf("child date of birth: %w", err)
+	}
+	now = now.In(s.Location())
+	p := &Predictions{}
+	if p.NextFeed, err = s.predictFeed(childID, now); err != nil {
+		return nil, err
+	}
+	if p.NextNap, err = s.predictNap(childID, now, ageWeeks(dob, now)); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *Store) predictFeed(childID string, now time.Time) (*FeedPrediction, error) {
+	rows, err := s.db.Query(
+		`SELECT start_time FROM feeding_logs WHERE child_id=? AND unixepoch(start_time) >= ? AND unixepoch(start_time) <= ? AND deleted_at IS NULL ORDER BY unixepoch(start_time)`,
+		childID, now.AddDate(0, 0, -predictionDays).Unix(), now.Unix(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query feeds for prediction: %w", err)
+	}
+	defer rows.Close()
+	var starts []time.Time
+	for rows.Next() {
+		var ts string
+		if err := rows.Scan(&ts); err != nil {
+			return nil, err
+		}
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			starts = append(starts, t)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(starts) == 0 {
+		return nil, nil
+	}
+
+	var gaps []interval
+	prev := starts[0]
+	for _, t := range starts[1:] {
+		d := t.Sub(prev)
+		if d < clusterFeedGap {
+			continue
+		}
+		if d <= maxLogGap {
+			gaps = append(gaps, interval{prev, d})
+		}
+		prev = t
+	}
+	last := prev // the start of the last feed, top-ups included
+	typical, n := s.typicalInterval(gaps, last)
+	if n == 0 {
+		return nil, nil
+	}
+	at := last.Add(typical)
+	return &FeedPrediction{
+		At:              at.In(s.Location()).Format(time.RFC3339),
+		LastFeedAt:      last.In(s.Location()).Format(time.RFC3339),
+		IntervalMinutes: int(typical.Minutes()),
+		Samples:         n,
+		Overdue:         at.Before(now),
+	}, nil
+}
+
+func (s *Store) predictNap(childID string, now time.Time, weeks int) (*NapPrediction, error) {
+	if _, err := s.GetActiveSleep(childID); err == nil {
+		return nil, nil
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	rows, err := s.db.Query(
+		`SELECT start_time, end_time FROM sleep_logs WHERE child_id=? AND end_time IS NOT NULL AND unixepoch(end_time) >= ? AND unixepoch(end_time) <= ? AND deleted_at IS NULL ORDER BY unixepoch(start_time)`,
+		childID, now.AddDate(0, 0, -predictionDays).Unix(), now.Unix(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query sleeps for prediction: %w", err)
+	}
+	defer rows.Close()
+	var wakes []interval
+	var awake time.Time
+	for rows.Next() {
+		var startTS, endTS string
+		if err := rows.Scan(&startTS, &endTS); err != nil {
+			return nil, err
+		}
+		start, err1 := time.Parse(time.RFC3339, startTS)
+		end, err2 := time.Parse(time.RFC3339, endTS)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		if d := start.Sub(awake); !awake.IsZero() && d > 0 && d <= maxLogGap {
+			wakes = append(wakes, interval{awake, d})
+		}
+		if end.After(awake) {
+			awake = end
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if awake.IsZero() {
+		return nil, nil
+	}
+
+	nap := &NapPrediction{AwakeSince: awake.In(s.Location()).Format(time.RFC3339)}
+	typical, n := s.typicalInterval(wakes, awake)
+	nap.Samples = n
+	lo, hi := typical-napWindowSlack, typical+napWindowSlack
+	if n > 0 {
+		mins := int(typical.Minutes())
+		nap.WakeMinutes = &mins
+	}
+	if need, ok := catalog.SleepNeedAt(weeks); ok {
+		rec := need.WakeMinutes
+		nap.RecommendedWakeMinutes = &rec
+		recLo, recHi := time.Duration(rec[0])*time.Minute, time.Duration(rec[1])*time.Minute
+		if n == 0 {
+			lo, hi = recLo, recHi
+		} else {
+			// Keep the child's own rhythm within the recommended window.
+			typical = min(max(typical, recLo), recHi)
+			lo, hi = max(typical-napWindowSlack, recLo), min(typical+napWindowSlack, recHi)
+		}
+	} else if n == 0 {
+		return nil, nil
+	}
+	nap.From = awake.Add(max(lo, 0)).In(s.Location()).Format(time.RFC3339)
+	nap.To = awake.Add(hi).In(s.Location()).Format(time.RFC3339)
+	return nap, nil
+}
+
+// typicalInterval returns the median length of the intervals that began
+// within sameTimeOfDay of the time of day of at, or of all of them when
+// fewer than minSamples did, and the number of intervals it is taken from.
+func (s *Store) typicalInterval(intervals []interval, at time.Time) (time.Duration, int) {
+	clock := func(t time.Time) time.Duration {
+		t = t.In(s.Location())
+		return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	var near, all []time.Duration
+	for _, iv := range intervals {
+		all = append(all, iv.length)
+		d := clock(iv.from) - clock(at)
+		if d < 0 {
+			d = -d
+		}
+		if min(d, 24*time.Hour-d) <= sameTimeOfDay {
+			near = append(near, iv.length)
+		}
+	}
+	if len(near) < minSamples {
+		near = all
+	}
+	if len(near) == 0 {
+		return 0, 0
+	}
+	slices.Sort(near)
+	mid := len(near) / 2
+	if len(near)%2 == 0 {
+		return (near[mid-1] + near[mid]) / 2, len(near)
+	}
+	return near[mid], len(near)
+}
@@ -0,0 +1,88 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetPredictions(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st) // born 2024-01-01, five weeks old below
+
+	// Feeds every three to four hours, but only two and a half after the
+	// 09:00 feed; the 09:10 feed on the last day is a top-up.
+	for _, day := range []string{"2024-02-02", "2024-02-03", "2024-02-04"} {
+		for _, at := range []string{"02:00", "06:00", "09:00", "11:30", "15:00", "18:00", "22:00"} {
+			st.CreateFeeding(childID, "bottle", day+"T"+at+":00+07:00", "", intPtr(90))
+		}
+	}
+	for _, at := range []string{"02:00", "06:00", "09:00", "09:10"} {
+		st.CreateFeeding(childID, "bottle", "2024-02-05T"+at+":00+07:00", "", intPtr(90))
+	}
+
+	// Awake about 70 minutes at a time in the morning.
+	for _, sp := range [][2]string{
+		{"2024-02-04T07:00", "2024-02-04T08:00"},
+		{"2024-02-04T09:10", "2024-02-04T10:30"},
+		{"2024-02-04T11:40", "2024-02-04T12:30"},
+		{"2024-02-05T05:30", "2024-02-05T07:00"},
+		{"2024-02-05T08:00", "2024-02-05T09:00"},
+	} {
+		sl, _, _ := st.CreateSleep(childID, sp[0]+":00+07:00", "")
+		st.UpdateSleep(sl.ID, "", sp[1]+":00+07:00", "")
+	}
+
+	now, _ := time.Parse(time.RFC3339, "2024-02-05T10:00:00+07:00")
+	p, err := st.GetPredictions(childID, now)
+	if err != nil {
+		t.Fatalf("GetPredictions: %v", err)
+	}
+	feed := p.NextFeed
+	if feed == nil {
+		t.Fatal("NextFeed missing")
+	}
+	if feed.LastFeedAt != "2024-02-05T09:00:00+07:00" || feed.IntervalMinutes != 150 || feed.Samples != 3 || feed.At != "2024-02-05T11:30:00+07:00" || feed.Overdue {
+		t.Errorf("NextFeed = %+v, want 11:30 from three 150-minute morning intervals", feed)
+	}
+
+	nap := p.NextNap
+	if nap == nil {
+		t.Fatal("NextNap missing")
+	}
+	if nap.AwakeSince != "2024-02-05T09:00:00+07:00" || nap.WakeMinutes == nil || *nap.WakeMinutes != 70 || nap.Samples != 3 {
+		t.Errorf("NextNap = %+v, want 70 minutes typical awake from 09:00", nap)
+	}
+	if nap.RecommendedWakeMinutes == nil || *nap.RecommendedWakeMinutes != [2]int{60, 90} {
+		t.Errorf("RecommendedWakeMinutes = %v, want [60 90]", nap.RecommendedWakeMinutes)
+	}
+	// 70 ± 15 minutes, cut to the recommended 60.
+	if nap.From != "2024-02-05T10:00:00+07:00" || nap.To != "2024-02-05T10:25:00+07:00" {
+		t.Errorf("nap window = %s – %s, want 10:00 – 10:25", nap.From, nap.To)
+	}
+
+	// Late for the feed, and asleep: no nap to predict.
+	st.CreateSleep(childID, "2024-02-05T10:15:00+07:00", "")
+	p, err = st.GetPredictions(childID, now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("GetPredictions: %v", err)
+	}
+	if p.NextFeed == nil || !p.NextFeed.Overdue {
+		t.Errorf("NextFeed = %+v, want overdue", p.NextFeed)
+	}
+	if p.NextNap != nil {
+		t.Errorf("NextNap = %+v while asleep, want none", p.NextNap)
+	}
+}
+
+func TestGetPredictions_NoHistory(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	p, err := st.GetPredictions(childID, time.Now())
+	if err != nil {
+		t.Fatalf("GetPredictions: %v", err)
+	}
+	if p.NextFeed != nil || p.NextNap != nil {
+		t.Errorf("predictions = %+v, want none without history", p)
+	}
+}
@@ -0,0 +1,188 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"baby-care/internal/model"
+	"github.com/google/uuid"
+)
+
+const pumpingColumns = `id, child_id, start_time, end_time, duration_minutes, left_ml, right_ml, notes, created_at, COALESCE(created_by,''), deleted_at`
+
+// CreatePumping starts a pumping session, or logs a finished one when endTime
+// is set.
+func (s *Store) CreatePumping(childID, startTime, endTime, notes string, leftML, rightML *int) (*model.PumpingLog, error) {
+	now := s.nowLocal()
+	if startTime == "" {
+		startTime = now
+	}
+	sp := span{start: startTime, end: endTime, notes: notes}
+	if err := sp.check(); err != nil {
+		return nil, err
+	}
+	var log *model.PumpingLog
+	err := s.inTx(func(tx *Store) error {
+		if err := tx.resolveOverlaps("pumping", childID, "", &sp); err != nil {
+			return err
+		}
+		log = &model.PumpingLog{
+			ID:              uuid.NewString(),
+			ChildID:         childID,
+			StartTime:       sp.start,
+			DurationMinutes: sp.durationMinutes(),
+			LeftML:          leftML,
+			RightML:         rightML,
+			Notes:           sp.notes,
+			CreatedAt:       now,
+			CreatedBy:       tx.actor,
+		}
+		if sp.end != "" {
+			log.EndTime = &sp.end
+		}
+		if _, err := tx.db.Exec(
+			`INSERT INTO pumping_logs (id, child_id, start_time, end_time, duration_minutes, left_ml, right_ml, notes, created_at, created_by) VALUES (?,?,?,?,?,?,?,?,?,?)`,
+			log.ID, log.ChildID, log.StartTime, sp.endValue(), log.DurationMinutes, log.LeftML, log.RightML, log.Notes, log.CreatedAt, tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert pumping: %w", err)
+		}
+		return tx.audit(AuditCreate, "pumping", log.ID, nil, log)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+func (s *Store) GetPumpingLogs(childID, date string) ([]*model.PumpingLog, error) {
+	query := `SELECT ` + pumpingColumns + ` FROM pumping_logs WHERE child_id=? AND deleted_at IS NULL`
+	args := []any{childID}
+	if date != "" {
+		start, end, err := s.dayBounds(date)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND unixepoch(start_time) >= ? AND unixepoch(start_time) < ?`
+		args = append(args, start, end)
+	}
+	query += ` ORDER BY unixepoch(start_time) DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query pumping: %w", err)
+	}
+	defer rows.Close()
+	return scanPumpingRows(rows)
+}
+
+func (s *Store) GetActivePumping(childID string) (*model.PumpingLog, error) {
+	row := s.db.QueryRow(
+		`SELECT ` + pumpingColumns + ` FROM pumping_logs WHERE child_id=? AND end_time IS NULL AND deleted_at IS NULL ORDER BY unixepoch(start_time) DESC LIMIT 1`,
+		childID,
+	)
+	return scanPumpingRow(row)
+}
+
+// UpdatePumping edits a session; stop the timer by setting endTime. Volumes
+// left nil keep their stored values. Lowering them below the milk already
+// bagged from the session fails with ErrExceedsPumped.
+func (s *Store) UpdatePumping(id, startTime, endTime, notes string, leftML, rightML *int) (*model.PumpingLog, error) {
+	var updated *model.PumpingLog
+	err := s.inTx(func(tx *Store) error {
+		existing, err := getPumpingByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if existing.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if leftML == nil {
+			leftML = existing.LeftML
+		}
+		if rightML == nil {
+			rightML = existing.RightML
+		}
+		// The session cannot yield less than was already bagged from it.
+		bagged, err := baggedML(tx.db, id)
+		if err != nil {
+			return err
+		}
+		pumped := 0
+		for _, ml := range []*int{leftML, rightML} {
+			if ml != nil {
+				pumped += *ml
+			}
+		}
+		if pumped < bagged {
+			return ErrExceedsPumped
+		}
+
+		sp := span{start: existing.StartTime, end: endTime, notes: notes}
+		if startTime != "" {
+			sp.start = startTime
+		}
+		if sp.end == "" && existing.EndTime != nil {
+			sp.end = *existing.EndTime
+		}
+		if err := sp.check(); err != nil {
+			return err
+		}
+		if err := tx.resolveOverlaps("pumping", existing.ChildID, id, &sp); err != nil {
+			return err
+		}
+
+		_, err = tx.db.Exec(
+			`UPDATE pumping_logs SET start_time=?, end_time=?, duration_minutes=?, left_ml=?, right_ml=?, notes=? WHERE id=?`,
+			sp.start, sp.endValue(), sp.durationMinutes(), leftML, rightML, sp.notes, id,
+		)
+		if err != nil {
+			return fmt.Errorf("update pumping: %w", err)
+		}
+		updated, err = getPumpingByID(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "pumping", id, existing, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeletePumping moves a pumping log to the trash; see RestoreLog.
+func (s *Store) DeletePumping(id string) error {
+	return s.trashLog("pumping", id)
+}
+
+func getPumpingByID(s *Store, id string) (*model.PumpingLog, error) {
+	row := s.db.QueryRow(
+		`SELECT ` + pumpingColumns + ` FROM pumping_logs WHERE id=?`, id,
+	)
+	return scanPumpingRow(row)
+}
+
+func scanPumpingRow(row *sql.Row) (*model.PumpingLog, error) {
+	var l model.PumpingLog
+	err := row.Scan(&l.ID, &l.ChildID, &l.StartTime, &l.EndTime, &l.DurationMinutes, &l.LeftML, &l.RightML, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func scanPumpingRows(rows *sql.Rows) ([]*model.PumpingLog, error) {
+	var logs []*model.PumpingLog
+	for rows.Next() {
+		var l model.PumpingLog
+		if err := rows.Scan(&l.ID, &l.ChildID, &l.StartTime, &l.EndTime, &l.DurationMinutes, &l.LeftML, &l.RightML, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt); err != nil {
+			return nil, err
+		}
+		logs = append(logs, &l)
+	}
+	return logs, rows.Err()
+}
@@ -0,0 +1,70 @@
+package store_test
+
+import "testing"
+
+const (
+	pump1Start = "2024-01-15T07:00:00+07:00"
+	pump1End   = "2024-01-15T07:25:00+07:00" // 25 min later
+	pump2Start = "2024-01-15T15:00:00+07:00"
+)
+
+func TestPumping_TimerLifecycle(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	p, err := st.CreatePumping(childID, pump1Start, "", "", nil, nil)
+	if err != nil {
+		t.Fatalf("CreatePumping: %v", err)
+	}
+	active, err := st.GetActivePumping(childID)
+	if err != nil || active.ID != p.ID {
+		t.Fatalf("GetActivePumping = %v, %v; want the new session", active, err)
+	}
+
+	// Volumes are usually entered when stopping.
+	p, err = st.UpdatePumping(p.ID, "", pump1End, "", intPtr(80), intPtr(60))
+	if err != nil {
+		t.Fatalf("UpdatePumping: %v", err)
+	}
+	if p.DurationMinutes == nil || *p.DurationMinutes != 25 {
+		t.Errorf("DurationMinutes = %v, want 25", p.DurationMinutes)
+	}
+	if _, err := st.GetActivePumping(childID); err == nil {
+		t.Error("expected no active session after stopping")
+	}
+
+	// Omitted volumes keep their stored values.
+	p, err = st.UpdatePumping(p.ID, "", "", "fridge", nil, nil)
+	if err != nil {
+		t.Fatalf("UpdatePumping notes: %v", err)
+	}
+	if p.LeftML == nil || *p.LeftML != 80 || p.RightML == nil || *p.RightML != 60 {
+		t.Errorf("volumes = %v/%v, want 80/60", p.LeftML, p.RightML)
+	}
+}
+
+func TestPumping_Totals(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	st.CreatePumping(childID, pump1Start, pump1End, "", intPtr(80), intPtr(60))
+	st.CreatePumping(childID, pump2Start, "2024-01-15T15:20:00+07:00", "", intPtr(100), nil)
+	deleted, _ := st.CreatePumping(childID, "2024-01-15T18:00:00+07:00", "2024-01-15T18:20:00+07:00", "", intPtr(50), intPtr(50))
+	st.DeletePumping(deleted.ID)
+
+	summary, err := st.GetDaySummary(childID, "2024-01-15")
+	if err != nil {
+		t.Fatalf("GetDaySummary: %v", err)
+	}
+	if summary.PumpingCount != 2 || summary.PumpedMLTotal != 240 {
+		t.Errorf("summary pumping = %d sessions, %d ml; want 2, 240", summary.PumpingCount, summary.PumpedMLTotal)
+	}
+
+	stats, err := st.GetAnalytics(childID, "2024-01-15", "2024-01-15")
+	if err != nil {
+		t.Fatalf("GetAnalytics: %v", err)
+	}
+	if stats[0].PumpingCount != 2 || stats[0].PumpedMLTotal != 240 {
+		t.Errorf("stats pumping = %d sessions, %d ml; want 2, 240", stats[0].PumpingCount, stats[0].PumpedMLTotal)
+	}
+}
@@ -0,0 +1,283 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"baby-care/internal/model"
+)
+
+var (
+	// ErrFeedingNotActive is returned when switching sides on a feed that is
+	// finished or is a bottle.
+	ErrFeedingNotActive = errors.New("feeding is not an ongoing breast feed")
+	// ErrCutsSegments is returned when new start or end times of a feed that
+	// switched sides would fall inside another segment than the first or last.
+	ErrCutsSegments = errors.New("times cut across a side switch")
+)
+
+// breastSide returns the side of a breast feed type, or "" for bottles.
+func breastSide(feedType string) string {
+	switch feedType {
+	case "breast_left":
+		return "left"
+	case "breast_right":
+		return "right"
+	}
+	return ""
+}
+
+func otherSide(side string) string {
+	if side == "left" {
+		return "right"
+	}
+	return "left"
+}
+
+// shapeSegments fits the segments of a feed to its new side and times. A feed
+// that never switched keeps one segment spanning the whole feed; one that did
+// has its first segment moved to the new start and its last to the new end,
+// with sides alternating from the starting side. Bottles have no segments.
+func shapeSegments(segs []model.FeedingSegment, feedType string, sp span) ([]model.FeedingSegment, error) {
+	side := breastSide(feedType)
+	if side == "" {
+		return nil, nil
+	}
+	if len(segs) <= 1 {
+		segs = []model.FeedingSegment{{}}
+	} else {
+		segs = append([]model.FeedingSegment(nil), segs...)
+	}
+	first, last := &segs[0], &segs[len(segs)-1]
+	first.StartTime = sp.start
+	last.EndTime = nil
+	if sp.end != "" {
+		end := sp.end
+		last.EndTime = &end
+	}
+	for i := range segs {
+		segs[i].Side = side
+		if i%2 == 1 {
+			segs[i].Side = otherSide(side)
+		}
+		seg := span{start: segs[i].StartTime}
+		if segs[i].EndTime != nil {
+			seg.end = *segs[i].EndTime
+		}
+		if err := seg.check(); err != nil {
+			if len(segs) > 1 {
+				return nil, ErrCutsSegments
+			}
+			return nil, err
+		}
+		segs[i].DurationMinutes = seg.durationMinutes()
+	}
+	return segs, nil
+}
+
+// mergeSegments lays the segments of breast feeds merged into sp out on one
+// timeline: each runs until the next one starts and the last to the end of
+// sp, joining stretches on the same side. It returns the segments and the
+// feed type of the merged feed, which starts on the side fed first.
+func mergeSegments(segs []model.FeedingSegment, sp span) ([]model.FeedingSegment, string) {
+	segs = slices.Clone(segs)
+	slices.SortStableFunc(segs, func(a, b model.FeedingSegment) int {
+		return compareTimestamps(a.StartTime, b.StartTime)
+	})
+	var merged []model.FeedingSegment
+	for i, seg := range segs {
+		seg.EndTime, seg.DurationMinutes = nil, nil
+		if i+1 < len(segs) {
+			next := segs[i+1].StartTime
+			if compareTimestamps(seg.StartTime, next) == 0 {
+				continue
+			}
+			seg.EndTime = &next
+		} else if sp.end != "" {
+			end := sp.end
+			seg.EndTime = &end
+		}
+		if n := len(merged); n > 0 && merged[n-1].Side == seg.Side {
+			merged[n-1].EndTime = seg.EndTime
+			continue
+		}
+		merged = append(merged, seg)
+	}
+	return merged, "breast_" + merged[0].Side
+}
+
+// saveSegments replaces the stored segments of a feed.
+func saveSegments(db execer, feedingID string, segs []model.FeedingSegment) error {
+	if _, err := db.Exec(`DELETE FROM feeding_segments WHERE feeding_id=?`, feedingID); err != nil {
+		return fmt.Errorf("delete feeding segments: %w", err)
+	}
+	for i, seg := range segs {
+		_, err := db.Exec(
+			`INSERT INTO feeding_segments (feeding_id, seq, side, start_time, end_time) VALUES (?,?,?,?,?)`,
+			feedingID, i, seg.Side, seg.StartTime, seg.EndTime,
+		)
+		if err != nil {
+			return fmt.Errorf("insert feeding segment: %w", err)
+		}
+	}
+	return nil
+}
+
+// fillFeedings fills in the milk bottles drew from the stash, and the pauses,
+// segments and per-side minutes of breast feeds. Segment durations exclude
+// paused time.
+func (s *Store) fillFeedings(logs ...*model.FeedingLog) error {
+	byID := map[string]*model.FeedingLog{}
+	var ids, bottles []string
+	var args []any
+	for _, l := range logs {
+		if l.MilkType == "breast_milk" {
+			bottles = append(bottles, l.ID)
+		}
+		if breastSide(l.FeedType) != "" {
+			byID[l.ID] = l
+			ids = append(ids, l.ID)
+			args = append(args, l.ID)
+		}
+	}
+	uses, err := s.loadMilkUses(bottles...)
+	if err != nil {
+		return err
+	}
+	for _, l := range logs {
+		l.MilkUsed = uses[l.ID]
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	pauses, err := s.loadPauses("feeding", ids...)
+	if err != nil {
+		return err
+	}
+	for id, l := range byID {
+		l.Pauses = pauses[id]
+		l.PausedAt = pausedAt(l.Pauses)
+	}
+
+	rows, err := s.db.Query(
+		`SELECT feeding_id, side, start_time, end_time FROM feeding_segments WHERE feeding_id IN (?`+strings.Repeat(",?", len(ids)-1)+`) ORDER BY feeding_id, seq`,
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("query feeding segments: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var seg model.FeedingSegment
+		if err := rows.Scan(&id, &seg.Side, &seg.StartTime, &seg.EndTime); err != nil {
+			return err
+		}
+		sp := span{start: seg.StartTime}
+		if seg.EndTime != nil {
+			sp.end = *seg.EndTime
+		}
+		seg.DurationMinutes = activeMinutes(sp, pauses[id])
+		byID[id].Segments = append(byID[id].Segments, seg)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, l := range byID {
+		left, right := 0, 0
+		for _, seg := range l.Segments {
+			if seg.DurationMinutes == nil {
+				continue
+			}
+			if seg.Side == "left" {
+				left += *seg.DurationMinutes
+			} else {
+				right += *seg.DurationMinutes
+			}
+		}
+		l.LeftMinutes, l.RightMinutes = &left, &right
+	}
+	return nil
+}
+
+// SwitchFeedingSide ends the current segment of an ongoing breast feed at at
+// (now when empty) and continues the feed on the other side.
+func (s *Store) SwitchFeedingSide(id, at string) (*model.FeedingLog, error) {
+	var updated *model.FeedingLog
+	err := s.inTx(func(tx *Store) error {
+		existing, err := getFeedingByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if existing.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if existing.EndTime != nil || len(existing.Segments) == 0 {
+			return ErrFeedingNotActive
+		}
+		if at == "" {
+			at = tx.nowLocal()
+		}
+		segs := append([]model.FeedingSegment(nil), existing.Segments...)
+		current := &segs[len(segs)-1]
+		if err := (span{start: current.StartTime, end: at}).check(); err != nil {
+			return err
+		}
+		current.EndTime = &at
+		segs = append(segs, model.FeedingSegment{Side: otherSide(current.Side), StartTime: at})
+		if err := saveSegments(tx.db, id, segs); err != nil {
+			return err
+		}
+		// Offering the other side resumes a paused feed.
+		if err := closePauses(tx.db, "feeding", id, at); err != nil {
+			return err
+		}
+		updated, err = getFeedingByID(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "feeding", id, existing, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// NextSide recommends the breast to start the next feed on.
+type NextSide struct {
+	Side          string `json:"side"`
+	LastFeedingID string `json:"last_feeding_id"`
+	LastSide      string `json:"last_side"` // the side the last feed ended on
+	LastEndTime   string `json:"last_end_time"`
+}
+
+// GetNextBreastSide recommends a side from the last finished breast feed: the
+// side it ended on when it used both breasts, since that one was emptied
+// least, and otherwise the other side. It returns ErrNotFound before the
+// first breast feed.
+func (s *Store) GetNextBreastSide(childID string) (*NextSide, error) {
+	last, err := scanFeedingRow(s.db.QueryRow(
+		`SELECT `+feedingColumns+` FROM feeding_logs WHERE child_id=? AND feed_type IN ('breast_left','breast_right') AND end_time IS NOT NULL AND deleted_at IS NULL ORDER BY unixepoch(end_time) DESC LIMIT 1`,
+		childID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillFeedings(last); err != nil {
+		return nil, err
+	}
+	next := &NextSide{LastFeedingID: last.ID, LastSide: breastSide(last.FeedType), LastEndTime: *last.EndTime}
+	usedBoth := false
+	for _, seg := range last.Segments {
+		next.LastSide = seg.Side
+		usedBoth = usedBoth || seg.Side != breastSide(last.FeedType)
+	}
+	next.Side = otherSide(next.LastSide)
+	if usedBoth {
+		next.Side = next.LastSide
+	}
+	return next, nil
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"baby-care/internal/catalog"
+)
+
+const (
+	settingTimezone            = "timezone"
+	settingVaccinationSchedule = "vaccination_schedule"
+	settingNightStart          = "night_start"
+	settingNightEnd            = "night_end"
+)
+
+// ErrUnknownSchedule is returned when a vaccination schedule ID is not one of
+// the schedules shipped in the catalog.
+var ErrUnknownSchedule = errors.New("unknown vaccination schedule")
+
+// Settings are the household-wide preferences.
+type Settings struct {
+	// Timezone is the stored household timezone.
+	Timezone string `json:"timezone"`
+	// EffectiveTimezone differs from Timezone when the server was started
+	// with --tz.
+	EffectiveTimezone string `json:"effective_timezone"`
+	// VaccinationSchedule is the ID of the schedule due vaccinations are
+	// computed from.
+	VaccinationSchedule string `json:"vaccination_schedule"`
+	// NightStart and NightEnd (HH:MM, local time) bound the night: sleep
+	// starting within them is night sleep.
+	NightStart string `json:"night_start"`
+	NightEnd   string `json:"night_end"`
+}
+
+// GetSettings returns the household settings, with defaults for unset keys.
+func (s *Store) GetSettings() (*Settings, error) {
+	tz, err := s.getSetting(settingTimezone)
+	if errors.Is(err, ErrNotFound) {
+		tz = DefaultTimezone
+	} else if err != nil {
+		return nil, err
+	}
+	schedule, err := s.getSetting(settingVaccinationSchedule)
+	if errors.Is(err, ErrNotFound) {
+		schedule = catalog.DefaultVaccineSchedule
+	} else if err != nil {
+		return nil, err
+	}
+	night, err := s.nightWindow()
+	if err != nil {
+		return nil, err
+	}
+	return &Settings{
+		Timezone:            tz,
+		EffectiveTimezone:   s.Location().String(),
+		VaccinationSchedule: schedule,
+		NightStart:          night.startClock(),
+		NightEnd:            night.endClock(),
+	}, nil
+}
+
+// SetVaccinationSchedule picks the household's vaccination schedule.
+func (s *Store) SetVaccinationSchedule(id string) error {
+	if _, ok := catalog.VaccineScheduleByID(id); !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownSchedule, id)
+	}
+	return s.setSetting(settingVaccinationSchedule, id)
+}
+
+func (s *Store) getSetting(key string) (string, error) {
+	var value string
+	err := s.db.QueryRow(`SELECT value FROM settings WHERE key=?`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("read setting %s: %w", key, err)
+	}
+	return value, nil
+}
+
+func (s *Store) setSetting(key, value string) error {
+	return s.inTx(func(tx *Store) error {
+		var before any
+		old, err := tx.getSetting(key)
+		switch {
+		case err == nil:
+			before = old
+		case !errors.Is(err, ErrNotFound):
+			return err
+		}
+		_, err = tx.db.Exec(
+			`INSERT INTO settings (key, value, updated_at) VALUES (?,?,?)
+			 ON CONFLICT(key) DO UPDATE SET value=excluded.value, updated_at=excluded.updated_at`,
+			key, value, tx.nowLocal(),
+		)
+		if err != nil {
+			return fmt.Errorf("write setting %s: %w", key, err)
+		}
+		action := AuditUpdate
+		if before == nil {
+			action = AuditCreate
+		}
+		return tx.audit(action, "setting", key, before, value)
+	})
+}
@@ -0,0 +1,162 @@
+package store_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"baby-care/internal/store"
+)
+
+func TestSettings_DefaultTimezone(t *testing.T) {
+	st := newTestStore(t)
+	settings, err := st.GetSettings()
+	if err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if settings.Timezone != store.DefaultTimezone {
+		t.Errorf("Timezone = %q, want %q", settings.Timezone, store.DefaultTimezone)
+	}
+	if st.Location().String() != store.DefaultTimezone {
+		t.Errorf("Location = %q, want %q", st.Location(), store.DefaultTimezone)
+	}
+}
+
+func TestSetTimezone_PersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	st, err := store.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := st.SetTimezone("Europe/Berlin"); err != nil {
+		t.Fatalf("SetTimezone: %v", err)
+	}
+	st.Close()
+
+	st, err = store.Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer st.Close()
+	if got := st.Location().String(); got != "Europe/Berlin" {
+		t.Errorf("Location after reopen = %q, want Europe/Berlin", got)
+	}
+}
+
+func TestSetTimezone_Invalid(t *testing.T) {
+	st := newTestStore(t)
+	for _, name := range []string{"", "Mars/Olympus_Mons"} {
+		if err := st.SetTimezone(name); !errors.Is(err, store.ErrInvalidTimezone) {
+			t.Errorf("SetTimezone(%q) error = %v, want ErrInvalidTimezone", name, err)
+		}
+	}
+}
+
+func TestOverrideTimezone_WinsOverSetting(t *testing.T) {
+	st := newTestStore(t)
+	if err := st.OverrideTimezone("America/New_York"); err != nil {
+		t.Fatalf("OverrideTimezone: %v", err)
+	}
+	if err := st.SetTimezone("Europe/Berlin"); err != nil {
+		t.Fatalf("SetTimezone: %v", err)
+	}
+	settings, _ := st.GetSettings()
+	if settings.Timezone != "Europe/Berlin" {
+		t.Errorf("stored Timezone = %q, want Europe/Berlin", settings.Timezone)
+	}
+	if settings.EffectiveTimezone != "America/New_York" {
+		t.Errorf("EffectiveTimezone = %q, want America/New_York", settings.EffectiveTimezone)
+	}
+}
+
+func TestDayBucketing_AcrossDST(t *testing.T) {
+	st := newTestStore(t)
+	if err := st.SetTimezone("Europe/Berlin"); err != nil {
+		t.Fatalf("SetTimezone: %v", err)
+	}
+	childID := mustCreateChild(t, st)
+
+	// Europe/Berlin switches to summer time at 02:00 on 2024-03-31, so that
+	// local day is only 23 hours long.
+	for _, ts := range []string{
+		"2024-03-30T23:30:00Z",      // 00:30 CET on the 31st, despite the UTC date
+		"2024-03-31T03:30:00+02:00", // just after the switch
+		"2024-03-31T23:59:00+02:00", // last minute of the short day
+		"2024-03-31T22:00:00Z",      // 00:00 CEST on April 1st
+	} {
+		if _, err := st.CreateDiaper(childID, "wet", ts, ""); err != nil {
+			t.Fatalf("CreateDiaper(%s): %v", ts, err)
+		}
+	}
+
+	logs, err := st.GetDiaperLogs(childID, "2024-03-31")
+	if err != nil {
+		t.Fatalf("GetDiaperLogs: %v", err)
+	}
+	if len(logs) != 3 {
+		t.Errorf("GetDiaperLogs returned %d logs for 2024-03-31, want 3", len(logs))
+	}
+
+	summary, err := st.GetDaySummary(childID, "2024-03-31")
+	if err != nil {
+		t.Fatalf("GetDaySummary: %v", err)
+	}
+	if summary.DiaperCount != 3 {
+		t.Errorf("DiaperCount = %d, want 3", summary.DiaperCount)
+	}
+
+	days, err := st.GetAnalytics(childID, "2024-03-30", "2024-04-01")
+	if err != nil {
+		t.Fatalf("GetAnalytics: %v", err)
+	}
+	want := map[string]int{"2024-03-30": 0, "2024-03-31": 3, "2024-04-01": 1}
+	for _, d := range days {
+		if d.DiaperCount != want[d.Date] {
+			t.Errorf("%s DiaperCount = %d, want %d", d.Date, d.DiaperCount, want[d.Date])
+		}
+	}
+}
+
+func TestGetLogs_OrderedByInstantAcrossOffsets(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	// Logged before and after a move from Asia/Ho_Chi_Minh to UTC: the later
+	// change sorts first as a string.
+	earlier, _ := st.CreateDiaper(childID, "wet", "2024-01-15T09:00:00+07:00", "") // 02:00Z
+	later, _ := st.CreateDiaper(childID, "wet", "2024-01-15T03:00:00Z", "")
+
+	logs, err := st.GetDiaperLogs(childID, "")
+	if err != nil {
+		t.Fatalf("GetDiaperLogs: %v", err)
+	}
+	if len(logs) != 2 || logs[0].ID != later.ID || logs[1].ID != earlier.ID {
+		t.Errorf("GetDiaperLogs is not newest first: %+v", logs)
+	}
+}
+
+func TestSetNightWindow(t *testing.T) {
+	st := newTestStore(t)
+
+	settings, err := st.GetSettings()
+	if err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if settings.NightStart != store.DefaultNightStart || settings.NightEnd != store.DefaultNightEnd {
+		t.Errorf("night = %s–%s, want the defaults", settings.NightStart, settings.NightEnd)
+	}
+
+	if err := st.SetNightWindow("20:30", "6:00"); err != nil {
+		t.Fatalf("SetNightWindow: %v", err)
+	}
+	settings, _ = st.GetSettings()
+	if settings.NightStart != "20:30" || settings.NightEnd != "06:00" {
+		t.Errorf("night = %s–%s, want 20:30–06:00", settings.NightStart, settings.NightEnd)
+	}
+
+	for _, tc := range [][2]string{{"25:00", ""}, {"", "dawn"}, {"06:00", ""}} {
+		if err := st.SetNightWindow(tc[0], tc[1]); !errors.Is(err, store.ErrInvalidNightWindow) {
+			t.Errorf("SetNightWindow(%q, %q) = %v, want ErrInvalidNightWindow", tc[0], tc[1], err)
+		}
+	}
+}
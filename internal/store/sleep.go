@@ -4,12 +4,17 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"time"
 
 	"baby-care/internal/model"
 	"github.com/google/uuid"
 )
 
+// ErrEndBeforeStart is returned when an update would leave a timed entry
+// ending before it started.
+var ErrEndBeforeStart = errors.New("end time is before start time")
+
+const sleepColumns = `id, child_id, start_time, end_time, duration_minutes, notes, created_at, COALESCE(created_by,''), deleted_at`
+
 // StoppedFeeding is returned by CreateSleep when an active feeding was auto-stopped.
 type StoppedFeeding struct {
 	ID              string `json:"id"`
@@ -18,126 +23,193 @@ type StoppedFeeding struct {
 }
 
 func (s *Store) CreateSleep(childID, startTime, notes string) (*model.SleepLog, *StoppedFeeding, error) {
-	now := nowHCMC()
+	return s.CreateSleepSpan(childID, startTime, "", notes)
+}
+
+// CreateSleepSpan creates a sleep that is already finished when endTime is
+// set, as when logging one after the fact. Only an ongoing sleep auto-stops
+// the active feeding.
+func (s *Store) CreateSleepSpan(childID, startTime, endTime, notes string) (*model.SleepLog, *StoppedFeeding, error) {
+	now := s.nowLocal()
 	if startTime == "" {
 		startTime = now
 	}
-
-	// Auto-stop any active breast feeding session when sleep starts.
+	sp := span{start: startTime, end: endTime, notes: notes}
+	if err := sp.check(); err != nil {
+		return nil, nil, err
+	}
+	var log *model.SleepLog
 	var stopped *StoppedFeeding
-	if activeFeeding, err := s.GetActiveFeeding(childID); err == nil {
-		updated, err := s.UpdateFeeding(activeFeeding.ID, "", "", nowHCMC(), activeFeeding.Notes, activeFeeding.QuantityML)
-		if err == nil && updated.DurationMinutes != nil {
-			stopped = &StoppedFeeding{ID: updated.ID, FeedType: updated.FeedType, DurationMinutes: *updated.DurationMinutes}
+	err := s.inTx(func(tx *Store) error {
+		if err := tx.resolveOverlaps("sleep", childID, "", &sp); err != nil {
+			return err
 		}
-	}
 
-	log := &model.SleepLog{
-		ID:        uuid.NewString(),
-		ChildID:   childID,
-		StartTime: startTime,
-		Notes:     notes,
-		CreatedAt: now,
-	}
-	_, err := s.db.Exec(
-		`INSERT INTO sleep_logs (id, child_id, start_time, notes, created_at) VALUES (?,?,?,?,?)`,
-		log.ID, log.ChildID, log.StartTime, log.Notes, log.CreatedAt,
-	)
+		// Auto-stop any active breast feeding session when sleep starts.
+		if activeFeeding, err := tx.GetActiveFeeding(childID); err == nil && endTime == "" {
+			updated, err := tx.UpdateFeeding(activeFeeding.ID, "", "", "", tx.nowLocal(), activeFeeding.Notes, activeFeeding.QuantityML)
+			if err == nil && updated.DurationMinutes != nil {
+				stopped = &StoppedFeeding{ID: updated.ID, FeedType: updated.FeedType, DurationMinutes: *updated.DurationMinutes}
+			}
+		}
+
+		log = &model.SleepLog{
+			ID:              uuid.NewString(),
+			ChildID:         childID,
+			StartTime:       sp.start,
+			DurationMinutes: sp.durationMinutes(),
+			Notes:           sp.notes,
+			CreatedAt:       now,
+			CreatedBy:       tx.actor,
+		}
+		if sp.end != "" {
+			log.EndTime = &sp.end
+		}
+		if _, err := tx.db.Exec(
+			`INSERT INTO sleep_logs (id, child_id, start_time, end_time, duration_minutes, notes, created_at, created_by) VALUES (?,?,?,?,?,?,?,?)`,
+			log.ID, log.ChildID, log.StartTime, sp.endValue(), log.DurationMinutes, log.Notes, log.CreatedAt, tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert sleep: %w", err)
+		}
+		return tx.audit(AuditCreate, "sleep", log.ID, nil, log)
+	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("insert sleep: %w", err)
+		return nil, nil, err
 	}
 	return log, stopped, nil
 }
 
 func (s *Store) GetSleepLogs(childID, date string) ([]*model.SleepLog, error) {
-	query := `SELECT id, child_id, start_time, end_time, duration_minutes, notes, created_at FROM sleep_logs WHERE child_id=?`
+	query := `SELECT ` + sleepColumns + ` FROM sleep_logs WHERE child_id=? AND deleted_at IS NULL`
 	args := []any{childID}
 	if date != "" {
-		query += ` AND substr(start_time,1,10)=?`
-		args = append(args, date)
+		start, end, err := s.dayBounds(date)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND unixepoch(start_time) >= ? AND unixepoch(start_time) < ?`
+		args = append(args, start, end)
 	}
-	query += ` ORDER BY start_time DESC`
+	query += ` ORDER BY unixepoch(start_time) DESC`
 
 	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query sleep: %w", err)
 	}
 	defer rows.Close()
-	return scanSleepRows(rows)
+	logs, err := scanSleepRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillSleeps(logs...); err != nil {
+		return nil, err
+	}
+	return logs, nil
 }
 
 func (s *Store) GetActiveSleep(childID string) (*model.SleepLog, error) {
 	row := s.db.QueryRow(
-		`SELECT id, child_id, start_time, end_time, duration_minutes, notes, created_at FROM sleep_logs WHERE child_id=? AND end_time IS NULL ORDER BY start_time DESC LIMIT 1`,
+		`SELECT ` + sleepColumns + ` FROM sleep_logs WHERE child_id=? AND end_time IS NULL AND deleted_at IS NULL ORDER BY unixepoch(start_time) DESC LIMIT 1`,
 		childID,
 	)
-	return scanSleepRow(row)
-}
-
-func (s *Store) UpdateSleep(id, startTime, endTime, notes string) (*model.SleepLog, error) {
-	existing, err := getSleepByID(s, id)
+	log, err := scanSleepRow(row)
 	if err != nil {
 		return nil, err
 	}
+	return log, s.fillSleeps(log)
+}
 
-	effectiveStart := existing.StartTime
-	if startTime != "" {
-		effectiveStart = startTime
-	}
+func (s *Store) UpdateSleep(id, startTime, endTime, notes string) (*model.SleepLog, error) {
+	var updated *model.SleepLog
+	err := s.inTx(func(tx *Store) error {
+		existing, err := getSleepByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if existing.DeletedAt != nil {
+			return ErrNotFound
+		}
 
-	var durationMinutes *int
-	if existing.EndTime != nil && *existing.EndTime != "" {
-		effectiveEnd := *existing.EndTime
-		if endTime != "" {
-			effectiveEnd = endTime
+		sp := span{start: existing.StartTime, end: endTime, notes: notes}
+		if startTime != "" {
+			sp.start = startTime
 		}
-		st, e1 := time.Parse(time.RFC3339, effectiveStart)
-		et, e2 := time.Parse(time.RFC3339, effectiveEnd)
-		if e1 == nil && e2 == nil {
-			d := int(et.Sub(st).Minutes())
-			durationMinutes = &d
+		if sp.end == "" && existing.EndTime != nil {
+			sp.end = *existing.EndTime
 		}
-	} else if endTime != "" {
-		st, e1 := time.Parse(time.RFC3339, effectiveStart)
-		et, e2 := time.Parse(time.RFC3339, endTime)
-		if e1 == nil && e2 == nil {
-			d := int(et.Sub(st).Minutes())
-			durationMinutes = &d
+		if err := sp.check(); err != nil {
+			return err
+		}
+		if err := tx.resolveOverlaps("sleep", existing.ChildID, id, &sp); err != nil {
+			return err
+		}
+
+		// Stopping a paused sleep ends its pause.
+		if sp.end != "" {
+			if err := closePauses(tx.db, "sleep", id, sp.end); err != nil {
+				return err
+			}
+		}
+		pauses, err := tx.loadPauses("sleep", id)
+		if err != nil {
+			return err
 		}
-	}
 
-	if endTime != "" {
-		_, err = s.db.Exec(
+		_, err = tx.db.Exec(
 			`UPDATE sleep_logs SET start_time=?, end_time=?, duration_minutes=?, notes=? WHERE id=?`,
-			effectiveStart, endTime, durationMinutes, notes, id,
-		)
-	} else {
-		_, err = s.db.Exec(
-			`UPDATE sleep_logs SET start_time=?, duration_minutes=?, notes=? WHERE id=?`,
-			effectiveStart, durationMinutes, notes, id,
+			sp.start, sp.endValue(), activeMinutes(sp, pauses[id]), sp.notes, id,
 		)
-	}
+		if err != nil {
+			return fmt.Errorf("update sleep: %w", err)
+		}
+		updated, err = getSleepByID(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "sleep", id, existing, updated)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("update sleep: %w", err)
+		return nil, err
 	}
-	return getSleepByID(s, id)
+	return updated, nil
 }
 
+// DeleteSleep moves a sleep log to the trash; see RestoreLog.
 func (s *Store) DeleteSleep(id string) error {
-	_, err := s.db.Exec(`DELETE FROM sleep_logs WHERE id=?`, id)
-	return err
+	return s.trashLog("sleep", id)
 }
 
 func getSleepByID(s *Store, id string) (*model.SleepLog, error) {
 	row := s.db.QueryRow(
-		`SELECT id, child_id, start_time, end_time, duration_minutes, notes, created_at FROM sleep_logs WHERE id=?`, id,
+		`SELECT ` + sleepColumns + ` FROM sleep_logs WHERE id=?`, id,
 	)
-	return scanSleepRow(row)
+	log, err := scanSleepRow(row)
+	if err != nil {
+		return nil, err
+	}
+	return log, s.fillSleeps(log)
+}
+
+// fillSleeps fills in the pauses of sleeps.
+func (s *Store) fillSleeps(logs ...*model.SleepLog) error {
+	ids := make([]string, len(logs))
+	for i, l := range logs {
+		ids[i] = l.ID
+	}
+	pauses, err := s.loadPauses("sleep", ids...)
+	if err != nil {
+		return err
+	}
+	for _, l := range logs {
+		l.Pauses = pauses[l.ID]
+		l.PausedAt = pausedAt(l.Pauses)
+	}
+	return nil
 }
 
 func scanSleepRow(row *sql.Row) (*model.SleepLog, error) {
 	var l model.SleepLog
-	err := row.Scan(&l.ID, &l.ChildID, &l.StartTime, &l.EndTime, &l.DurationMinutes, &l.Notes, &l.CreatedAt)
+	err := row.Scan(&l.ID, &l.ChildID, &l.StartTime, &l.EndTime, &l.DurationMinutes, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrNotFound
 	}
@@ -151,7 +223,7 @@ func scanSleepRows(rows *sql.Rows) ([]*model.SleepLog, error) {
 	var logs []*model.SleepLog
 	for rows.Next() {
 		var l model.SleepLog
-		if err := rows.Scan(&l.ID, &l.ChildID, &l.StartTime, &l.EndTime, &l.DurationMinutes, &l.Notes, &l.CreatedAt); err != nil {
+		if err := rows.Scan(&l.ID, &l.ChildID, &l.StartTime, &l.EndTime, &l.DurationMinutes, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt); err != nil {
 			return nil, err
 		}
 		logs = append(logs, &l)
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"testing"
 
+	"baby-care/internal/model"
 	"baby-care/internal/store"
 )
 
@@ -55,7 +56,8 @@ func TestGetSleepLogs_All(t *testing.T) {
 	st := newTestStore(t)
 	childID := mustCreateChild(t, st)
 
-	st.CreateSleep(childID, sleep1Start, "")
+	first, _, _ := st.CreateSleep(childID, sleep1Start, "")
+	st.UpdateSleep(first.ID, "", sleep1End, "")
 	st.CreateSleep(childID, sleep2Start, "")
 
 	logs, err := st.GetSleepLogs(childID, "")
@@ -197,3 +199,110 @@ func TestCreateSleep_AutoStopsActiveFeeding(t *testing.T) {
 		t.Errorf("expected no active feeding after auto-stop, got %v", err)
 	}
 }
+
+func TestPauseTimer_Sleep(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	sl, _, err := st.CreateSleep(childID, "2024-01-15T13:00:00+07:00", "")
+	if err != nil {
+		t.Fatalf("CreateSleep: %v", err)
+	}
+
+	if _, err := st.ResumeTimer("sleep", sl.ID, ""); !errors.Is(err, store.ErrTimerNotPaused) {
+		t.Errorf("resume before pausing error = %v, want ErrTimerNotPaused", err)
+	}
+	if _, err := st.PauseTimer("sleep", sl.ID, "2024-01-15T12:00:00+07:00"); !errors.Is(err, store.ErrEndBeforeStart) {
+		t.Errorf("pause before the start error = %v, want ErrEndBeforeStart", err)
+	}
+	got, err := st.PauseTimer("sleep", sl.ID, "2024-01-15T13:30:00+07:00")
+	if err != nil {
+		t.Fatalf("PauseTimer: %v", err)
+	}
+	if p := got.(*model.SleepLog).PausedAt; p == nil || *p != "2024-01-15T13:30:00+07:00" {
+		t.Errorf("PausedAt = %v, want 13:30", p)
+	}
+	if _, err := st.PauseTimer("sleep", sl.ID, ""); !errors.Is(err, store.ErrTimerPaused) {
+		t.Errorf("second pause error = %v, want ErrTimerPaused", err)
+	}
+	if _, err := st.ResumeTimer("sleep", sl.ID, "2024-01-15T13:40:00+07:00"); err != nil {
+		t.Fatalf("ResumeTimer: %v", err)
+	}
+
+	// A second pause still open when the sleep ends is closed at the end.
+	st.PauseTimer("sleep", sl.ID, "2024-01-15T14:50:00+07:00")
+	if active, err := st.GetActiveSleep(childID); err != nil || active.PausedAt == nil {
+		t.Errorf("active sleep = %+v, %v; want it paused", active, err)
+	}
+	ended, err := st.UpdateSleep(sl.ID, "", "2024-01-15T15:00:00+07:00", "")
+	if err != nil {
+		t.Fatalf("UpdateSleep: %v", err)
+	}
+	if *ended.DurationMinutes != 100 || ended.PausedAt != nil || len(ended.Pauses) != 2 {
+		t.Errorf("ended = %d min, paused %v, %d pauses; want 100 min, not paused, 2 pauses", *ended.DurationMinutes, ended.PausedAt, len(ended.Pauses))
+	}
+	if _, err := st.PauseTimer("sleep", sl.ID, ""); !errors.Is(err, store.ErrTimerNotRunning) {
+		t.Errorf("pause after the end error = %v, want ErrTimerNotRunning", err)
+	}
+}
+
+func TestGetAnalytics_NightSleep(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	sleep := func(start, end string) *model.SleepLog {
+		t.Helper()
+		sl, _, err := st.CreateSleep(childID, start, "")
+		if err != nil {
+			t.Fatalf("CreateSleep: %v", err)
+		}
+		if end != "" {
+			if sl, err = st.UpdateSleep(sl.ID, "", end, ""); err != nil {
+				t.Fatalf("UpdateSleep: %v", err)
+			}
+		}
+		return sl
+	}
+
+	sleep("2024-02-05T13:00:00+07:00", "2024-02-05T14:00:00+07:00")
+	// Put down at 19:30, a 20-minute waking at 21:00, up again at 23:00.
+	bed := sleep("2024-02-05T19:30:00+07:00", "")
+	if _, err := st.PauseTimer("sleep", bed.ID, "2024-02-05T21:00:00+07:00"); err != nil {
+		t.Fatalf("PauseTimer: %v", err)
+	}
+	if _, err := st.ResumeTimer("sleep", bed.ID, "2024-02-05T21:20:00+07:00"); err != nil {
+		t.Fatalf("ResumeTimer: %v", err)
+	}
+	st.UpdateSleep(bed.ID, "", "2024-02-05T23:00:00+07:00", "")
+	sleep("2024-02-06T00:30:00+07:00", "2024-02-06T05:30:00+07:00")
+	sleep("2024-02-06T06:30:00+07:00", "2024-02-06T07:30:00+07:00")
+	sleep("2024-02-06T09:00:00+07:00", "2024-02-06T10:00:00+07:00")
+
+	days, err := st.GetAnalytics(childID, "2024-02-05", "2024-02-06")
+	if err != nil {
+		t.Fatalf("GetAnalytics: %v", err)
+	}
+	first := days[0]
+	if first.DaySleepMinutes != 60 || first.NightSleepMinutes != 190+300+60 || first.SleepMinutes != 610 || first.SleepCount != 4 || first.NapCount != 1 {
+		t.Errorf("2024-02-05 = %+v, want the whole night that began that evening", first)
+	}
+	want := store.NightStats{
+		Bedtime:               "2024-02-05T19:30:00+07:00",
+		WakeUp:                "2024-02-06T07:30:00+07:00",
+		LongestStretchMinutes: 300,
+		Wakings:               3,
+	}
+	if first.Night == nil || *first.Night != want {
+		t.Errorf("night = %+v, want %+v", first.Night, want)
+	}
+	if second := days[1]; second.DaySleepMinutes != 60 || second.NightSleepMinutes != 0 || second.Night != nil {
+		t.Errorf("2024-02-06 = %+v, want only the morning nap", second)
+	}
+
+	// With the night starting at 20:00 the 19:30 sleep is a nap.
+	if err := st.SetNightWindow("20:00", ""); err != nil {
+		t.Fatalf("SetNightWindow: %v", err)
+	}
+	days, _ = st.GetAnalytics(childID, "2024-02-05", "2024-02-05")
+	if d := days[0]; d.NapCount != 2 || d.Night == nil || d.Night.Bedtime != "2024-02-06T00:30:00+07:00" {
+		t.Errorf("2024-02-05 = %+v, want two naps and bedtime at 00:30", d)
+	}
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"baby-care/internal/catalog"
+)
+
+// SleepCheck compares a day of sleep from GetAnalytics with the
+// recommendations for the child's age that day. Deviations are 0 within the
+// recommended range and otherwise the distance past its nearer end, negative
+// below and positive above. They are left out for days without a logged sleep
+// and ages past the end of the recommendations.
+type SleepCheck struct {
+	Date                  string             `json:"date"`
+	AgeWeeks              int                `json:"age_weeks"`
+	SleepMinutes          int                `json:"sleep_minutes"`
+	NapCount              int                `json:"nap_count"`
+	LongestWakeMinutes    int                `json:"longest_wake_minutes"`
+	Recommended           *catalog.SleepNeed `json:"recommended,omitempty"`
+	SleepDeviationMinutes *int               `json:"sleep_deviation_minutes,omitempty"`
+	NapDeviation          *int               `json:"nap_deviation,omitempty"`
+	WakeDeviationMinutes  *int               `json:"wake_deviation_minutes,omitempty"` // longest wake past the longest recommended; never negative
+}
+
+// GetSleepChecks compares each day in [from, to] with the sleep recommended
+// for the child's age.
+func (s *Store) GetSleepChecks(childID, from, to string) ([]SleepCheck, error) {
+	child, err := s.GetChildByID(childID)
+	if err != nil {
+		return nil, err
+	}
+	dob, err := time.Parse("2006-01-02", child.DateOfBirth)
+	if err != nil {
+		return nil, fmt.Errorf("child date of birth: %w", err)
+	}
+	days, err := s.GetAnalytics(childID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	checks := []SleepCheck{}
+	for _, d := range days {
+		c := SleepCheck{
+			Date:               d.Date,
+			SleepMinutes:       d.SleepMinutes,
+			NapCount:           d.NapCount,
+			LongestWakeMinutes: d.LongestWakeMin,
+		}
+		date, err := time.Parse("2006-01-02", d.Date)
+		if err != nil {
+			return nil, err
+		}
+		c.AgeWeeks = ageWeeks(dob, date)
+		if need, ok := catalog.SleepNeedAt(c.AgeWeeks); ok {
+			c.Recommended = need
+			if d.SleepCount > 0 {
+				sleep, naps := deviation(d.SleepMinutes, need.SleepMinutes), deviation(d.NapCount, need.Naps)
+				wake := max(deviation(d.LongestWakeMin, need.WakeMinutes), 0)
+				c.SleepDeviationMinutes, c.NapDeviation, c.WakeDeviationMinutes = &sleep, &naps, &wake
+			}
+		}
+		checks = append(checks, c)
+	}
+	return checks, nil
+}
+
+// deviation is how far v lies outside the range r: negative below it,
+// positive above it and 0 within it.
+func deviation(v int, r [2]int) int {
+	switch {
+	case v < r[0]:
+		return v - r[0]
+	case v > r[1]:
+		return v - r[1]
+	}
+	return 0
+}
+
+// ageWeeks is the age in whole weeks on a date of a child born on dob.
+func ageWeeks(dob, on time.Time) int {
+	days := int(on.Sub(dob).Hours() / 24)
+	if days < 0 {
+		return -1
+	}
+	return days / 7
+}
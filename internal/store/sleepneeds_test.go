@@ -0,0 +1,81 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetSleepChecks(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st) // five weeks old on 2024-02-05
+
+	for _, sp := range [][2]string{
+		{"2024-02-05T08:00", "2024-02-05T09:00"},
+		{"2024-02-05T10:00", "2024-02-05T11:00"},
+		{"2024-02-05T13:00", "2024-02-05T14:00"},
+		{"2024-02-05T20:00", "2024-02-05T23:00"}, // after six hours awake
+		{"2024-02-06T00:00", "2024-02-06T06:00"}, // the same night
+	} {
+		sl, _, _ := st.CreateSleep(childID, sp[0]+":00+07:00", "")
+		st.UpdateSleep(sl.ID, "", sp[1]+":00+07:00", "")
+	}
+
+	checks, err := st.GetSleepChecks(childID, "2024-02-05", "2024-02-06")
+	if err != nil {
+		t.Fatalf("GetSleepChecks: %v", err)
+	}
+	if len(checks) != 2 {
+		t.Fatalf("got %d days, want 2", len(checks))
+	}
+	c := checks[0]
+	if c.AgeWeeks != 5 || c.SleepMinutes != 720 || c.NapCount != 3 || c.LongestWakeMinutes != 360 {
+		t.Errorf("check = %+v, want 5 weeks old, 720 minutes asleep, 3 naps, 360 minutes awake", c)
+	}
+	if c.Recommended == nil || c.Recommended.WakeMinutes != [2]int{60, 90} {
+		t.Fatalf("Recommended = %+v, want the 4–12 week band", c.Recommended)
+	}
+	// 840–1020 minutes of sleep, 4–6 naps, awake 90 minutes at most.
+	if c.SleepDeviationMinutes == nil || *c.SleepDeviationMinutes != -120 {
+		t.Errorf("SleepDeviationMinutes = %v, want -120", c.SleepDeviationMinutes)
+	}
+	if c.NapDeviation == nil || *c.NapDeviation != -1 {
+		t.Errorf("NapDeviation = %v, want -1", c.NapDeviation)
+	}
+	if c.WakeDeviationMinutes == nil || *c.WakeDeviationMinutes != 270 {
+		t.Errorf("WakeDeviationMinutes = %v, want 270", c.WakeDeviationMinutes)
+	}
+
+	// Nothing logged: no deviations to report.
+	if c := checks[1]; c.Recommended == nil || c.SleepDeviationMinutes != nil || c.NapDeviation != nil {
+		t.Errorf("empty day = %+v, want recommendations only", c)
+	}
+}
+
+func TestGetDaySummary_OverWakeWindow(t *testing.T) {
+	st := newTestStore(t)
+	now := time.Now()
+	child, err := st.CreateChild("Newborn", now.AddDate(0, 0, -30).Format("2006-01-02"), "male", "", "")
+	if err != nil {
+		t.Fatalf("CreateChild: %v", err)
+	}
+	sl, _, _ := st.CreateSleep(child.ID, now.Add(-4*time.Hour).Format(time.RFC3339), "")
+	st.UpdateSleep(sl.ID, "", now.Add(-3*time.Hour).Format(time.RFC3339), "")
+
+	summary, err := st.GetDaySummary(child.ID, now.Format("2006-01-02"))
+	if err != nil {
+		t.Fatalf("GetDaySummary: %v", err)
+	}
+	if summary.WakeWindow == nil || *summary.WakeWindow != [2]int{60, 90} {
+		t.Errorf("WakeWindow = %v, want [60 90]", summary.WakeWindow)
+	}
+	if !summary.OverWakeWindow {
+		t.Error("OverWakeWindow = false after three hours awake")
+	}
+
+	// Asleep again.
+	st.CreateSleep(child.ID, now.Add(-time.Minute).Format(time.RFC3339), "")
+	summary, _ = st.GetDaySummary(child.ID, now.Format("2006-01-02"))
+	if summary.OverWakeWindow {
+		t.Error("OverWakeWindow = true while asleep")
+	}
+}
@@ -0,0 +1,290 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"baby-care/internal/catalog"
+	"baby-care/internal/model"
+	"github.com/google/uuid"
+)
+
+// FoodWaitDays is how long to watch a newly introduced food for a reaction
+// before introducing another new allergen.
+const FoodWaitDays = 3
+
+// reactionRank orders reactions from none to severe.
+var reactionRank = []string{"none", "mild", "moderate", "severe"}
+
+const solidFeedingColumns = `id, child_id, food_id, COALESCE((SELECT name FROM foods WHERE foods.id = food_id),''), fed_at, amount_grams, reaction, reaction_notes, notes, created_at, COALESCE(created_by,''), deleted_at`
+
+// CreateSolidFeeding logs a serving of a food. It returns ErrNotFound when the
+// food does not exist.
+func (s *Store) CreateSolidFeeding(childID, foodID, fedAt, reaction, reactionNotes, notes string, amountGrams *int) (*model.SolidFeedingLog, error) {
+	food, err := s.GetFood(foodID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.nowLocal()
+	if fedAt == "" {
+		fedAt = now
+	}
+	if reaction == "" {
+		reaction = "none"
+	}
+	log := &model.SolidFeedingLog{
+		ID:            uuid.NewString(),
+		ChildID:       childID,
+		FoodID:        food.ID,
+		FoodName:      food.Name,
+		FedAt:         fedAt,
+		AmountGrams:   amountGrams,
+		Reaction:      reaction,
+		ReactionNotes: reactionNotes,
+		Notes:         notes,
+		CreatedAt:     now,
+		CreatedBy:     s.actor,
+	}
+	err = s.inTx(func(tx *Store) error {
+		if _, err := tx.db.Exec(
+			`INSERT INTO solid_feeding_logs (id, child_id, food_id, fed_at, amount_grams, reaction, reaction_notes, notes, created_at, created_by) VALUES (?,?,?,?,?,?,?,?,?,?)`,
+			log.ID, log.ChildID, log.FoodID, log.FedAt, log.AmountGrams, log.Reaction, log.ReactionNotes, log.Notes, log.CreatedAt, tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert solid feeding: %w", err)
+		}
+		return tx.audit(AuditCreate, "solid_feeding", log.ID, nil, log)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+func (s *Store) GetSolidFeedings(childID, date string) ([]*model.SolidFeedingLog, error) {
+	query := `SELECT ` + solidFeedingColumns + ` FROM solid_feeding_logs WHERE child_id=? AND deleted_at IS NULL`
+	args := []any{childID}
+	if date != "" {
+		start, end, err := s.dayBounds(date)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND unixepoch(fed_at) >= ? AND unixepoch(fed_at) < ?`
+		args = append(args, start, end)
+	}
+	query += ` ORDER BY unixepoch(fed_at) DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query solid feeding: %w", err)
+	}
+	defer rows.Close()
+	return scanSolidFeedingRows(rows)
+}
+
+// UpdateSolidFeeding edits a serving; an empty food, time or reaction and a
+// nil amount keep the stored values.
+func (s *Store) UpdateSolidFeeding(id, foodID, fedAt, reaction, reactionNotes, notes string, amountGrams *int) (*model.SolidFeedingLog, error) {
+	var updated *model.SolidFeedingLog
+	err := s.inTx(func(tx *Store) error {
+		existing, err := getSolidFeedingByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if existing.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if foodID == "" {
+			foodID = existing.FoodID
+		} else if _, err := tx.GetFood(foodID); err != nil {
+			return err
+		}
+		if fedAt == "" {
+			fedAt = existing.FedAt
+		}
+		if reaction == "" {
+			reaction = existing.Reaction
+		}
+		if amountGrams == nil {
+			amountGrams = existing.AmountGrams
+		}
+		_, err = tx.db.Exec(
+			`UPDATE solid_feeding_logs SET food_id=?, fed_at=?, amount_grams=?, reaction=?, reaction_notes=?, notes=? WHERE id=?`,
+			foodID, fedAt, amountGrams, reaction, reactionNotes, notes, id,
+		)
+		if err != nil {
+			return fmt.Errorf("update solid feeding: %w", err)
+		}
+		updated, err = getSolidFeedingByID(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "solid_feeding", id, existing, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteSolidFeeding moves a serving to the trash; see RestoreLog.
+func (s *Store) DeleteSolidFeeding(id string) error {
+	return s.trashLog("solid_feeding", id)
+}
+
+// TriedFood is a food the child has eaten, with how it went.
+type TriedFood struct {
+	*model.Food
+	FirstFedAt    string `json:"first_fed_at"`
+	LastFedAt     string `json:"last_fed_at"`
+	Times         int    `json:"times"`
+	WorstReaction string `json:"worst_reaction"`
+}
+
+// AllergenIntro records when an allergen first reached the child.
+type AllergenIntro struct {
+	Allergen   string `json:"allergen"`
+	FirstFedAt string `json:"first_fed_at"`
+	FoodID     string `json:"food_id"` // the food that introduced it
+	Reacted    bool   `json:"reacted"` // any food with it caused a reaction
+}
+
+// FoodWait is a newly introduced food still being watched for a reaction.
+type FoodWait struct {
+	FoodID       string   `json:"food_id"`
+	FoodName     string   `json:"food_name"`
+	Allergens    []string `json:"allergens"`
+	IntroducedAt string   `json:"introduced_at"`
+	WaitUntil    string   `json:"wait_until"`
+}
+
+// FoodIntroductions summarizes the child's progress with solid foods.
+type FoodIntroductions struct {
+	Tried               []*TriedFood     `json:"tried"` // by first feed
+	AllergensIntroduced []*AllergenIntro `json:"allergens_introduced"`
+	AllergensPending    []string         `json:"allergens_pending"`
+	Waiting             []*FoodWait      `json:"waiting"`
+	// NextAllergenAt is when the last wait window closes and the next new
+	// allergen may be introduced; nil when that is now.
+	NextAllergenAt *string `json:"next_allergen_at"`
+}
+
+// GetFoodIntroductions reports the foods the child has tried, which
+// allergens have been introduced, and which new foods are still within
+// their FoodWaitDays wait window as of now.
+func (s *Store) GetFoodIntroductions(childID string, now time.Time) (*FoodIntroductions, error) {
+	rows, err := s.db.Query(
+		`SELECT `+solidFeedingColumns+` FROM solid_feeding_logs WHERE child_id=? AND deleted_at IS NULL ORDER BY unixepoch(fed_at) ASC`,
+		childID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query solid feeding: %w", err)
+	}
+	logs, err := scanSolidFeedingRows(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	intro := &FoodIntroductions{
+		Tried:               []*TriedFood{},
+		AllergensIntroduced: []*AllergenIntro{},
+		AllergensPending:    []string{},
+		Waiting:             []*FoodWait{},
+	}
+	tried := map[string]*TriedFood{}
+	allergens := map[string]*AllergenIntro{}
+	for _, l := range logs {
+		t := tried[l.FoodID]
+		if t == nil {
+			food, err := s.GetFood(l.FoodID)
+			if err != nil {
+				return nil, err
+			}
+			t = &TriedFood{Food: food, FirstFedAt: l.FedAt, WorstReaction: "none"}
+			tried[l.FoodID] = t
+			intro.Tried = append(intro.Tried, t)
+		}
+		t.Times++
+		t.LastFedAt = l.FedAt
+		if slices.Index(reactionRank, l.Reaction) > slices.Index(reactionRank, t.WorstReaction) {
+			t.WorstReaction = l.Reaction
+		}
+		for _, a := range t.Allergens {
+			ai := allergens[a]
+			if ai == nil {
+				ai = &AllergenIntro{Allergen: a, FirstFedAt: l.FedAt, FoodID: l.FoodID}
+				allergens[a] = ai
+				intro.AllergensIntroduced = append(intro.AllergensIntroduced, ai)
+			}
+			if l.Reaction != "none" {
+				ai.Reacted = true
+			}
+		}
+	}
+	for _, a := range catalog.Allergens {
+		if allergens[a] == nil {
+			intro.AllergensPending = append(intro.AllergensPending, a)
+		}
+	}
+
+	var next time.Time
+	for _, t := range intro.Tried {
+		first, err := time.Parse(time.RFC3339, t.FirstFedAt)
+		if err != nil {
+			continue
+		}
+		until := first.AddDate(0, 0, FoodWaitDays)
+		if !until.After(now) {
+			continue
+		}
+		intro.Waiting = append(intro.Waiting, &FoodWait{
+			FoodID:       t.ID,
+			FoodName:     t.Name,
+			Allergens:    t.Allergens,
+			IntroducedAt: t.FirstFedAt,
+			WaitUntil:    until.In(s.Location()).Format(time.RFC3339),
+		})
+		if until.After(next) {
+			next = until
+		}
+	}
+	if !next.IsZero() {
+		at := next.In(s.Location()).Format(time.RFC3339)
+		intro.NextAllergenAt = &at
+	}
+	return intro, nil
+}
+
+func getSolidFeedingByID(s *Store, id string) (*model.SolidFeedingLog, error) {
+	row := s.db.QueryRow(
+		`SELECT `+solidFeedingColumns+` FROM solid_feeding_logs WHERE id=?`, id,
+	)
+	l, err := scanSolidFeeding(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return l, err
+}
+
+func scanSolidFeeding(row rowScanner) (*model.SolidFeedingLog, error) {
+	var l model.SolidFeedingLog
+	if err := row.Scan(&l.ID, &l.ChildID, &l.FoodID, &l.FoodName, &l.FedAt, &l.AmountGrams, &l.Reaction, &l.ReactionNotes, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func scanSolidFeedingRows(rows *sql.Rows) ([]*model.SolidFeedingLog, error) {
+	var logs []*model.SolidFeedingLog
+	for rows.Next() {
+		l, err := scanSolidFeeding(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, rows.Err()
+}
@@ -0,0 +1,66 @@
+package store_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGetFoodIntroductions(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	for _, f := range []struct {
+		food, at, reaction string
+	}{
+		{"rice-porridge", "2024-07-01T11:00:00+07:00", ""},
+		{"egg", "2024-07-05T11:00:00+07:00", "mild"},
+		{"egg", "2024-07-06T11:00:00+07:00", ""},
+		{"tofu", "2024-07-09T11:00:00+07:00", ""},
+	} {
+		if _, err := st.CreateSolidFeeding(childID, f.food, f.at, f.reaction, "", "", intPtr(20)); err != nil {
+			t.Fatalf("CreateSolidFeeding %s: %v", f.food, err)
+		}
+	}
+
+	now, _ := time.Parse(time.RFC3339, "2024-07-10T08:00:00+07:00")
+	intro, err := st.GetFoodIntroductions(childID, now)
+	if err != nil {
+		t.Fatalf("GetFoodIntroductions: %v", err)
+	}
+	if len(intro.Tried) != 3 || intro.Tried[1].ID != "egg" || intro.Tried[1].Times != 2 || intro.Tried[1].WorstReaction != "mild" {
+		t.Errorf("tried = %+v, want rice, egg (twice, mild) and tofu", intro.Tried)
+	}
+	if len(intro.AllergensIntroduced) != 2 || !intro.AllergensIntroduced[0].Reacted || intro.AllergensIntroduced[1].Allergen != "soy" {
+		t.Errorf("introduced = %+v, want egg (reacted) then soy", intro.AllergensIntroduced)
+	}
+	if slices.Contains(intro.AllergensPending, "egg") || !slices.Contains(intro.AllergensPending, "peanut") {
+		t.Errorf("pending = %v", intro.AllergensPending)
+	}
+	// Only tofu, first given a day ago, is still being watched.
+	if len(intro.Waiting) != 1 || intro.Waiting[0].FoodID != "tofu" {
+		t.Errorf("waiting = %+v, want tofu", intro.Waiting)
+	}
+	if intro.NextAllergenAt == nil || *intro.NextAllergenAt != "2024-07-12T11:00:00+07:00" {
+		t.Errorf("NextAllergenAt = %v, want three days after the tofu", intro.NextAllergenAt)
+	}
+}
+
+func TestSolidFeeds_CountAsFeedings(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	st.CreateFeeding(childID, "breast_left", "2024-07-01T08:00:00+07:00", "", nil)
+	st.CreateSolidFeeding(childID, "banana", "2024-07-01T11:00:00+07:00", "", "", "", nil)
+
+	summary, err := st.GetDaySummary(childID, "2024-07-01")
+	if err != nil {
+		t.Fatalf("GetDaySummary: %v", err)
+	}
+	if summary.FeedingCount != 2 || summary.SolidFeedCount != 1 {
+		t.Errorf("summary feedings = %d (%d solid), want 2 (1 solid)", summary.FeedingCount, summary.SolidFeedCount)
+	}
+	stats, _ := st.GetAnalytics(childID, "2024-07-01", "2024-07-01")
+	if stats[0].FeedingCount != 2 || stats[0].SolidFeedCount != 1 || stats[0].BreastFeedCount != 1 {
+		t.Errorf("stats = %+v, want 2 feedings: 1 breast, 1 solid", stats[0])
+	}
+}
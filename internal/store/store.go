@@ -6,113 +6,147 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+	_ "time/tzdata" // IANA zones for minimal container images
 
 	_ "modernc.org/sqlite"
 )
 
-// hcmcTZ is GMT+7 (Ho Chi Minh City). All timestamps are stored in this zone.
-var hcmcTZ = time.FixedZone("Asia/Ho_Chi_Minh", 7*60*60)
+// DefaultTimezone is used until the household configures its own.
+const DefaultTimezone = "Asia/Ho_Chi_Minh"
 
-// nowHCMC returns the current time in GMT+7 formatted as RFC3339.
-func nowHCMC() string {
-	return time.Now().In(hcmcTZ).Format(time.RFC3339)
+// nowLocal returns the current time in the household timezone formatted as RFC3339.
+func (s *Store) nowLocal() string {
+	return time.Now().In(s.Location()).Format(time.RFC3339)
 }
 
-// todayHCMC returns the current date (YYYY-MM-DD) in GMT+7.
-func todayHCMC() string {
-	return time.Now().In(hcmcTZ).Format("2006-01-02")
+// todayLocal returns the current date (YYYY-MM-DD) in the household timezone.
+func (s *Store) todayLocal() string {
+	return time.Now().In(s.Location()).Format("2006-01-02")
 }
 
 type Store struct {
-	db *sql.DB
+	pool *sql.DB
+	// db is the pool, or the transaction of a view made by inTx.
+	db   conn
+	tz   *timezone
+	path string
+
+	// actor is the user on whose behalf mutations are made; see WithActor.
+	actor string
+	// resolve is how overlapping timed entries are resolved; see WithResolve.
+	resolve string
+}
+
+// conn is satisfied by both *sql.DB and *sql.Tx.
+type conn interface {
+	execer
+	querier
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// execer and querier are satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+type querier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// WithActor returns a view of the store that records userID as the creator
+// of new log entries. The view shares the connection and settings.
+func (s *Store) WithActor(userID string) *Store {
+	c := *s
+	c.actor = userID
+	return &c
 }
 
+// inTx runs fn on a view of the store whose queries all go through one
+// transaction, committed if fn returns nil and rolled back otherwise. Within
+// a view fn runs in the transaction already open.
+func (s *Store) inTx(fn func(tx *Store) error) error {
+	if _, ok := s.db.(*sql.Tx); ok {
+		return fn(s)
+	}
+	tx, err := s.pool.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+	v := *s
+	v.db = tx
+	if err := fn(&v); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// actorID returns the acting user for created_by columns, or NULL.
+func (s *Store) actorID() any {
+	if s.actor == "" {
+		return nil
+	}
+	return s.actor
+}
+
+// Open connects to the database at dbPath and applies any pending migrations.
 func Open(dbPath string) (*Store, error) {
+	s, err := Connect(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.Migrate(); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+	if err := s.loadTimezone(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	if err := s.syncFoods(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Connect opens the database at dbPath without running migrations. It is used
+// by the migrate subcommand to inspect the schema before changing it.
+func Connect(dbPath string) (*Store, error) {
 	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
 		return nil, fmt.Errorf("create db dir: %w", err)
 	}
 
-	db, err := sql.Open("sqlite", dbPath+"?_pragma=journal_mode(WAL)&_pragma=foreign_keys(on)")
+	// Transactions take the write lock when they begin, so that what they
+	// read stays true until they commit, and writers wait for each other
+	// rather than failing with SQLITE_BUSY.
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=journal_mode(WAL)&_pragma=foreign_keys(on)&_pragma=busy_timeout(5000)&_txlock=immediate")
 	if err != nil {
 		return nil, fmt.Errorf("open db: %w", err)
 	}
 
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("ping db: %w", err)
 	}
 
-	s := &Store{db: db}
-	if err := s.migrate(); err != nil {
-		return nil, fmt.Errorf("migrate: %w", err)
-	}
-
-	return s, nil
+	return &Store{pool: db, db: db, tz: newTimezone(), path: dbPath}, nil
 }
 
-func (s *Store) Close() error {
-	return s.db.Close()
+// AttachmentDir is where uploaded files are kept by default: an
+// "attachments" directory next to the database file.
+func (s *Store) AttachmentDir() string {
+	return filepath.Join(filepath.Dir(s.path), "attachments")
 }
 
-func (s *Store) migrate() error {
-	stmts := []string{
-		`CREATE TABLE IF NOT EXISTS children (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			date_of_birth TEXT NOT NULL,
-			gender TEXT NOT NULL,
-			photo_url TEXT DEFAULT '',
-			notes TEXT DEFAULT '',
-			created_at TEXT NOT NULL,
-			updated_at TEXT NOT NULL
-		)`,
-		`CREATE TABLE IF NOT EXISTS sleep_logs (
-			id TEXT PRIMARY KEY,
-			child_id TEXT NOT NULL REFERENCES children(id),
-			start_time TEXT NOT NULL,
-			end_time TEXT,
-			duration_minutes INTEGER,
-			notes TEXT DEFAULT '',
-			created_at TEXT NOT NULL
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_sleep_child_start ON sleep_logs(child_id, start_time)`,
-		`CREATE TABLE IF NOT EXISTS feeding_logs (
-			id TEXT PRIMARY KEY,
-			child_id TEXT NOT NULL REFERENCES children(id),
-			feed_type TEXT NOT NULL,
-			start_time TEXT NOT NULL,
-			end_time TEXT,
-			duration_minutes INTEGER,
-			quantity_ml INTEGER,
-			notes TEXT DEFAULT '',
-			created_at TEXT NOT NULL
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_feeding_child_start ON feeding_logs(child_id, start_time)`,
-		`CREATE TABLE IF NOT EXISTS diaper_logs (
-			id TEXT PRIMARY KEY,
-			child_id TEXT NOT NULL REFERENCES children(id),
-			diaper_type TEXT NOT NULL,
-			changed_at TEXT NOT NULL,
-			notes TEXT DEFAULT '',
-			created_at TEXT NOT NULL
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_diaper_child_changed ON diaper_logs(child_id, changed_at)`,
-		`CREATE TABLE IF NOT EXISTS growth_logs (
-			id TEXT PRIMARY KEY,
-			child_id TEXT NOT NULL REFERENCES children(id),
-			measured_on TEXT NOT NULL,
-			weight_grams INTEGER,
-			length_mm INTEGER,
-			head_circumference_mm INTEGER,
-			notes TEXT DEFAULT '',
-			created_at TEXT NOT NULL
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_growth_child_measured ON growth_logs(child_id, measured_on)`,
-	}
-
-	for _, stmt := range stmts {
-		if _, err := s.db.Exec(stmt); err != nil {
-			return fmt.Errorf("exec migration %q: %w", stmt[:40], err)
-		}
-	}
-	return nil
+func (s *Store) Close() error {
+	return s.pool.Close()
 }
@@ -1,47 +1,110 @@
 package store
 
-import "baby-care/internal/model"
+import (
+	"fmt"
+	"time"
+
+	"baby-care/internal/catalog"
+	"baby-care/internal/model"
+)
 
 type DaySummary struct {
-	Date             string             `json:"date"`
-	TotalSleepMin    int                `json:"total_sleep_minutes"`
-	SleepCount       int                `json:"sleep_count"`
-	FeedingCount     int                `json:"feeding_count"`
-	DiaperCount      int                `json:"diaper_count"`
-	LastWeight       *int               `json:"last_weight_grams,omitempty"`
-	LastSleepEndTime *string            `json:"last_sleep_end_time,omitempty"`
+	Date             string            `json:"date"`
+	TotalSleepMin    int               `json:"total_sleep_minutes"` // by sleep day, see GetAnalytics
+	SleepCount       int               `json:"sleep_count"`
+	FeedingCount     int               `json:"feeding_count"` // breast, bottle and solid
+	SolidFeedCount   int               `json:"solid_feed_count"`
+	PumpingCount     int               `json:"pumping_count"`
+	PumpedMLTotal    int               `json:"pumped_ml_total"`
+	DiaperCount      int               `json:"diaper_count"`
+	LastWeight       *int              `json:"last_weight_grams,omitempty"`
+	LastSleepEndTime *string           `json:"last_sleep_end_time,omitempty"`
 	ActiveSleep      *model.SleepLog   `json:"active_sleep,omitempty"`
 	ActiveFeeding    *model.FeedingLog `json:"active_feeding,omitempty"`
+	ActivePumping    *model.PumpingLog `json:"active_pumping,omitempty"`
+	LastDose         *GivenDose        `json:"last_dose,omitempty"`
+	NextBreastSide   string            `json:"next_breast_side,omitempty"`
+	Predictions      *Predictions      `json:"predictions,omitempty"`
+	// WakeWindow is the recommended time awake between sleeps for the child's
+	// age, and OverWakeWindow whether the child has now been awake longer.
+	WakeWindow     *[2]int `json:"wake_window_minutes,omitempty"`
+	OverWakeWindow bool    `json:"over_wake_window"`
 }
 
 func (s *Store) GetDaySummary(childID, date string) (*DaySummary, error) {
 	summary := &DaySummary{Date: date}
+	start, end, err := s.dayBounds(date)
+	if err != nil {
+		return nil, err
+	}
 
-	// Sleep stats
-	row := s.db.QueryRow(
-		`SELECT COUNT(*), COALESCE(SUM(duration_minutes),0) FROM sleep_logs WHERE child_id=? AND substr(start_time,1,10)=? AND end_time IS NOT NULL`,
-		childID, date,
+	// Sleep stats, by sleep day as in GetAnalytics: the night after date is
+	// read in full and the early hours of date belong to the day before.
+	night, err := s.nightWindow()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(
+		`SELECT start_time, COALESCE(duration_minutes,0) FROM sleep_logs WHERE child_id=? AND unixepoch(start_time) >= ? AND unixepoch(start_time) < ? AND end_time IS NOT NULL AND deleted_at IS NULL`,
+		childID, start, end+24*60*60,
 	)
-	row.Scan(&summary.SleepCount, &summary.TotalSleepMin)
+	if err != nil {
+		return nil, fmt.Errorf("summary sleep: %w", err)
+	}
+	for rows.Next() {
+		var startTime string
+		var mins int
+		if err := rows.Scan(&startTime, &mins); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		t, err := time.Parse(time.RFC3339, startTime)
+		if err != nil {
+			continue
+		}
+		if day, _ := night.sleepDay(t.In(s.Location())); day == date {
+			summary.SleepCount++
+			summary.TotalSleepMin += mins
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	// Feeding count
-	row = s.db.QueryRow(
-		`SELECT COUNT(*) FROM feeding_logs WHERE child_id=? AND substr(start_time,1,10)=?`,
-		childID, date,
+	row := s.db.QueryRow(
+		`SELECT COUNT(*) FROM feeding_logs WHERE child_id=? AND unixepoch(start_time) >= ? AND unixepoch(start_time) < ? AND deleted_at IS NULL`,
+		childID, start, end,
 	)
 	row.Scan(&summary.FeedingCount)
 
+	// Solid feeds
+	row = s.db.QueryRow(
+		`SELECT COUNT(*) FROM solid_feeding_logs WHERE child_id=? AND unixepoch(fed_at) >= ? AND unixepoch(fed_at) < ? AND deleted_at IS NULL`,
+		childID, start, end,
+	)
+	row.Scan(&summary.SolidFeedCount)
+	summary.FeedingCount += summary.SolidFeedCount
+
+	// Pumping totals
+	row = s.db.QueryRow(
+		`SELECT COUNT(*), COALESCE(SUM(COALESCE(left_ml,0) + COALESCE(right_ml,0)),0) FROM pumping_logs WHERE child_id=? AND unixepoch(start_time) >= ? AND unixepoch(start_time) < ? AND deleted_at IS NULL`,
+		childID, start, end,
+	)
+	row.Scan(&summary.PumpingCount, &summary.PumpedMLTotal)
+
 	// Diaper count
 	row = s.db.QueryRow(
-		`SELECT COUNT(*) FROM diaper_logs WHERE child_id=? AND substr(changed_at,1,10)=?`,
-		childID, date,
+		`SELECT COUNT(*) FROM diaper_logs WHERE child_id=? AND unixepoch(changed_at) >= ? AND unixepoch(changed_at) < ? AND deleted_at IS NULL`,
+		childID, start, end,
 	)
 	row.Scan(&summary.DiaperCount)
 
 	// Last weight
 	var w int
-	err := s.db.QueryRow(
-		`SELECT weight_grams FROM growth_logs WHERE child_id=? AND weight_grams IS NOT NULL ORDER BY measured_on DESC LIMIT 1`,
+	err = s.db.QueryRow(
+		`SELECT weight_grams FROM growth_logs WHERE child_id=? AND weight_grams IS NOT NULL AND deleted_at IS NULL ORDER BY measured_on DESC LIMIT 1`,
 		childID,
 	).Scan(&w)
 	if err == nil {
@@ -51,13 +114,28 @@ func (s *Store) GetDaySummary(childID, date string) (*DaySummary, error) {
 	// Last sleep end time (for awake-time counter)
 	var lastSleepEnd string
 	err = s.db.QueryRow(
-		`SELECT end_time FROM sleep_logs WHERE child_id=? AND end_time IS NOT NULL ORDER BY end_time DESC LIMIT 1`,
+		`SELECT end_time FROM sleep_logs WHERE child_id=? AND end_time IS NOT NULL AND deleted_at IS NULL ORDER BY unixepoch(end_time) DESC LIMIT 1`,
 		childID,
 	).Scan(&lastSleepEnd)
 	if err == nil {
 		summary.LastSleepEndTime = &lastSleepEnd
 	}
 
+	// Last medication dose, whatever the day
+	if dose, err := s.lastDose(childID); err == nil {
+		summary.LastDose = dose
+	}
+
+	// Side to start the next breast feed on
+	if next, err := s.GetNextBreastSide(childID); err == nil {
+		summary.NextBreastSide = next.Side
+	}
+
+	// Next feed and nap, as of now
+	if p, err := s.GetPredictions(childID, time.Now()); err == nil {
+		summary.Predictions = p
+	}
+
 	// Active timers
 	activeSleep, err := s.GetActiveSleep(childID)
 	if err == nil {
@@ -67,6 +145,44 @@ func (s *Store) GetDaySummary(childID, date string) (*DaySummary, error) {
 	if err == nil {
 		summary.ActiveFeeding = activeFeeding
 	}
+	activePumping, err := s.GetActivePumping(childID)
+	if err == nil {
+		summary.ActivePumping = activePumping
+	}
+
+	// Awake longer than recommended, as of now
+	if err := s.checkWakeWindow(childID, summary, time.Now()); err != nil {
+		return nil, err
+	}
 
 	return summary, nil
 }
+
+// checkWakeWindow fills in the recommended wake window and whether the child,
+// awake since the last sleep ended, has gone past it by now. There is none
+// without a usable date of birth.
+func (s *Store) checkWakeWindow(childID string, summary *DaySummary, now time.Time) error {
+	child, err := s.GetChildByID(childID)
+	if err != nil {
+		return err
+	}
+	// Children added before dates of birth were validated may hold any text;
+	// they get no recommendation.
+	dob, err := time.Parse("2006-01-02", child.DateOfBirth)
+	if err != nil {
+		return nil
+	}
+	need, ok := catalog.SleepNeedAt(ageWeeks(dob, now))
+	if !ok {
+		return nil
+	}
+	window := need.WakeMinutes
+	summary.WakeWindow = &window
+	if summary.ActiveSleep != nil || summary.LastSleepEndTime == nil {
+		return nil
+	}
+	if end, err := time.Parse(time.RFC3339, *summary.LastSleepEndTime); err == nil {
+		summary.OverWakeWindow = now.Sub(end) > time.Duration(window[1])*time.Minute
+	}
+	return nil
+}
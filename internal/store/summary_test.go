@@ -84,6 +84,33 @@ func TestGetDaySummary_ExcludesOtherDates(t *testing.T) {
 	}
 }
 
+func TestGetDaySummary_SleepBySleepDay(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	// The early hours of the 15th finish the night of the 14th; the night
+	// starting on the evening of the 15th counts in full.
+	mustCreateSleep(t, st, childID, "2024-01-15T03:00:00+07:00", "2024-01-15T05:00:00+07:00", "")
+	mustCreateSleep(t, st, childID, "2024-01-15T13:00:00+07:00", "2024-01-15T14:00:00+07:00", "")
+	mustCreateSleep(t, st, childID, "2024-01-15T20:00:00+07:00", "2024-01-16T02:00:00+07:00", "")
+	mustCreateSleep(t, st, childID, "2024-01-16T02:30:00+07:00", "2024-01-16T06:00:00+07:00", "")
+
+	summary, err := st.GetDaySummary(childID, "2024-01-15")
+	if err != nil {
+		t.Fatalf("GetDaySummary: %v", err)
+	}
+	if summary.SleepCount != 3 || summary.TotalSleepMin != 60+360+210 {
+		t.Errorf("summary sleep = %d, %d min; want 3, 630", summary.SleepCount, summary.TotalSleepMin)
+	}
+	stats, err := st.GetAnalytics(childID, "2024-01-15", "2024-01-15")
+	if err != nil {
+		t.Fatalf("GetAnalytics: %v", err)
+	}
+	if len(stats) != 1 || stats[0].SleepCount != summary.SleepCount || stats[0].SleepMinutes != summary.TotalSleepMin {
+		t.Errorf("analytics = %+v, want the same sleep as the summary", stats)
+	}
+}
+
 func TestGetDaySummary_LastWeight(t *testing.T) {
 	st := newTestStore(t)
 	childID := mustCreateChild(t, st)
@@ -124,3 +151,19 @@ func TestGetDaySummary_ActiveTimers(t *testing.T) {
 		t.Errorf("SleepCount = %d, want 0 (in-progress not counted)", summary.SleepCount)
 	}
 }
+
+func TestGetDaySummary_UnparseableBirthDate(t *testing.T) {
+	st := newTestStore(t)
+	child, err := st.CreateChild("Test Baby", "01/01/2024", "female", "", "")
+	if err != nil {
+		t.Fatalf("CreateChild: %v", err)
+	}
+
+	summary, err := st.GetDaySummary(child.ID, "2024-01-15")
+	if err != nil {
+		t.Fatalf("GetDaySummary: %v", err)
+	}
+	if summary.WakeWindow != nil {
+		t.Errorf("WakeWindow = %v, want none without a date of birth", *summary.WakeWindow)
+	}
+}
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"baby-care/internal/model"
+	"github.com/google/uuid"
+)
+
+const symptomColumns = `id, child_id, observed_at, symptoms, severity, notes, created_at, COALESCE(created_by,''), deleted_at`
+
+func (s *Store) CreateSymptom(childID, observedAt, severity, notes string, symptoms []string) (*model.SymptomLog, error) {
+	now := s.nowLocal()
+	if observedAt == "" {
+		observedAt = now
+	}
+	log := &model.SymptomLog{
+		ID:         uuid.NewString(),
+		ChildID:    childID,
+		ObservedAt: observedAt,
+		Symptoms:   symptoms,
+		Severity:   severity,
+		Notes:      notes,
+		CreatedAt:  now,
+		CreatedBy:  s.actor,
+	}
+	err := s.inTx(func(tx *Store) error {
+		if _, err := tx.db.Exec(
+			`INSERT INTO symptom_logs (id, child_id, observed_at, symptoms, severity, notes, created_at, created_by) VALUES (?,?,?,?,?,?,?,?)`,
+			log.ID, log.ChildID, log.ObservedAt, strings.Join(log.Symptoms, " "), log.Severity, log.Notes, log.CreatedAt, tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert symptom: %w", err)
+		}
+		return tx.audit(AuditCreate, "symptom", log.ID, nil, log)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+func (s *Store) GetSymptomLogs(childID, date string) ([]*model.SymptomLog, error) {
+	query := `SELECT ` + symptomColumns + ` FROM symptom_logs WHERE child_id=? AND deleted_at IS NULL`
+	args := []any{childID}
+	if date != "" {
+		start, end, err := s.dayBounds(date)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND unixepoch(observed_at) >= ? AND unixepoch(observed_at) < ?`
+		args = append(args, start, end)
+	}
+	query += ` ORDER BY unixepoch(observed_at) DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query symptom: %w", err)
+	}
+	defer rows.Close()
+	return scanSymptomRows(rows)
+}
+
+// UpdateSymptom edits an observation; empty strings and nil symptoms keep
+// the stored values.
+func (s *Store) UpdateSymptom(id, observedAt, severity, notes string, symptoms []string) (*model.SymptomLog, error) {
+	var updated *model.SymptomLog
+	err := s.inTx(func(tx *Store) error {
+		existing, err := getSymptomByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if existing.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if observedAt == "" {
+			observedAt = existing.ObservedAt
+		}
+		if severity == "" {
+			severity = existing.Severity
+		}
+		if symptoms == nil {
+			symptoms = existing.Symptoms
+		}
+		_, err = tx.db.Exec(
+			`UPDATE symptom_logs SET observed_at=?, symptoms=?, severity=?, notes=? WHERE id=?`,
+			observedAt, strings.Join(symptoms, " "), severity, notes, id,
+		)
+		if err != nil {
+			return fmt.Errorf("update symptom: %w", err)
+		}
+		updated, err = getSymptomByID(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "symptom", id, existing, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteSymptom moves an observation to the trash; see RestoreLog.
+func (s *Store) DeleteSymptom(id string) error {
+	return s.trashLog("symptom", id)
+}
+
+func getSymptomByID(s *Store, id string) (*model.SymptomLog, error) {
+	row := s.db.QueryRow(
+		`SELECT ` + symptomColumns + ` FROM symptom_logs WHERE id=?`, id,
+	)
+	l, err := scanSymptom(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return l, err
+}
+
+func scanSymptom(row rowScanner) (*model.SymptomLog, error) {
+	var l model.SymptomLog
+	var symptoms string
+	if err := row.Scan(&l.ID, &l.ChildID, &l.ObservedAt, &symptoms, &l.Severity, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt); err != nil {
+		return nil, err
+	}
+	l.Symptoms = strings.Fields(symptoms)
+	return &l, nil
+}
+
+func scanSymptomRows(rows *sql.Rows) ([]*model.SymptomLog, error) {
+	var logs []*model.SymptomLog
+	for rows.Next() {
+		l, err := scanSymptom(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, rows.Err()
+}
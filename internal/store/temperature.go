@@ -0,0 +1,156 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"baby-care/internal/model"
+	"github.com/google/uuid"
+)
+
+// FeverThresholds are the readings, in °C, at or above which a temperature
+// taken by each method counts as a fever. Armpit readings run lower than
+// rectal and ear ones.
+var FeverThresholds = map[string]float64{
+	"axillary": 37.5,
+	"rectal":   38.0,
+	"ear":      38.0,
+}
+
+// isFever reports whether celsius taken by method is a fever.
+func isFever(method string, celsius float64) bool {
+	threshold, ok := FeverThresholds[method]
+	return ok && celsius >= threshold
+}
+
+const temperatureColumns = `id, child_id, taken_at, celsius, method, notes, created_at, COALESCE(created_by,''), deleted_at`
+
+func (s *Store) CreateTemperature(childID, takenAt, method, notes string, celsius float64) (*model.TemperatureLog, error) {
+	now := s.nowLocal()
+	if takenAt == "" {
+		takenAt = now
+	}
+	log := &model.TemperatureLog{
+		ID:        uuid.NewString(),
+		ChildID:   childID,
+		TakenAt:   takenAt,
+		Celsius:   celsius,
+		Method:    method,
+		Fever:     isFever(method, celsius),
+		Notes:     notes,
+		CreatedAt: now,
+		CreatedBy: s.actor,
+	}
+	err := s.inTx(func(tx *Store) error {
+		if _, err := tx.db.Exec(
+			`INSERT INTO temperature_logs (id, child_id, taken_at, celsius, method, notes, created_at, created_by) VALUES (?,?,?,?,?,?,?,?)`,
+			log.ID, log.ChildID, log.TakenAt, log.Celsius, log.Method, log.Notes, log.CreatedAt, tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert temperature: %w", err)
+		}
+		return tx.audit(AuditCreate, "temperature", log.ID, nil, log)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+func (s *Store) GetTemperatureLogs(childID, date string) ([]*model.TemperatureLog, error) {
+	query := `SELECT ` + temperatureColumns + ` FROM temperature_logs WHERE child_id=? AND deleted_at IS NULL`
+	args := []any{childID}
+	if date != "" {
+		start, end, err := s.dayBounds(date)
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND unixepoch(taken_at) >= ? AND unixepoch(taken_at) < ?`
+		args = append(args, start, end)
+	}
+	query += ` ORDER BY unixepoch(taken_at) DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query temperature: %w", err)
+	}
+	defer rows.Close()
+	return scanTemperatureRows(rows)
+}
+
+// UpdateTemperature edits a reading; empty strings and a nil celsius keep the
+// stored values.
+func (s *Store) UpdateTemperature(id, takenAt, method, notes string, celsius *float64) (*model.TemperatureLog, error) {
+	var updated *model.TemperatureLog
+	err := s.inTx(func(tx *Store) error {
+		existing, err := getTemperatureByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if existing.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if takenAt == "" {
+			takenAt = existing.TakenAt
+		}
+		if method == "" {
+			method = existing.Method
+		}
+		if celsius == nil {
+			celsius = &existing.Celsius
+		}
+		_, err = tx.db.Exec(
+			`UPDATE temperature_logs SET taken_at=?, celsius=?, method=?, notes=? WHERE id=?`,
+			takenAt, *celsius, method, notes, id,
+		)
+		if err != nil {
+			return fmt.Errorf("update temperature: %w", err)
+		}
+		updated, err = getTemperatureByID(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "temperature", id, existing, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteTemperature moves a reading to the trash; see RestoreLog.
+func (s *Store) DeleteTemperature(id string) error {
+	return s.trashLog("temperature", id)
+}
+
+func getTemperatureByID(s *Store, id string) (*model.TemperatureLog, error) {
+	row := s.db.QueryRow(
+		`SELECT ` + temperatureColumns + ` FROM temperature_logs WHERE id=?`, id,
+	)
+	l, err := scanTemperature(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return l, err
+}
+
+func scanTemperature(row rowScanner) (*model.TemperatureLog, error) {
+	var l model.TemperatureLog
+	if err := row.Scan(&l.ID, &l.ChildID, &l.TakenAt, &l.Celsius, &l.Method, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt); err != nil {
+		return nil, err
+	}
+	l.Fever = isFever(l.Method, l.Celsius)
+	return &l, nil
+}
+
+func scanTemperatureRows(rows *sql.Rows) ([]*model.TemperatureLog, error) {
+	var logs []*model.TemperatureLog
+	for rows.Next() {
+		l, err := scanTemperature(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, rows.Err()
+}
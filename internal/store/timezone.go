@@ -0,0 +1,133 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrInvalidTimezone is returned when a timezone name is not a known IANA zone.
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// timezone holds the household timezone used for "now", "today" and day
+// bucketing. An override (the --tz flag) wins over the stored setting for the
+// lifetime of the process.
+type timezone struct {
+	mu       sync.RWMutex
+	loc      *time.Location
+	override bool
+}
+
+func newTimezone() *timezone {
+	loc, err := time.LoadLocation(DefaultTimezone)
+	if err != nil {
+		loc = time.FixedZone(DefaultTimezone, 7*60*60)
+	}
+	return &timezone{loc: loc}
+}
+
+// Location returns the effective household timezone.
+func (s *Store) Location() *time.Location {
+	s.tz.mu.RLock()
+	defer s.tz.mu.RUnlock()
+	return s.tz.loc
+}
+
+// SetTimezone stores the household timezone. It takes effect immediately
+// unless the process was started with an override.
+func (s *Store) SetTimezone(name string) error {
+	loc, err := loadLocation(name)
+	if err != nil {
+		return err
+	}
+	if err := s.setSetting(settingTimezone, loc.String()); err != nil {
+		return err
+	}
+	s.tz.mu.Lock()
+	defer s.tz.mu.Unlock()
+	if !s.tz.override {
+		s.tz.loc = loc
+	}
+	return nil
+}
+
+// OverrideTimezone pins the effective timezone for this process without
+// changing the stored household setting.
+func (s *Store) OverrideTimezone(name string) error {
+	loc, err := loadLocation(name)
+	if err != nil {
+		return err
+	}
+	s.tz.mu.Lock()
+	defer s.tz.mu.Unlock()
+	s.tz.loc = loc
+	s.tz.override = true
+	return nil
+}
+
+// loadTimezone applies the stored household timezone, if any.
+func (s *Store) loadTimezone() error {
+	name, err := s.getSetting(settingTimezone)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	loc, err := loadLocation(name)
+	if err != nil {
+		return err
+	}
+	s.tz.mu.Lock()
+	defer s.tz.mu.Unlock()
+	if !s.tz.override {
+		s.tz.loc = loc
+	}
+	return nil
+}
+
+func loadLocation(name string) (*time.Location, error) {
+	// time.LoadLocation maps "" to UTC; a household must name its zone.
+	if name == "" {
+		return nil, fmt.Errorf("%w: empty name", ErrInvalidTimezone)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, name)
+	}
+	return loc, nil
+}
+
+// dayBounds returns the unix-second range [start, end) covering the given
+// local calendar date. Days are 23 or 25 hours long across DST transitions.
+func (s *Store) dayBounds(date string) (int64, int64, error) {
+	return s.rangeBounds(date, date)
+}
+
+// rangeBounds returns the unix-second range [start, end) covering the local
+// calendar dates from..to inclusive.
+func (s *Store) rangeBounds(from, to string) (int64, int64, error) {
+	loc := s.Location()
+	start, err := time.ParseInLocation("2006-01-02", from, loc)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse date %q: %w", from, err)
+	}
+	last, err := time.ParseInLocation("2006-01-02", to, loc)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse date %q: %w", to, err)
+	}
+	return start.Unix(), last.AddDate(0, 0, 1).Unix(), nil
+}
+
+// localDate returns the household-local calendar date of a stored timestamp.
+func (s *Store) localDate(ts string) string {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		if len(ts) >= 10 {
+			return ts[:10]
+		}
+		return ts
+	}
+	return t.In(s.Location()).Format("2006-01-02")
+}
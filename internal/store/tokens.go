@@ -0,0 +1,110 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"baby-care/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// CreateAPIToken stores a token for userID under the hash of its secret.
+func (s *Store) CreateAPIToken(userID, name, tokenHash string, scopes []string) (*model.APIToken, error) {
+	t := &model.APIToken{
+		ID:        uuid.NewString(),
+		Name:      name,
+		Scopes:    scopes,
+		CreatedAt: s.nowLocal(),
+	}
+	err := s.inTx(func(tx *Store) error {
+		if _, err := tx.db.Exec(
+			`INSERT INTO api_tokens (id, user_id, name, token_hash, scopes, created_at) VALUES (?,?,?,?,?,?)`,
+			t.ID, userID, t.Name, tokenHash, strings.Join(scopes, " "), t.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("insert api token: %w", err)
+		}
+		return tx.audit(AuditCreate, "api_token", t.ID, nil, t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ListAPITokens returns the tokens owned by userID, newest first.
+func (s *Store) ListAPITokens(userID string) ([]*model.APIToken, error) {
+	rows, err := s.db.Query(
+		`SELECT id, name, scopes, created_at, last_used_at FROM api_tokens
+		 WHERE user_id=? ORDER BY created_at DESC, rowid DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*model.APIToken
+	for rows.Next() {
+		var t model.APIToken
+		var scopes string
+		if err := rows.Scan(&t.ID, &t.Name, &scopes, &t.CreatedAt, &t.LastUsedAt); err != nil {
+			return nil, err
+		}
+		t.Scopes = strings.Fields(scopes)
+		tokens = append(tokens, &t)
+	}
+	return tokens, rows.Err()
+}
+
+// DeleteAPIToken revokes one of userID's tokens.
+func (s *Store) DeleteAPIToken(userID, id string) error {
+	return s.inTx(func(tx *Store) error {
+		var t model.APIToken
+		var scopes string
+		err := tx.db.QueryRow(
+			`SELECT id, name, scopes, created_at, last_used_at FROM api_tokens WHERE id=? AND user_id=?`, id, userID,
+		).Scan(&t.ID, &t.Name, &scopes, &t.CreatedAt, &t.LastUsedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("lookup api token: %w", err)
+		}
+		t.Scopes = strings.Fields(scopes)
+
+		if _, err := tx.db.Exec(`DELETE FROM api_tokens WHERE id=?`, id); err != nil {
+			return fmt.Errorf("delete api token: %w", err)
+		}
+		return tx.audit(AuditDelete, "api_token", id, &t, nil)
+	})
+}
+
+// GetAPITokenUser returns the owner of a token, restricted to the token's
+// scopes, and records the token as used.
+func (s *Store) GetAPITokenUser(tokenHash string) (*model.User, error) {
+	row := s.db.QueryRow(
+		`SELECT t.id, t.scopes, u.id, u.username, u.role, u.created_at
+		 FROM api_tokens t JOIN users u ON u.id = t.user_id
+		 WHERE t.token_hash=?`,
+		tokenHash,
+	)
+	var u model.User
+	var tokenID, scopes string
+	err := row.Scan(&tokenID, &scopes, &u.ID, &u.Username, &u.Role, &u.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan api token: %w", err)
+	}
+	// Non-nil even when empty, so a scopeless token grants nothing.
+	u.Scopes = append([]string{}, strings.Fields(scopes)...)
+
+	if _, err := s.db.Exec(`UPDATE api_tokens SET last_used_at=? WHERE id=?`, s.nowLocal(), tokenID); err != nil {
+		return nil, fmt.Errorf("touch api token: %w", err)
+	}
+	return &u, nil
+}
@@ -0,0 +1,182 @@
+package store_test
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"baby-care/internal/model"
+	"baby-care/internal/store"
+)
+
+func TestDelete_MovesToTrash(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	sleep, _, _ := st.CreateSleep(childID, "2024-01-15T13:00:00+07:00", "")
+	diaper, _ := st.CreateDiaper(childID, "wet", "2024-01-15T14:00:00+07:00", "")
+	if err := st.DeleteSleep(sleep.ID); err != nil {
+		t.Fatalf("DeleteSleep: %v", err)
+	}
+	if err := st.DeleteDiaper(diaper.ID); err != nil {
+		t.Fatalf("DeleteDiaper: %v", err)
+	}
+
+	if logs, _ := st.GetSleepLogs(childID, ""); len(logs) != 0 {
+		t.Errorf("got %d sleep logs, want deleted one hidden", len(logs))
+	}
+	if _, err := st.GetActiveSleep(childID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("GetActiveSleep error = %v, want ErrNotFound for deleted sleep", err)
+	}
+	if sum, _ := st.GetDaySummary(childID, "2024-01-15"); sum.DiaperCount != 0 {
+		t.Errorf("summary diaper count = %d, want 0", sum.DiaperCount)
+	}
+	if _, err := st.LogChildID("sleep", sleep.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("LogChildID error = %v, want ErrNotFound", err)
+	}
+	if _, err := st.UpdateSleep(sleep.ID, "", "2024-01-15T14:00:00+07:00", ""); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("UpdateSleep on deleted error = %v, want ErrNotFound", err)
+	}
+	if err := st.DeleteSleep(sleep.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("second DeleteSleep error = %v, want ErrNotFound", err)
+	}
+
+	trash, err := st.ListTrash(childID)
+	if err != nil {
+		t.Fatalf("ListTrash: %v", err)
+	}
+	if len(trash) != 2 {
+		t.Fatalf("got %d trash items, want 2", len(trash))
+	}
+	kinds := map[string]string{}
+	for _, item := range trash {
+		kinds[item.Kind] = item.ID
+	}
+	if kinds["sleep"] != sleep.ID || kinds["diaper"] != diaper.ID {
+		t.Errorf("trash = %v, want the sleep and diaper", kinds)
+	}
+
+	if _, err := st.RestoreLog("sleep", sleep.ID); err != nil {
+		t.Fatalf("RestoreLog: %v", err)
+	}
+	if active, err := st.GetActiveSleep(childID); err != nil || active.ID != sleep.ID {
+		t.Errorf("GetActiveSleep after restore = %v, %v", active, err)
+	}
+	if _, err := st.RestoreLog("sleep", sleep.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("restoring a live entry error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	feed, _, _ := st.CreateFeeding(childID, "bottle", "", "", intPtr(90))
+	kept, _, _ := st.CreateFeeding(childID, "bottle", "", "", intPtr(60))
+	if err := st.DeleteFeeding(feed.ID); err != nil {
+		t.Fatalf("DeleteFeeding: %v", err)
+	}
+
+	if n, err := st.PurgeTrash(time.Hour); err != nil || n != 0 {
+		t.Fatalf("PurgeTrash(1h) = %d, %v; want nothing old enough", n, err)
+	}
+	if n, err := st.PurgeTrash(0); err != nil || n != 1 {
+		t.Fatalf("PurgeTrash(0) = %d, %v; want 1", n, err)
+	}
+	if trash, _ := st.ListTrash(childID); len(trash) != 0 {
+		t.Errorf("trash after purge = %d items, want 0", len(trash))
+	}
+	if _, err := st.RestoreLog("feeding", feed.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("RestoreLog after purge error = %v, want ErrNotFound", err)
+	}
+	if logs, _ := st.GetFeedingLogs(childID, ""); len(logs) != 1 || logs[0].ID != kept.ID {
+		t.Errorf("live feedings = %d, want the kept one", len(logs))
+	}
+}
+
+func TestPurgeTrash_PumpingWithBags(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	pump, err := st.CreatePumping(childID, "2024-01-15T06:00:00+07:00", "2024-01-15T06:20:00+07:00", "", intPtr(60), intPtr(50))
+	if err != nil {
+		t.Fatalf("CreatePumping: %v", err)
+	}
+	bag, err := st.CreateMilkBag(childID, pump.ID, "freezer", "", "", nil)
+	if err != nil {
+		t.Fatalf("CreateMilkBag: %v", err)
+	}
+	diaper, _ := st.CreateDiaper(childID, "wet", "2024-01-15T07:00:00+07:00", "")
+	if err := st.DeletePumping(pump.ID); err != nil {
+		t.Fatalf("DeletePumping: %v", err)
+	}
+	if err := st.DeleteDiaper(diaper.ID); err != nil {
+		t.Fatalf("DeleteDiaper: %v", err)
+	}
+
+	if n, err := st.PurgeTrash(0); err != nil || n != 2 {
+		t.Fatalf("PurgeTrash(0) = %d, %v; want 2", n, err)
+	}
+	got, err := st.GetMilkBag(bag.ID)
+	if err != nil {
+		t.Fatalf("GetMilkBag after purge: %v", err)
+	}
+	if got.PumpingID != nil || got.RemainingML != 110 {
+		t.Errorf("bag after purge = pumping %v, %d ml; want no pumping, 110 ml", got.PumpingID, got.RemainingML)
+	}
+}
+
+func TestPurgeTrash_ContinuesPastFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	st, err := store.Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer st.Close()
+	childID := mustCreateChild(t, st)
+	sleep := mustCreateSleep(t, st, childID, "2024-01-15T13:00:00+07:00", "2024-01-15T14:00:00+07:00", "")
+	diaper, _ := st.CreateDiaper(childID, "wet", "2024-01-15T14:00:00+07:00", "")
+	st.DeleteSleep(sleep.ID)
+	st.DeleteDiaper(diaper.ID)
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`CREATE TRIGGER keep_sleep BEFORE DELETE ON sleep_logs BEGIN SELECT RAISE(ABORT, 'kept'); END`); err != nil {
+		t.Fatalf("create trigger: %v", err)
+	}
+
+	n, err := st.PurgeTrash(0)
+	if n != 1 || err == nil {
+		t.Errorf("PurgeTrash(0) = %d, %v; want the diaper purged and the sleep's error", n, err)
+	}
+	if trash, _ := st.ListTrash(childID); len(trash) != 1 || trash[0].ID != sleep.ID {
+		t.Errorf("trash after purge = %+v, want the sleep left for the next run", trash)
+	}
+}
+
+func TestListTrash_FeedingSegments(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+
+	f, _, _ := st.CreateFeeding(childID, "breast_left", feed1Start, "", nil)
+	st.SwitchFeedingSide(f.ID, "2024-01-15T10:12:00+07:00")
+	st.UpdateFeeding(f.ID, "", "", "", feed1End, "", nil)
+	if err := st.DeleteFeeding(f.ID); err != nil {
+		t.Fatalf("DeleteFeeding: %v", err)
+	}
+
+	trash, err := st.ListTrash(childID)
+	if err != nil {
+		t.Fatalf("ListTrash: %v", err)
+	}
+	if len(trash) != 1 {
+		t.Fatalf("got %d trash items, want 1", len(trash))
+	}
+	got := trash[0].Entry.(*model.FeedingLog)
+	if len(got.Segments) != 2 || got.LeftMinutes == nil || *got.LeftMinutes != 12 || got.RightMinutes == nil || *got.RightMinutes != 8 {
+		t.Errorf("trashed feed = %+v, want both sides and their minutes", got)
+	}
+}
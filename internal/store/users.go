@@ -0,0 +1,141 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"baby-care/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ErrUsernameTaken is returned when creating a user whose username exists.
+var ErrUsernameTaken = errors.New("username already taken")
+
+// CreateUser adds a household account. passwordHash must already be hashed.
+func (s *Store) CreateUser(username, passwordHash, role string) (*model.User, error) {
+	u := &model.User{
+		ID:        uuid.NewString(),
+		Username:  strings.ToLower(strings.TrimSpace(username)),
+		Role:      role,
+		CreatedAt: s.nowLocal(),
+	}
+	err := s.inTx(func(tx *Store) error {
+		if err := insertUser(tx.db, u, passwordHash); err != nil {
+			return err
+		}
+		return tx.audit(AuditCreate, "user", u.ID, nil, u)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func insertUser(db execer, u *model.User, passwordHash string) error {
+	_, err := db.Exec(
+		`INSERT INTO users (id, username, password_hash, role, created_at, updated_at) VALUES (?,?,?,?,?,?)`,
+		u.ID, u.Username, passwordHash, u.Role, u.CreatedAt, u.CreatedAt,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return ErrUsernameTaken
+		}
+		return fmt.Errorf("insert user: %w", err)
+	}
+	return nil
+}
+
+// GetUserCredentials returns the user with the given username and their
+// password hash.
+func (s *Store) GetUserCredentials(username string) (*model.User, string, error) {
+	row := s.db.QueryRow(
+		`SELECT id, username, role, created_at, password_hash FROM users WHERE username=?`,
+		strings.ToLower(strings.TrimSpace(username)),
+	)
+	var u model.User
+	var hash string
+	err := row.Scan(&u.ID, &u.Username, &u.Role, &u.CreatedAt, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("scan user: %w", err)
+	}
+	return &u, hash, nil
+}
+
+// ListUsers returns every household account in creation order.
+func (s *Store) ListUsers() ([]*model.User, error) {
+	rows, err := s.db.Query(`SELECT id, username, role, created_at FROM users ORDER BY created_at ASC, rowid ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*model.User
+	for rows.Next() {
+		var u model.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.Role, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, &u)
+	}
+	return users, rows.Err()
+}
+
+// CountUsers returns the number of household accounts.
+func (s *Store) CountUsers() (int, error) {
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count users: %w", err)
+	}
+	return n, nil
+}
+
+// CreateSession stores a login session under the hash of its token.
+func (s *Store) CreateSession(tokenHash, userID string, ttl time.Duration) error {
+	now := time.Now().In(s.Location())
+	_, err := s.db.Exec(
+		`INSERT INTO sessions (token_hash, user_id, created_at, expires_at) VALUES (?,?,?,?)`,
+		tokenHash, userID, now.Format(time.RFC3339), now.Add(ttl).Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("insert session: %w", err)
+	}
+	return nil
+}
+
+// GetSessionUser returns the user owning an unexpired session.
+func (s *Store) GetSessionUser(tokenHash string) (*model.User, error) {
+	row := s.db.QueryRow(
+		`SELECT u.id, u.username, u.role, u.created_at
+		 FROM sessions s JOIN users u ON u.id = s.user_id
+		 WHERE s.token_hash=? AND unixepoch(s.expires_at) > unixepoch('now')`,
+		tokenHash,
+	)
+	var u model.User
+	err := row.Scan(&u.ID, &u.Username, &u.Role, &u.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan session: %w", err)
+	}
+	return &u, nil
+}
+
+// DeleteSession ends a login session.
+func (s *Store) DeleteSession(tokenHash string) error {
+	_, err := s.db.Exec(`DELETE FROM sessions WHERE token_hash=?`, tokenHash)
+	return err
+}
+
+// DeleteExpiredSessions removes sessions past their expiry.
+func (s *Store) DeleteExpiredSessions() error {
+	_, err := s.db.Exec(`DELETE FROM sessions WHERE unixepoch(expires_at) <= unixepoch('now')`)
+	return err
+}
@@ -0,0 +1,108 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"baby-care/internal/store"
+)
+
+func TestCreateUser(t *testing.T) {
+	st := newTestStore(t)
+	u, err := st.CreateUser(" Mom ", "hash", "parent")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if u.Username != "mom" {
+		t.Errorf("Username = %q, want normalised mom", u.Username)
+	}
+	if _, err := st.CreateUser("MOM", "hash", "parent"); !errors.Is(err, store.ErrUsernameTaken) {
+		t.Errorf("duplicate CreateUser error = %v, want ErrUsernameTaken", err)
+	}
+
+	got, hash, err := st.GetUserCredentials("Mom")
+	if err != nil {
+		t.Fatalf("GetUserCredentials: %v", err)
+	}
+	if got.ID != u.ID || hash != "hash" {
+		t.Errorf("GetUserCredentials = %+v, %q", got, hash)
+	}
+	if n, _ := st.CountUsers(); n != 1 {
+		t.Errorf("CountUsers = %d, want 1", n)
+	}
+}
+
+func TestSessions(t *testing.T) {
+	st := newTestStore(t)
+	u, _ := st.CreateUser("mom", "hash", "parent")
+
+	if err := st.CreateSession("live", u.ID, time.Hour); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if err := st.CreateSession("stale", u.ID, -time.Hour); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	got, err := st.GetSessionUser("live")
+	if err != nil {
+		t.Fatalf("GetSessionUser: %v", err)
+	}
+	if got.ID != u.ID {
+		t.Errorf("session user = %q, want %q", got.ID, u.ID)
+	}
+	if _, err := st.GetSessionUser("stale"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("expired session error = %v, want ErrNotFound", err)
+	}
+
+	if err := st.DeleteSession("live"); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	if _, err := st.GetSessionUser("live"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("deleted session error = %v, want ErrNotFound", err)
+	}
+	if err := st.DeleteExpiredSessions(); err != nil {
+		t.Fatalf("DeleteExpiredSessions: %v", err)
+	}
+}
+
+func TestAPITokens(t *testing.T) {
+	st := newTestStore(t)
+	mom, _ := st.CreateUser("mom", "hash", "parent")
+	dad, _ := st.CreateUser("dad", "hash", "parent")
+
+	tok, err := st.CreateAPIToken(mom.ID, "zigbee button", "tok-hash", []string{"diaper:write"})
+	if err != nil {
+		t.Fatalf("CreateAPIToken: %v", err)
+	}
+
+	u, err := st.GetAPITokenUser("tok-hash")
+	if err != nil {
+		t.Fatalf("GetAPITokenUser: %v", err)
+	}
+	if u.ID != mom.ID || len(u.Scopes) != 1 || u.Scopes[0] != "diaper:write" {
+		t.Errorf("token user = %+v, want mom scoped to diaper:write", u)
+	}
+	if _, err := st.GetAPITokenUser("unknown"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("unknown token error = %v, want ErrNotFound", err)
+	}
+
+	tokens, err := st.ListAPITokens(mom.ID)
+	if err != nil {
+		t.Fatalf("ListAPITokens: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].LastUsedAt == nil {
+		t.Fatalf("tokens = %+v, want one with last_used_at", tokens)
+	}
+
+	// Tokens can only be revoked by their owner.
+	if err := st.DeleteAPIToken(dad.ID, tok.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("DeleteAPIToken by other user error = %v, want ErrNotFound", err)
+	}
+	if err := st.DeleteAPIToken(mom.ID, tok.ID); err != nil {
+		t.Fatalf("DeleteAPIToken: %v", err)
+	}
+	if _, err := st.GetAPITokenUser("tok-hash"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("revoked token error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,239 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"baby-care/internal/catalog"
+	"baby-care/internal/model"
+	"github.com/google/uuid"
+)
+
+const vaccinationColumns = `id, child_id, vaccine, dose_number, given_on, clinic, lot_number, notes, created_at, COALESCE(created_by,''), deleted_at`
+
+func (s *Store) CreateVaccination(childID, vaccine string, doseNumber int, givenOn, clinic, lotNumber, notes string) (*model.VaccinationLog, error) {
+	now := s.nowLocal()
+	if givenOn == "" {
+		givenOn = s.todayLocal()
+	}
+	log := &model.VaccinationLog{
+		ID:         uuid.NewString(),
+		ChildID:    childID,
+		Vaccine:    vaccine,
+		DoseNumber: doseNumber,
+		GivenOn:    givenOn,
+		Clinic:     clinic,
+		LotNumber:  lotNumber,
+		Notes:      notes,
+		CreatedAt:  now,
+		CreatedBy:  s.actor,
+	}
+	err := s.inTx(func(tx *Store) error {
+		if _, err := tx.db.Exec(
+			`INSERT INTO vaccination_logs (id, child_id, vaccine, dose_number, given_on, clinic, lot_number, notes, created_at, created_by) VALUES (?,?,?,?,?,?,?,?,?,?)`,
+			log.ID, log.ChildID, log.Vaccine, log.DoseNumber, log.GivenOn, log.Clinic, log.LotNumber, log.Notes, log.CreatedAt, tx.actorID(),
+		); err != nil {
+			return fmt.Errorf("insert vaccination: %w", err)
+		}
+		return tx.audit(AuditCreate, "vaccination", log.ID, nil, log)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// GetVaccinationLogs returns the child's vaccinations, oldest first.
+func (s *Store) GetVaccinationLogs(childID string) ([]*model.VaccinationLog, error) {
+	rows, err := s.db.Query(
+		`SELECT `+vaccinationColumns+` FROM vaccination_logs WHERE child_id=? AND deleted_at IS NULL ORDER BY given_on ASC, vaccine, dose_number`,
+		childID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query vaccination: %w", err)
+	}
+	defer rows.Close()
+	return scanVaccinationRows(rows)
+}
+
+// UpdateVaccination edits a vaccination; an empty vaccine or date and a nil
+// dose number keep the stored values.
+func (s *Store) UpdateVaccination(id, vaccine string, doseNumber *int, givenOn, clinic, lotNumber, notes string) (*model.VaccinationLog, error) {
+	var updated *model.VaccinationLog
+	err := s.inTx(func(tx *Store) error {
+		existing, err := getVaccinationByID(tx, id)
+		if err != nil {
+			return err
+		}
+		if existing.DeletedAt != nil {
+			return ErrNotFound
+		}
+		if vaccine == "" {
+			vaccine = existing.Vaccine
+		}
+		if doseNumber == nil {
+			doseNumber = &existing.DoseNumber
+		}
+		if givenOn == "" {
+			givenOn = existing.GivenOn
+		}
+		_, err = tx.db.Exec(
+			`UPDATE vaccination_logs SET vaccine=?, dose_number=?, given_on=?, clinic=?, lot_number=?, notes=? WHERE id=?`,
+			vaccine, *doseNumber, givenOn, clinic, lotNumber, notes, id,
+		)
+		if err != nil {
+			return fmt.Errorf("update vaccination: %w", err)
+		}
+		updated, err = getVaccinationByID(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.audit(AuditUpdate, "vaccination", id, existing, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteVaccination moves a vaccination to the trash; see RestoreLog.
+func (s *Store) DeleteVaccination(id string) error {
+	return s.trashLog("vaccination", id)
+}
+
+// Statuses of a scheduled dose that has not been given.
+const (
+	DoseOverdue  = "overdue"  // past its target age and grace period
+	DoseDue      = "due"      // past its target age, within the grace period
+	DoseUpcoming = "upcoming" // target age still ahead
+)
+
+// DueDose is a scheduled dose the child has not had yet.
+type DueDose struct {
+	Vaccine    string `json:"vaccine"`
+	Name       string `json:"name"`
+	DoseNumber int    `json:"dose_number"`
+	TargetAge  string `json:"target_age"`
+	TargetDate string `json:"target_date"`
+	DueBy      string `json:"due_by"` // last day before the dose is overdue
+	Status     string `json:"status"`
+}
+
+// VaccinationsDue lists the doses of the household schedule the child still
+// needs, each list ordered by target date.
+type VaccinationsDue struct {
+	ScheduleID   string     `json:"schedule_id"`
+	ScheduleName string     `json:"schedule_name"`
+	Overdue      []*DueDose `json:"overdue"`
+	Due          []*DueDose `json:"due"`
+	Upcoming     []*DueDose `json:"upcoming"`
+}
+
+// GetVaccinationsDue compares the child's vaccinations with the household
+// schedule as of now. Upcoming doses are limited to those whose target date
+// falls within withinDays.
+func (s *Store) GetVaccinationsDue(childID string, now time.Time, withinDays int) (*VaccinationsDue, error) {
+	child, err := s.GetChildByID(childID)
+	if err != nil {
+		return nil, err
+	}
+	dob, err := time.Parse("2006-01-02", child.DateOfBirth)
+	if err != nil {
+		return nil, fmt.Errorf("child date of birth: %w", err)
+	}
+	schedule, err := s.VaccineSchedule()
+	if err != nil {
+		return nil, err
+	}
+	logs, err := s.GetVaccinationLogs(childID)
+	if err != nil {
+		return nil, err
+	}
+	given := map[string]bool{}
+	for _, l := range logs {
+		given[fmt.Sprintf("%s#%d", l.Vaccine, l.DoseNumber)] = true
+	}
+
+	today := now.In(s.Location()).Format("2006-01-02")
+	horizon := now.In(s.Location()).AddDate(0, 0, withinDays).Format("2006-01-02")
+	due := &VaccinationsDue{
+		ScheduleID:   schedule.ID,
+		ScheduleName: schedule.Name,
+		Overdue:      []*DueDose{},
+		Due:          []*DueDose{},
+		Upcoming:     []*DueDose{},
+	}
+	for _, d := range schedule.Doses {
+		if given[fmt.Sprintf("%s#%d", d.Vaccine, d.Dose)] {
+			continue
+		}
+		target := d.Age.From(dob)
+		dd := &DueDose{
+			Vaccine:    d.Vaccine,
+			Name:       d.Name,
+			DoseNumber: d.Dose,
+			TargetAge:  d.Age.String(),
+			TargetDate: target.Format("2006-01-02"),
+			DueBy:      target.AddDate(0, 0, d.Grace()-1).Format("2006-01-02"),
+		}
+		switch {
+		case dd.DueBy < today:
+			dd.Status = DoseOverdue
+			due.Overdue = append(due.Overdue, dd)
+		case dd.TargetDate <= today:
+			dd.Status = DoseDue
+			due.Due = append(due.Due, dd)
+		case dd.TargetDate <= horizon:
+			dd.Status = DoseUpcoming
+			due.Upcoming = append(due.Upcoming, dd)
+		}
+	}
+	return due, nil
+}
+
+// VaccineSchedule returns the household's vaccination schedule.
+func (s *Store) VaccineSchedule() (*catalog.VaccineSchedule, error) {
+	settings, err := s.GetSettings()
+	if err != nil {
+		return nil, err
+	}
+	schedule, ok := catalog.VaccineScheduleByID(settings.VaccinationSchedule)
+	if !ok {
+		// A schedule dropped from a newer build falls back to the default.
+		schedule, _ = catalog.VaccineScheduleByID(catalog.DefaultVaccineSchedule)
+	}
+	return schedule, nil
+}
+
+func getVaccinationByID(s *Store, id string) (*model.VaccinationLog, error) {
+	row := s.db.QueryRow(
+		`SELECT `+vaccinationColumns+` FROM vaccination_logs WHERE id=?`, id,
+	)
+	l, err := scanVaccination(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return l, err
+}
+
+func scanVaccination(row rowScanner) (*model.VaccinationLog, error) {
+	var l model.VaccinationLog
+	if err := row.Scan(&l.ID, &l.ChildID, &l.Vaccine, &l.DoseNumber, &l.GivenOn, &l.Clinic, &l.LotNumber, &l.Notes, &l.CreatedAt, &l.CreatedBy, &l.DeletedAt); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func scanVaccinationRows(rows *sql.Rows) ([]*model.VaccinationLog, error) {
+	var logs []*model.VaccinationLog
+	for rows.Next() {
+		l, err := scanVaccination(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, rows.Err()
+}
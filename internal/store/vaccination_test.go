@@ -0,0 +1,70 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"baby-care/internal/store"
+)
+
+func TestGetVaccinationsDue(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st) // born 2024-01-01
+
+	for _, v := range []struct {
+		vaccine string
+		dose    int
+		on      string
+	}{
+		{"hepb", 1, "2024-01-01"},
+		{"bcg", 1, "2024-01-10"},
+		{"dtp-hepb-hib", 1, "2024-03-01"},
+	} {
+		if _, err := st.CreateVaccination(childID, v.vaccine, v.dose, v.on, "Ward clinic", "", ""); err != nil {
+			t.Fatalf("CreateVaccination: %v", err)
+		}
+	}
+
+	now, _ := time.Parse(time.RFC3339, "2024-04-15T10:00:00+07:00")
+	due, err := st.GetVaccinationsDue(childID, now, 30)
+	if err != nil {
+		t.Fatalf("GetVaccinationsDue: %v", err)
+	}
+	if due.ScheduleID != "vn-epi" {
+		t.Errorf("ScheduleID = %q, want the vn-epi default", due.ScheduleID)
+	}
+	// OPV 1 was due at 2 months and is past its 30-day grace period.
+	if len(due.Overdue) != 1 || due.Overdue[0].Vaccine != "opv" || due.Overdue[0].DueBy != "2024-03-30" {
+		t.Errorf("Overdue = %+v, want OPV 1", due.Overdue)
+	}
+	// The 3-month doses are within their grace period; the 4-month ones
+	// fall within the next 30 days.
+	if len(due.Due) != 2 || due.Due[0].TargetAge != "3 months" {
+		t.Errorf("Due = %+v, want the two 3-month doses", due.Due)
+	}
+	if len(due.Upcoming) != 2 || due.Upcoming[0].TargetDate != "2024-05-01" {
+		t.Errorf("Upcoming = %+v, want the two 4-month doses", due.Upcoming)
+	}
+}
+
+func TestSetVaccinationSchedule(t *testing.T) {
+	st := newTestStore(t)
+	childID := mustCreateChild(t, st)
+	if err := st.SetVaccinationSchedule("nope"); !errors.Is(err, store.ErrUnknownSchedule) {
+		t.Fatalf("SetVaccinationSchedule(nope) error = %v, want ErrUnknownSchedule", err)
+	}
+	if err := st.SetVaccinationSchedule("who"); err != nil {
+		t.Fatalf("SetVaccinationSchedule: %v", err)
+	}
+
+	now, _ := time.Parse(time.RFC3339, "2024-02-13T10:00:00+07:00")
+	due, err := st.GetVaccinationsDue(childID, now, 0)
+	if err != nil {
+		t.Fatalf("GetVaccinationsDue: %v", err)
+	}
+	// Six weeks old: the 6-week doses are due today.
+	if due.ScheduleID != "who" || len(due.Due) != 4 || due.Due[0].TargetAge != "6 weeks" {
+		t.Errorf("due = %+v, want the four 6-week WHO doses", due)
+	}
+}
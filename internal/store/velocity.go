@@ -0,0 +1,188 @@
+package store
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"baby-care/internal/catalog"
+)
+
+// GrowthVelocity is the gain in weight or length since the previous entry
+// with that measurement, compared with the WHO increment standards over the
+// same time.
+type GrowthVelocity struct {
+	Since  string   `json:"since"` // measured_on of the previous measurement
+	Days   int      `json:"days"`
+	Rate   float64  `json:"rate"`
+	Unit   string   `json:"unit"` // g/day or mm/month
+	P5     *float64 `json:"p5,omitempty"`
+	P50    *float64 `json:"p50,omitempty"`
+	P95    *float64 `json:"p95,omitempty"`
+	Status string   `json:"status,omitempty"` // low, normal or high
+}
+
+// GrowthWarning flags a measurement whose trend is worth raising with a
+// doctor.
+type GrowthWarning struct {
+	Code      string `json:"code"`      // percentile_drop, percentile_rise, low_velocity or high_velocity
+	Indicator string `json:"indicator"` // a growth standard or velocity indicator
+	Since     string `json:"since"`     // measured_on of the entry compared with
+	Message   string `json:"message"`
+}
+
+// velocityRates gives the unit rates are reported in and the number of days
+// they are expressed per.
+var velocityRates = map[string]struct {
+	unit string
+	days float64
+}{
+	catalog.WeightVelocity: {"g/day", 1},
+	catalog.LengthVelocity: {"mm/month", catalog.DaysPerMonth},
+}
+
+// assessTrends fills in the velocities and warnings of growth entries,
+// oldest first, each compared with the previous entry that has the same
+// measurement or score.
+func assessTrends(entries []*AssessedGrowth, sex string) {
+	measures := []struct {
+		indicator string
+		value     func(*AssessedGrowth) *int
+		set       func(*AssessedGrowth, *GrowthVelocity)
+	}{
+		{catalog.WeightVelocity, func(a *AssessedGrowth) *int { return a.WeightGrams }, func(a *AssessedGrowth, v *GrowthVelocity) { a.WeightVelocity = v }},
+		{catalog.LengthVelocity, func(a *AssessedGrowth) *int { return a.LengthMM }, func(a *AssessedGrowth, v *GrowthVelocity) { a.LengthVelocity = v }},
+	}
+	for _, m := range measures {
+		var prev *AssessedGrowth
+		for _, cur := range entries {
+			if m.value(cur) == nil {
+				continue
+			}
+			if prev != nil {
+				if v := growthVelocity(m.indicator, sex, prev, cur, *m.value(prev), *m.value(cur)); v != nil {
+					m.set(cur, v)
+					cur.Warnings = append(cur.Warnings, velocityWarnings(m.indicator, v)...)
+				}
+			}
+			prev = cur
+		}
+	}
+
+	scores := []struct {
+		indicator string
+		score     func(*AssessedGrowth) *GrowthScore
+	}{
+		{catalog.WeightForAge, func(a *AssessedGrowth) *GrowthScore { return a.WeightForAge }},
+		{catalog.LengthForAge, func(a *AssessedGrowth) *GrowthScore { return a.LengthForAge }},
+		{catalog.HeadCircumferenceForAge, func(a *AssessedGrowth) *GrowthScore { return a.HeadCircumferenceForAge }},
+		{catalog.WeightForLength, func(a *AssessedGrowth) *GrowthScore { return a.WeightForLength }},
+	}
+	for _, sc := range scores {
+		var prev *AssessedGrowth
+		for _, cur := range entries {
+			if sc.score(cur) == nil {
+				continue
+			}
+			if prev != nil {
+				if w := percentileCrossing(sc.indicator, prev.MeasuredOn, sc.score(prev).Percentile, sc.score(cur).Percentile); w != nil {
+					cur.Warnings = append(cur.Warnings, *w)
+				}
+			}
+			prev = cur
+		}
+	}
+}
+
+// growthVelocity compares the gain from before to after with the WHO
+// increments for the interval starting at the earlier age. The comparison is
+// left out when the measurements are less than half a WHO interval apart,
+// since shorter gains are too noisy to judge, or outside the tables.
+func growthVelocity(indicator, sex string, prev, cur *AssessedGrowth, before, after int) *GrowthVelocity {
+	days := cur.AgeDays - prev.AgeDays
+	if days <= 0 {
+		return nil
+	}
+	r := velocityRates[indicator]
+	v := &GrowthVelocity{
+		Since: prev.MeasuredOn,
+		Days:  days,
+		Rate:  roundTenth(float64(after-before) / float64(days) * r.days),
+		Unit:  r.unit,
+	}
+	std, ok := catalog.IncrementStandardFor(indicator)
+	if !ok || prev.AgeDays < 0 {
+		return v
+	}
+	interval := std.IntervalMonths * catalog.DaysPerMonth
+	inc, ok := std.At(sex, float64(prev.AgeDays)/catalog.DaysPerMonth)
+	if !ok || float64(days) < interval/2 {
+		return v
+	}
+	rate := func(gain float64) *float64 {
+		x := roundTenth(gain / interval * r.days)
+		return &x
+	}
+	v.P5, v.P50, v.P95 = rate(inc.P5), rate(inc.P50), rate(inc.P95)
+	switch {
+	case v.Rate < *v.P5:
+		v.Status = "low"
+	case v.Rate > *v.P95:
+		v.Status = "high"
+	default:
+		v.Status = "normal"
+	}
+	return v
+}
+
+func velocityWarnings(indicator string, v *GrowthVelocity) []GrowthWarning {
+	what := strings.TrimSuffix(indicator, "_velocity")
+	switch v.Status {
+	case "low":
+		return []GrowthWarning{{
+			Code: "low_velocity", Indicator: indicator, Since: v.Since,
+			Message: fmt.Sprintf("%s gain of %s %s since %s is below the WHO 5th percentile of %s %s", what, formatFloat(v.Rate), v.Unit, v.Since, formatFloat(*v.P5), v.Unit),
+		}}
+	case "high":
+		return []GrowthWarning{{
+			Code: "high_velocity", Indicator: indicator, Since: v.Since,
+			Message: fmt.Sprintf("%s gain of %s %s since %s is above the WHO 95th percentile of %s %s", what, formatFloat(v.Rate), v.Unit, v.Since, formatFloat(*v.P95), v.Unit),
+		}}
+	}
+	return nil
+}
+
+// percentileCrossing warns when a score moved across two or more of the
+// major percentile lines; landing on a line counts as crossing it.
+func percentileCrossing(indicator, since string, from, to float64) *GrowthWarning {
+	lo, hi := min(from, to), max(from, to)
+	crossed := 0
+	for _, line := range catalog.PercentileLines {
+		if line >= lo && line <= hi {
+			crossed++
+		}
+	}
+	if crossed < 2 {
+		return nil
+	}
+	w := &GrowthWarning{Code: "percentile_drop", Indicator: indicator, Since: since}
+	verb := "dropped"
+	if to > from {
+		w.Code, verb = "percentile_rise", "rose"
+	}
+	name := indicator
+	if g, ok := catalog.GrowthStandardFor(indicator); ok {
+		name = g.Name
+	}
+	w.Message = fmt.Sprintf("%s %s from P%s to P%s since %s", name, verb, formatFloat(from), formatFloat(to), since)
+	return w
+}
+
+func roundTenth(x float64) float64 {
+	return math.Round(x*10) / 10
+}
+
+func formatFloat(x float64) string {
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
@@ -0,0 +1,93 @@
+// Package thumbnail makes small JPEG previews of JPEG and PNG images using
+// only the standard library.
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png" // register the PNG decoder
+	"io"
+)
+
+// ErrUnsupported is returned for content that is not a JPEG or PNG image.
+var ErrUnsupported = errors.New("unsupported image format")
+
+// maxPixels bounds the images decoded, so a small file claiming huge
+// dimensions cannot exhaust memory.
+const maxPixels = 50_000_000
+
+// Generate decodes a JPEG or PNG image from r and returns a JPEG no larger
+// than size×size, keeping the aspect ratio. Images already that small are
+// re-encoded at their own size. Transparent areas become white.
+func Generate(r io.Reader, size int) ([]byte, error) {
+	var buf bytes.Buffer
+	cfg, format, err := image.DecodeConfig(io.TeeReader(r, &buf))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if format != "jpeg" && format != "png" {
+		return nil, ErrUnsupported
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, errors.New("image too large to thumbnail")
+	}
+	src, _, err := image.Decode(io.MultiReader(&buf, r))
+	if err != nil {
+		return nil, err
+	}
+
+	b := src.Bounds()
+	w, h := fit(b.Dx(), b.Dy(), size)
+	// Flatten onto white first so the averaging works on opaque RGBA.
+	flat := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, b.Min, draw.Over)
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, scale(flat, w, h), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// fit returns the dimensions of a w×h image scaled down to fit in size×size.
+func fit(w, h, size int) (int, int) {
+	if w <= size && h <= size {
+		return w, h
+	}
+	if w >= h {
+		return size, max(1, h*size/w)
+	}
+	return max(1, w*size/h), size
+}
+
+// scale resizes src to w×h by averaging the source pixels each destination
+// pixel covers, which is a good downscaling filter.
+func scale(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+			var r, g, b, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4:]
+					r += int(p[0])
+					g += int(p[1])
+					b += int(p[2])
+					n++
+				}
+			}
+			d := dst.Pix[y*dst.Stride+x*4:]
+			d[0], d[1], d[2], d[3] = uint8(r/n), uint8(g/n), uint8(b/n), 0xff
+		}
+	}
+	return dst
+}
@@ -0,0 +1,44 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 800, 400))
+	for y := 0; y < 400; y++ {
+		for x := 0; x < 800; x++ {
+			src.Set(x, y, color.NRGBA{R: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, src)
+
+	thumb, err := Generate(&buf, 256)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(thumb))
+	if err != nil {
+		t.Fatalf("decode thumbnail: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 256 || b.Dy() != 128 {
+		t.Errorf("thumbnail size = %dx%d, want 256x128", b.Dx(), b.Dy())
+	}
+	if r, g, _, _ := img.At(128, 64).RGBA(); r>>8 < 180 || g>>8 > 30 {
+		t.Errorf("thumbnail colour = %v, want red", img.At(128, 64))
+	}
+}
+
+func TestGenerate_Unsupported(t *testing.T) {
+	if _, err := Generate(strings.NewReader("%PDF-1.4"), 256); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Generate(pdf) error = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,194 @@
+// Package validate checks API request fields and collects every problem
+// found, so clients can highlight all invalid fields at once.
+package validate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FutureTolerance is how far ahead of the server clock a timestamp may be,
+// to absorb clock skew between phones and the server.
+const FutureTolerance = 5 * time.Minute
+
+// Error codes, stable for clients to switch on.
+const (
+	CodeRequired      = "required"
+	CodeInvalidChoice = "invalid_choice"
+	CodeInvalidFormat = "invalid_format"
+	CodeOutOfRange    = "out_of_range"
+	CodeInFuture      = "in_future"
+	CodeBeforeStart   = "before_start"
+)
+
+// Allowed values of the enumerated fields.
+var (
+	FeedTypes     = []string{"breast_left", "breast_right", "bottle"}
+	MilkTypes     = []string{"breast_milk", "formula"}
+	MilkLocations = []string{"room", "fridge", "freezer"}
+	DiaperTypes   = []string{"wet", "dirty", "mixed"}
+	Genders       = []string{"male", "female", "other"}
+	TempMethods   = []string{"axillary", "rectal", "ear"}
+	Severities    = []string{"mild", "moderate", "severe"}
+	Reactions     = []string{"none", "mild", "moderate", "severe"}
+)
+
+// Plausible ranges for measurements.
+const (
+	MinQuantityML = 1
+	MaxQuantityML = 500
+	MaxPumpedML   = 500 // per breast, per session
+
+	MinWeightGrams = 300
+	MaxWeightGrams = 30000
+	MinLengthMM    = 200
+	MaxLengthMM    = 1300
+	MinHeadCircMM  = 200
+	MaxHeadCircMM  = 600
+
+	MinCelsius = 34.0
+	MaxCelsius = 43.0
+
+	MaxDoseIntervalMinutes = 7 * 24 * 60
+	MaxDailyDoses          = 24
+
+	MaxVaccineDose = 10
+
+	MaxSolidGrams = 500
+)
+
+// FieldError describes one invalid request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors is the list of field errors of a rejected request.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Field returns an Errors holding a single field error.
+func Field(field, code, message string) Errors {
+	return Errors{{Field: field, Code: code, Message: message}}
+}
+
+// Validator accumulates field errors. Checks on empty optional values pass;
+// use Required for mandatory fields.
+type Validator struct {
+	now  time.Time
+	errs Errors
+}
+
+// New returns a validator that judges "future" against now, which should be
+// in the household timezone so date checks use the local calendar day.
+func New(now time.Time) *Validator {
+	return &Validator{now: now}
+}
+
+// Add records an error for field.
+func (v *Validator) Add(field, code, message string) {
+	v.errs = append(v.errs, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Check records an error for field unless ok.
+func (v *Validator) Check(ok bool, field, code, message string) {
+	if !ok {
+		v.Add(field, code, message)
+	}
+}
+
+// Err returns the collected errors as Errors, or nil when there are none.
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// Required checks that value is not blank.
+func (v *Validator) Required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.Add(field, CodeRequired, "is required")
+		return false
+	}
+	return true
+}
+
+// OneOf checks that value is one of allowed.
+func (v *Validator) OneOf(field, value string, allowed ...string) {
+	if value != "" && !slices.Contains(allowed, value) {
+		v.Add(field, CodeInvalidChoice, "must be one of "+strings.Join(allowed, ", "))
+	}
+}
+
+// Timestamp checks that value is RFC3339 and not in the future, and returns
+// it parsed. It returns the zero time when value is empty or invalid.
+func (v *Validator) Timestamp(field, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		v.Add(field, CodeInvalidFormat, "must be an RFC3339 timestamp, e.g. 2024-01-15T08:30:00+07:00")
+		return time.Time{}
+	}
+	if t.After(v.now.Add(FutureTolerance)) {
+		v.Add(field, CodeInFuture, "must not be in the future")
+	}
+	return t
+}
+
+// EndAfterStart checks that end is not before start. Zero times are skipped.
+func (v *Validator) EndAfterStart(field string, start, end time.Time) {
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		v.Add(field, CodeBeforeStart, "must not be before the start time")
+	}
+}
+
+// Date checks that value is a YYYY-MM-DD calendar date.
+func (v *Validator) Date(field, value string) bool {
+	if value == "" {
+		return true
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		v.Add(field, CodeInvalidFormat, "must be a date in YYYY-MM-DD format")
+		return false
+	}
+	return true
+}
+
+// Clock checks that value is a time of day in HH:MM format.
+func (v *Validator) Clock(field, value string) bool {
+	if value == "" {
+		return true
+	}
+	if _, err := time.Parse("15:04", value); err != nil {
+		v.Add(field, CodeInvalidFormat, "must be a time of day in HH:MM format")
+		return false
+	}
+	return true
+}
+
+// PastDate checks that value is a YYYY-MM-DD date no later than today.
+func (v *Validator) PastDate(field, value string) {
+	if value != "" && v.Date(field, value) && value > v.now.Format("2006-01-02") {
+		v.Add(field, CodeInFuture, "must not be in the future")
+	}
+}
+
+// Range checks that value, when set, lies within [min, max].
+func (v *Validator) Range(field string, value *int, min, max int) {
+	if value != nil && (*value < min || *value > max) {
+		v.Add(field, CodeOutOfRange, fmt.Sprintf("must be between %d and %d", min, max))
+	}
+}
@@ -0,0 +1,112 @@
+package validate_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"baby-care/internal/validate"
+)
+
+var now = time.Date(2024, 1, 15, 12, 0, 0, 0, time.FixedZone("ICT", 7*60*60))
+
+func TestValidator_CollectsAllErrors(t *testing.T) {
+	v := validate.New(now)
+	v.Required("feed_type", "")
+	v.OneOf("diaper_type", "soggy", validate.DiaperTypes...)
+	v.Range("quantity_ml", intPtr(0), validate.MinQuantityML, validate.MaxQuantityML)
+
+	var errs validate.Errors
+	if !errors.As(v.Err(), &errs) || len(errs) != 3 {
+		t.Fatalf("Err() = %v, want 3 field errors", v.Err())
+	}
+	want := []string{validate.CodeRequired, validate.CodeInvalidChoice, validate.CodeOutOfRange}
+	for i, fe := range errs {
+		if fe.Code != want[i] {
+			t.Errorf("error %d code = %s, want %s", i, fe.Code, want[i])
+		}
+	}
+}
+
+func TestValidator_OptionalValuesPass(t *testing.T) {
+	v := validate.New(now)
+	v.OneOf("feed_type", "", validate.FeedTypes...)
+	v.Timestamp("start_time", "")
+	v.PastDate("measured_on", "")
+	v.Range("quantity_ml", nil, 1, 500)
+	if err := v.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+}
+
+func TestValidator_Timestamps(t *testing.T) {
+	for _, tc := range []struct {
+		value, code string
+	}{
+		{"2024-01-15T11:00:00+07:00", ""},
+		{"2024-01-15T12:04:00+07:00", ""}, // within the future tolerance
+		{"2024-01-15T12:30:00+07:00", validate.CodeInFuture},
+		{"2024-01-15 11:00", validate.CodeInvalidFormat},
+	} {
+		v := validate.New(now)
+		v.Timestamp("t", tc.value)
+		if got := code(v.Err()); got != tc.code {
+			t.Errorf("Timestamp(%q) code = %q, want %q", tc.value, got, tc.code)
+		}
+	}
+}
+
+func TestValidator_EndAfterStart(t *testing.T) {
+	v := validate.New(now)
+	start := v.Timestamp("start_time", "2024-01-15T10:00:00+07:00")
+	end := v.Timestamp("end_time", "2024-01-15T09:00:00+07:00")
+	v.EndAfterStart("end_time", start, end)
+	if got := code(v.Err()); got != validate.CodeBeforeStart {
+		t.Errorf("code = %q, want before_start", got)
+	}
+}
+
+func TestValidator_PastDate(t *testing.T) {
+	for _, tc := range []struct {
+		value, code string
+	}{
+		{"2024-01-15", ""},
+		{"2024-01-16", validate.CodeInFuture},
+		{"2024-13-01", validate.CodeInvalidFormat},
+	} {
+		v := validate.New(now)
+		v.PastDate("d", tc.value)
+		if got := code(v.Err()); got != tc.code {
+			t.Errorf("PastDate(%q) code = %q, want %q", tc.value, got, tc.code)
+		}
+	}
+}
+
+func TestValidator_Clock(t *testing.T) {
+	for _, tc := range []struct {
+		value, code string
+	}{
+		{"", ""},
+		{"19:00", ""},
+		{"00:30", ""},
+		{"24:00", validate.CodeInvalidFormat},
+		{"7pm", validate.CodeInvalidFormat},
+	} {
+		v := validate.New(now)
+		v.Clock("c", tc.value)
+		if got := code(v.Err()); got != tc.code {
+			t.Errorf("Clock(%q) code = %q, want %q", tc.value, got, tc.code)
+		}
+	}
+}
+
+// code returns the code of the single error in err, or "" for nil.
+func code(err error) string {
+	var errs validate.Errors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		return ""
+	}
+	return errs[0].Code
+}
+
+func intPtr(v int) *int { return &v }
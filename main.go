@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"flag"
 	"fmt"
 	"io/fs"
@@ -9,14 +11,38 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
+	"baby-care/internal/auth"
+	"baby-care/internal/blob"
 	"baby-care/internal/server"
 	"baby-care/internal/store"
+
+	"golang.org/x/term"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			if err := runMigrate(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "user":
+			if err := runUser(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
 	port := flag.Int("port", defaultPort(), "HTTP port")
 	dbPath := flag.String("db", defaultDBPath(), "SQLite database path")
+	tz := flag.String("tz", "", "IANA timezone overriding the household setting (e.g. Europe/Berlin)")
+	corsOrigins := flag.String("cors-origin", "", "comma-separated origins allowed to call the API cross-origin")
+	trashRetention := flag.Duration("trash-retention", 30*24*time.Hour, "how long deleted entries stay restorable before they are purged (0 keeps them forever)")
 	flag.Parse()
 
 	st, err := store.Open(*dbPath)
@@ -25,6 +51,33 @@ func main() {
 	}
 	defer st.Close()
 
+	if *tz != "" {
+		if err := st.OverrideTimezone(*tz); err != nil {
+			log.Fatalf("--tz: %v", err)
+		}
+	}
+	log.Printf("Using timezone %s", st.Location())
+
+	if n, err := st.CountUsers(); err != nil {
+		log.Fatalf("count users: %v", err)
+	} else if n == 0 {
+		log.Printf("No accounts yet: run `baby-care user add <username>` to create the first parent")
+	}
+	if err := st.DeleteExpiredSessions(); err != nil {
+		log.Printf("delete expired sessions: %v", err)
+	}
+
+	blobs := blob.NewDir(st.AttachmentDir())
+	go sweepBlobs(st, blobs)
+	if *trashRetention > 0 {
+		go purgeTrash(st, *trashRetention)
+	}
+
+	opts := server.Options{Blobs: blobs}
+	if *corsOrigins != "" {
+		opts.AllowedOrigins = strings.Split(*corsOrigins, ",")
+	}
+
 	staticFS, err := fs.Sub(staticFiles, "static")
 	if err != nil {
 		log.Fatalf("sub static: %v", err)
@@ -32,11 +85,172 @@ func main() {
 
 	addr := fmt.Sprintf(":%d", *port)
 	log.Printf("Baby Care Tracker listening on http://localhost%s", addr)
-	if err := http.ListenAndServe(addr, server.New(st, staticFS)); err != nil {
+	if err := http.ListenAndServe(addr, server.New(st, staticFS, opts)); err != nil {
 		log.Fatalf("serve: %v", err)
 	}
 }
 
+// purgeTrash hourly removes log entries that were deleted more than retention
+// ago.
+func purgeTrash(st *store.Store, retention time.Duration) {
+	for {
+		n, err := st.PurgeTrash(retention)
+		if err != nil {
+			log.Printf("purge trash: %v", err)
+		}
+		if n > 0 {
+			log.Printf("Purged %d deleted entries older than %s", n, retention)
+		}
+		time.Sleep(time.Hour)
+	}
+}
+
+// sweepBlobs hourly removes attachment files that no attachment refers to any
+// more, such as those of purged log entries. Files younger than an hour are
+// spared so an upload is never swept before its attachment row is written.
+func sweepBlobs(st *store.Store, blobs *blob.Dir) {
+	for {
+		if hashes, err := st.AttachmentHashes(); err != nil {
+			log.Printf("sweep attachments: %v", err)
+		} else if n, err := blobs.Sweep(func(hash string) bool { return hashes[hash] }, time.Hour); err != nil {
+			log.Printf("sweep attachments: %v", err)
+		} else if n > 0 {
+			log.Printf("Removed %d unreferenced attachment files", n)
+		}
+		time.Sleep(time.Hour)
+	}
+}
+
+// runMigrate implements `baby-care migrate status|up`.
+func runMigrate(args []string) error {
+	fset := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dbPath := fset.String("db", defaultDBPath(), "SQLite database path")
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: baby-care migrate [--db path] status|up")
+		fset.PrintDefaults()
+	}
+	// Flags may come before or after the verb.
+	fset.Parse(args)
+	verb := fset.Arg(0)
+	if fset.NArg() > 0 {
+		fset.Parse(fset.Args()[1:])
+	}
+	if fset.NArg() != 0 || (verb != "status" && verb != "up") {
+		fset.Usage()
+		os.Exit(2)
+	}
+
+	st, err := store.Connect(*dbPath)
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+	defer st.Close()
+
+	if verb == "up" {
+		n, err := st.Migrate()
+		if err != nil {
+			return fmt.Errorf("migrate: %w", err)
+		}
+		fmt.Printf("applied %d migration(s); schema version %d\n", n, store.LatestSchemaVersion())
+		return nil
+	}
+	current, err := st.SchemaVersion()
+	if err != nil {
+		return fmt.Errorf("schema version: %w", err)
+	}
+	statuses, err := st.MigrationStatus()
+	if err != nil {
+		return fmt.Errorf("migration status: %w", err)
+	}
+	fmt.Printf("database: %s\nschema version: %d (binary supports %d)\n\n", *dbPath, current, store.LatestSchemaVersion())
+	for _, m := range statuses {
+		state := "pending"
+		if m.AppliedAt != "" {
+			state = "applied " + m.AppliedAt
+		}
+		fmt.Printf("%4d  %-40s %s\n", m.Version, m.Name, state)
+	}
+	if current > store.LatestSchemaVersion() {
+		fmt.Println("\nwarning: database schema is newer than this binary")
+	}
+	return nil
+}
+
+// runUser implements `baby-care user add [--role role] <username>`. The password is read
+// from the terminal without echo, or from the first line of stdin when piped.
+func runUser(args []string) error {
+	fset := flag.NewFlagSet("user", flag.ExitOnError)
+	dbPath := fset.String("db", defaultDBPath(), "SQLite database path")
+	role := fset.String("role", auth.RoleParent, "role of the new user: "+strings.Join(auth.Roles, ", "))
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: baby-care user add [--db path] [--role role] <username>")
+		fset.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "add" {
+		fset.Usage()
+		os.Exit(2)
+	}
+	fset.Parse(args[1:])
+	if fset.NArg() != 1 {
+		fset.Usage()
+		os.Exit(2)
+	}
+	username := fset.Arg(0)
+	if !auth.ValidRole(*role) {
+		return fmt.Errorf("unknown role %q", *role)
+	}
+
+	password, err := readPassword()
+	if err != nil {
+		return fmt.Errorf("read password: %w", err)
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+
+	st, err := store.Open(*dbPath)
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+	defer st.Close()
+
+	user, err := st.CreateUser(username, hash, *role)
+	if err != nil {
+		return fmt.Errorf("create user: %w", err)
+	}
+	fmt.Printf("created %s %s\n", user.Role, user.Username)
+	return nil
+}
+
+func readPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	first, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(os.Stderr, "Confirm password: ")
+	second, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if string(first) != string(second) {
+		return "", errors.New("passwords do not match")
+	}
+	return string(first), nil
+}
+
 func defaultPort() int {
 	if p := os.Getenv("PORT"); p != "" {
 		if n, err := strconv.Atoi(p); err == nil {